@@ -0,0 +1,186 @@
+// Command reprocess regenerates the variants of stored images whose type's
+// sizes have changed since they were generated.
+//
+// It reads the same environment as the server and needs its database, so it
+// only runs with ENVIRONMENT set to staging or production:
+//
+//	reprocess -type user -dry-run
+//	reprocess -type user -interval 250ms
+//
+// Images already generated with the current sizes are skipped, so an
+// interrupted run can be started over, or resumed with -cursor from the
+// cursor it reported.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"go.uber.org/zap"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	typeName := flag.String("type", "", "image type to reprocess (required)")
+	dryRun := flag.Bool("dry-run", false, "only report the images that would be regenerated")
+	cursor := flag.String("cursor", "", "cursor reported by an earlier run, to resume it")
+	limit := flag.Int("limit", 0, "number of images to examine, 0 for all")
+	interval := flag.Duration("interval", cfg.Jobs.ReprocessInterval, "pause after each regenerated image")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if cfg.Environment != "production" && cfg.Environment != "staging" {
+		fmt.Fprintf(os.Stderr, "Reprocessing needs the database; ENVIRONMENT is %q, not staging or production\n", cfg.Environment)
+		os.Exit(1)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+	sugar := logger.Sugar()
+
+	imageConfig, err := config.LoadImageConfig(cfg.ImageConfig.ConfigPath)
+	if err != nil {
+		sugar.Fatalw("Failed to load image configuration",
+			"error", err,
+			"path", cfg.ImageConfig.ConfigPath)
+	}
+
+	db, err := initializeDatabase(cfg)
+	if err != nil {
+		sugar.Fatalw("Failed to initialize database", "error", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			sugar.Errorw("Failed to close database connection", "error", err)
+		}
+	}()
+
+	s3Config := storage.S3Config{
+		Region:          cfg.S3.Region,
+		Bucket:          cfg.S3.Bucket,
+		AccessKeyID:     cfg.S3.AccessKeyID,
+		SecretAccessKey: cfg.S3.SecretAccessKey,
+		Endpoint:        cfg.S3.Endpoint,
+		CDNBaseURL:      cfg.S3.CDNBaseURL,
+		UsePathStyle:    cfg.S3.UsePathStyle,
+	}
+	storageClient, err := storage.NewS3Client(s3Config)
+	if err != nil {
+		sugar.Fatalw("Failed to initialize S3 storage client", "error", err)
+	}
+
+	imageService := service.NewImageService(
+		repository.NewPostgresImageRepository(db),
+		storageClient,
+		processor.NewProcessor(),
+		imageConfig,
+		sugar,
+	)
+	if cfg.S3.OriginalsBucket != "" {
+		s3Config.Bucket = cfg.S3.OriginalsBucket
+		originalStorage, err := storage.NewS3Client(s3Config)
+		if err != nil {
+			sugar.Fatalw("Failed to initialize S3 originals storage client", "error", err)
+		}
+		imageService.SetOriginalStorage(originalStorage)
+	}
+
+	// Stop between images on interrupt, so the run can be resumed
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := imageService.ReprocessImages(ctx, service.ReprocessOptions{
+		TypeName: *typeName,
+		Cursor:   *cursor,
+		Limit:    *limit,
+		DryRun:   *dryRun,
+		Interval: *interval,
+	})
+	if report != nil {
+		printReport(report)
+	}
+	if err != nil {
+		if report != nil {
+			fmt.Fprintf(os.Stderr, "Stopped: %v; resume with -cursor %s\n", err, report.NextCursor)
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to reprocess images: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// printReport writes a line per stale image and a summary to stdout
+func printReport(report *service.ReprocessReport) {
+	for _, image := range report.Images {
+		line := fmt.Sprintf("%s owner=%s", image.ImageGUID, image.OwnerGUID)
+		if len(image.Added) > 0 {
+			line += " added=" + strings.Join(image.Added, ",")
+		}
+		if len(image.Removed) > 0 {
+			line += " removed=" + strings.Join(image.Removed, ",")
+		}
+		if image.Err != nil {
+			line += fmt.Sprintf(" error=%q", image.Err.Error())
+		}
+		fmt.Println(line)
+	}
+
+	if report.DryRun {
+		fmt.Printf("Examined %d images of type %s: %d would be reprocessed; next cursor %s\n",
+			report.Scanned, report.TypeName, report.Stale, report.NextCursor)
+		return
+	}
+	fmt.Printf("Examined %d images of type %s: %d stale, %d reprocessed, %d failed; next cursor %s\n",
+		report.Scanned, report.TypeName, report.Stale, report.Reprocessed, report.Failed, report.NextCursor)
+}
+
+// initializeDatabase sets up the PostgreSQL database connection
+func initializeDatabase(cfg *config.Config) (*sql.DB, error) {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.Password, cfg.DB.Name, cfg.DB.SSLMode,
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	db.SetMaxOpenConns(2)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
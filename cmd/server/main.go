@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,11 +13,20 @@ import (
 
 	"github.com/antonrybalko/image-service-go/internal/api"
 	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/fetcher"
+	"github.com/antonrybalko/image-service-go/internal/grpcapi"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/antonrybalko/image-service-go/internal/jobs"
+	"github.com/antonrybalko/image-service-go/internal/outbox"
 	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/queue"
 	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/resumable"
 	"github.com/antonrybalko/image-service-go/internal/service"
 	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/antonrybalko/image-service-go/internal/webhook"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -48,6 +58,7 @@ func main() {
 	sugar.Infow("Starting image service",
 		"environment", cfg.Environment,
 		"port", cfg.Port,
+		"grpcPort", cfg.GRPCPort,
 	)
 
 	// Load image configuration from YAML
@@ -64,6 +75,12 @@ func main() {
 	// Initialize repository
 	// For Phase 1, we'll use a mock repository
 	var imageRepo repository.ImageRepository
+	var eventOutbox repository.Outbox
+	var idempotencyStore idempotency.Store
+	var webhookStore webhook.Store
+	var jobStore jobs.Store
+	var resumableStore resumable.Store
+	var jobQueue queue.Queue
 	if cfg.Environment == "production" || cfg.Environment == "staging" {
 		// In production, we would initialize a real PostgreSQL connection
 		db, err := initializeDatabase(cfg)
@@ -76,19 +93,32 @@ func main() {
 				sugar.Errorw("Failed to close database connection", "error", err)
 			}
 		}()
-		imageRepo = repository.NewPostgresImageRepository(db)
+		postgresRepo := repository.NewPostgresImageRepository(db)
+		imageRepo, eventOutbox = postgresRepo, postgresRepo
+		idempotencyStore = idempotency.NewPostgresStore(db)
+		webhookStore = webhook.NewPostgresStore(db)
+		jobStore = jobs.NewPostgresStore(db)
+		resumableStore = resumable.NewPostgresStore(db)
+		jobQueue = queue.NewPostgresQueue(db)
 		sugar.Info("Initialized PostgreSQL repository")
 	} else {
 		// For development and testing, use an in-memory mock
-		imageRepo = repository.NewMockImageRepository()
+		mockRepo := repository.NewMockImageRepository()
+		imageRepo, eventOutbox = mockRepo, mockRepo
+		idempotencyStore = idempotency.NewMemoryStore()
+		webhookStore = webhook.NewMemoryStore()
+		jobStore = jobs.NewMemoryStore()
+		resumableStore = resumable.NewMemoryStore()
+		jobQueue = queue.NewMemoryQueue()
 		sugar.Info("Initialized mock repository")
 	}
 
 	// Initialize storage client
-	var storageClient storage.S3Interface
+	var storageClient, originalStorage storage.S3Interface
 	if cfg.Environment == "test" {
 		// Use mock storage for tests
 		storageClient = storage.NewMockS3()
+		originalStorage = storageClient
 		sugar.Info("Initialized mock S3 storage")
 	} else {
 		// Initialize real S3 client
@@ -110,6 +140,19 @@ func main() {
 			"region", cfg.S3.Region,
 			"bucket", cfg.S3.Bucket,
 			"endpoint", cfg.S3.Endpoint)
+
+		// Originals share the bucket unless a private one is configured
+		originalStorage = storageClient
+		if cfg.S3.OriginalsBucket != "" {
+			s3Config.Bucket = cfg.S3.OriginalsBucket
+			originalStorage, err = storage.NewS3Client(s3Config)
+			if err != nil {
+				sugar.Fatalw("Failed to initialize S3 originals storage client",
+					"error", err)
+			}
+			sugar.Infow("Initialized S3 originals storage client",
+				"bucket", cfg.S3.OriginalsBucket)
+		}
 	}
 
 	// Initialize image processor
@@ -124,21 +167,94 @@ func main() {
 		imageConfig,
 		sugar,
 	)
+	imageService.SetMaxImageSize(cfg.Upload.MaxImageSize)
+	imageService.SetJobStore(jobStore)
+	imageService.SetResumableStore(resumableStore)
+	imageService.SetQueue(jobQueue)
+	imageService.SetReprocessInterval(cfg.Jobs.ReprocessInterval)
+	imageService.SetOriginalStorage(originalStorage)
+
+	// Configure remote image import
+	allowedNetworks, err := fetcher.ParseNetworks(cfg.Fetch.AllowedNetworks)
+	if err != nil {
+		sugar.Fatalw("Invalid FETCH_ALLOWED_NETWORKS", "error", err)
+	}
+	fetchConfig := fetcher.DefaultConfig()
+	fetchConfig.Timeout = cfg.Fetch.Timeout
+	fetchConfig.MaxRedirects = cfg.Fetch.MaxRedirects
+	fetchConfig.MaxBytes = imageService.MaxUploadSize()
+	fetchConfig.AllowedNetworks = allowedNetworks
+	imageService.SetRemoteFetcher(fetcher.New(fetchConfig))
+
 	sugar.Info("Initialized image service")
 
+	// Relay recorded lifecycle events to the built-in publisher and the
+	// configured webhooks
+	var eventPublisher outbox.Publisher
+	switch cfg.Outbox.Publisher {
+	case "memory":
+		eventPublisher = outbox.NewMemoryPublisher(1000)
+	case "file":
+		filePublisher, err := outbox.NewFilePublisher(cfg.Outbox.FilePath)
+		if err != nil {
+			sugar.Fatalw("Failed to initialize event publisher", "error", err)
+		}
+		defer func() {
+			if err := filePublisher.Close(); err != nil {
+				sugar.Errorw("Failed to close event file", "error", err)
+			}
+		}()
+		eventPublisher = filePublisher
+	default:
+		sugar.Fatalf("Invalid OUTBOX_PUBLISHER %q: must be memory or file", cfg.Outbox.Publisher)
+	}
+	// Webhook deliveries are attempted by jobs on the job queue
+	webhookConfig := webhook.DefaultConfig()
+	webhookConfig.MaxAttempts = cfg.Webhook.MaxAttempts
+	webhookConfig.Timeout = cfg.Webhook.Timeout
+	dispatcher := webhook.NewDispatcher(webhookStore, jobQueue, imageConfig, webhookConfig, sugar)
+
+	relay := outbox.NewRelay(eventOutbox, outbox.Multi(webhook.NewPublisher(dispatcher), eventPublisher), sugar)
+	sugar.Infow("Initialized event relay", "publisher", cfg.Outbox.Publisher)
+
 	// Create router with all dependencies
-	router := api.NewRouter(sugar, cfg, imageService)
+	router := api.NewRouter(sugar, cfg, imageService, idempotencyStore, dispatcher)
 	sugar.Info("Initialized router")
 
 	// Create server
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      router.Handler(),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           router.Handler(),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
 
+	// Periodically remove direct uploads that were never finalized
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+	go imageService.RunStagingCleanup(cleanupCtx, 10*time.Minute)
+	go idempotency.RunCleanup(cleanupCtx, idempotencyStore, 10*time.Minute, sugar)
+	go relay.Run(cleanupCtx, cfg.Outbox.PollInterval, cfg.Outbox.Retention)
+
+	// Process queued background work, such as uploads submitted with
+	// Prefer: respond-async, file deletions and webhook deliveries
+	queueConfig := queue.DefaultConfig()
+	queueConfig.Workers = cfg.Jobs.Workers
+	queueConfig.PollInterval = cfg.Jobs.PollInterval
+	queueConfig.Visibility = cfg.Jobs.VisibilityTimeout
+	queueConfig.Retention = cfg.Jobs.Retention
+	worker := queue.NewWorker(jobQueue, queueConfig, sugar)
+	imageService.RegisterJobHandlers(worker)
+	dispatcher.RegisterJobHandlers(worker)
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		worker.Run(cleanupCtx)
+	}()
+	sugar.Infow("Started job workers", "workers", cfg.Jobs.Workers)
+
 	// Start server in a goroutine so that it doesn't block
 	go func() {
 		sugar.Infof("Server listening on port %d", cfg.Port)
@@ -147,6 +263,22 @@ func main() {
 		}
 	}()
 
+	// Serve the gRPC API on its own port
+	var grpcServer *grpc.Server
+	if cfg.GRPCPort != 0 {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			sugar.Fatalf("Failed to listen on gRPC port %d: %v", cfg.GRPCPort, err)
+		}
+		grpcServer = grpcapi.NewServer(sugar, cfg, imageService)
+		go func() {
+			sugar.Infof("gRPC server listening on port %d", cfg.GRPCPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				sugar.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// Channel to listen for interrupt signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -154,19 +286,47 @@ func main() {
 	// Block until a signal is received
 	sig := <-quit
 	sugar.Infof("Shutting down server: %v", sig)
+	stopCleanup()
 
 	// Create a deadline for server shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	// Attempt graceful shutdown
+	if grpcServer != nil {
+		stopGRPC(ctx, grpcServer)
+	}
 	if err := server.Shutdown(ctx); err != nil {
 		sugar.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Let the workers finish the jobs in progress; jobs left pending are
+	// picked up on the next start
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		sugar.Warn("Job workers did not finish before the shutdown deadline")
+	}
+
 	sugar.Info("Server exited gracefully")
 }
 
+// stopGRPC stops the gRPC server gracefully, cancelling the calls still
+// running when ctx expires
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
 // initializeDatabase sets up the PostgreSQL database connection
 func initializeDatabase(cfg *config.Config) (*sql.DB, error) {
 	connStr := fmt.Sprintf(
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// AdminImageResponse represents an image in the admin listing
+type AdminImageResponse struct {
+	ImageResponse
+	ContentType string `json:"contentType,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	Position    int    `json:"position"`
+	IsPrimary   bool   `json:"isPrimary"`
+}
+
+// ListImagesResponse represents one page of the admin listing
+type ListImagesResponse struct {
+	Images     []AdminImageResponse `json:"images"`
+	NextCursor string               `json:"nextCursor,omitempty"`
+}
+
+// MaxReprocessPreviewLimit caps the images examined by one dry run of the
+// reprocessing endpoint
+const MaxReprocessPreviewLimit = 1000
+
+// ReprocessReportResponse represents the dry-run report of a reprocessing run
+type ReprocessReportResponse struct {
+	TypeName   string                     `json:"typeName"`
+	SizesHash  string                     `json:"sizesHash"`
+	Scanned    int                        `json:"scanned"`
+	Stale      int                        `json:"stale"`
+	NextCursor string                     `json:"nextCursor,omitempty"` // Absent once the last image was examined
+	Images     []ReprocessedImageResponse `json:"images"`
+}
+
+// ReprocessedImageResponse represents an image a reprocessing run would regenerate
+type ReprocessedImageResponse struct {
+	GUID         string   `json:"guid"`
+	OwnerGUID    string   `json:"ownerGuid"`
+	AddedSizes   []string `json:"addedSizes,omitempty"`
+	RemovedSizes []string `json:"removedSizes,omitempty"`
+}
+
+// ReprocessRunResponse represents a queued reprocessing run
+type ReprocessRunResponse struct {
+	RunID    string `json:"runId"`
+	TypeName string `json:"typeName"`
+}
+
+// AdminHandlers contains handlers for /v1/admin, which require the service admin role
+type AdminHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewAdminHandlers creates a new set of admin handlers
+func NewAdminHandlers(imageService *service.ImageService) *AdminHandlers {
+	return &AdminHandlers{
+		imageService: imageService,
+	}
+}
+
+// RequireAdmin rejects callers without the service admin role
+func (h *AdminHandlers) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.GetUserIDFromContext(r.Context()); !ok {
+			writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+			return
+		}
+		if !auth.IsServiceAdmin(r.Context()) {
+			writeError(w, r, http.StatusForbidden, problem.Forbidden, "Caller is not a service admin")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ListImages handles GET /v1/admin/images
+//
+// Query parameters: typeName, ownerGuid, contentType, createdFrom, createdTo,
+// updatedFrom, updatedTo (RFC 3339; lower bounds inclusive, upper exclusive),
+// limit and cursor (the nextCursor of the previous page).
+func (h *AdminHandlers) ListImages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := repository.ImageFilter{
+			TypeName:    query.Get("typeName"),
+			ContentType: query.Get("contentType"),
+		}
+
+		if owner := query.Get("ownerGuid"); owner != "" {
+			ownerGUID, err := uuid.Parse(owner)
+			if err != nil {
+				writeFieldError(w, r, problem.InvalidOwnerID, "ownerGuid", "ownerGuid must be a valid UUID")
+				return
+			}
+			filter.OwnerGUID = ownerGUID
+		}
+
+		for param, bound := range map[string]*time.Time{
+			"createdFrom": &filter.CreatedFrom,
+			"createdTo":   &filter.CreatedTo,
+			"updatedFrom": &filter.UpdatedFrom,
+			"updatedTo":   &filter.UpdatedTo,
+		} {
+			value := query.Get(param)
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				writeFieldError(w, r, problem.InvalidFilter, param, param+" must be an RFC 3339 timestamp")
+				return
+			}
+			*bound = parsed
+		}
+
+		limit := 0
+		if value := query.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				writeFieldError(w, r, problem.InvalidFilter, "limit", "limit must be a positive integer")
+				return
+			}
+			limit = parsed
+		}
+
+		list, err := h.imageService.ListImages(r.Context(), filter, query.Get("cursor"), limit)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		response := ListImagesResponse{
+			Images:     make([]AdminImageResponse, 0, len(list.Images)),
+			NextCursor: list.NextCursor,
+		}
+		for _, image := range list.Images {
+			response.Images = append(response.Images, toAdminImageResponse(image))
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// ReprocessImages handles POST /v1/admin/images/{typeName}/reprocess
+//
+// Queues a run regenerating the variants of every image of the type that was
+// generated with other sizes than the type's current ones, and responds 202
+// Accepted. With dryRun=true nothing is changed; the response lists the
+// images that would be regenerated, examining limit images (default 100)
+// from cursor, the nextCursor of the previous page.
+func (h *AdminHandlers) ReprocessImages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		typeName := chi.URLParam(r, "typeName")
+		query := r.URL.Query()
+
+		dryRun := false
+		if value := query.Get("dryRun"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				writeFieldError(w, r, problem.InvalidReprocessOptions, "dryRun", "dryRun must be true or false")
+				return
+			}
+			dryRun = parsed
+		}
+
+		if !dryRun {
+			runID, err := h.imageService.StartReprocessing(r.Context(), typeName)
+			if err != nil {
+				handleImageServiceError(w, r, err)
+				return
+			}
+			writeJSON(w, http.StatusAccepted, ReprocessRunResponse{
+				RunID:    runID.String(),
+				TypeName: typeName,
+			})
+			return
+		}
+
+		opts := service.ReprocessOptions{
+			TypeName: typeName,
+			DryRun:   true,
+			Limit:    service.ReprocessBatchSize,
+		}
+		opts.Cursor = query.Get("cursor")
+		if value := query.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				writeFieldError(w, r, problem.InvalidReprocessOptions, "limit", "limit must be a positive integer")
+				return
+			}
+			opts.Limit = min(parsed, MaxReprocessPreviewLimit)
+		}
+
+		report, err := h.imageService.ReprocessImages(r.Context(), opts)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		response := ReprocessReportResponse{
+			TypeName:  report.TypeName,
+			SizesHash: report.SizesHash,
+			Scanned:   report.Scanned,
+			Stale:     report.Stale,
+			Images:    make([]ReprocessedImageResponse, 0, len(report.Images)),
+		}
+		if !report.Done {
+			response.NextCursor = report.NextCursor
+		}
+		for _, image := range report.Images {
+			response.Images = append(response.Images, ReprocessedImageResponse{
+				GUID:         image.ImageGUID.String(),
+				OwnerGUID:    image.OwnerGUID.String(),
+				AddedSizes:   image.Added,
+				RemovedSizes: image.Removed,
+			})
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// toAdminImageResponse converts an image to the admin listing format
+func toAdminImageResponse(image *domain.Image) AdminImageResponse {
+	return AdminImageResponse{
+		ImageResponse: toImageResponse(image),
+		ContentType:   image.ContentType,
+		CreatedAt:     image.CreatedAt.UTC().Format(time.RFC3339),
+		Position:      image.Position,
+		IsPrimary:     image.IsPrimary,
+	}
+}
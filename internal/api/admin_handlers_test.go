@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newAdminTestToken signs an HS256 token carrying the service admin role
+func newAdminTestToken(t *testing.T) string {
+	claims := auth.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.New().String()},
+		Roles:            []string{auth.RoleAdmin},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAdminListImages(t *testing.T) {
+	router := newTestRouter(t)
+	adminToken := newAdminTestToken(t)
+
+	var owners []uuid.UUID
+	for i := 0; i < 3; i++ {
+		owner := uuid.New()
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte("mock-admin-image-data")))
+		req.Header.Set("Content-Type", "image/jpeg")
+		req.Header.Set("Authorization", "Bearer "+newTestToken(t, owner.String()))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		owners = append(owners, owner)
+	}
+
+	list := func(token string, query url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/images?"+query.Encode(), nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Follow the cursors until the last page
+	var listed []uuid.UUID
+	query := url.Values{"typeName": {"user"}, "limit": {"2"}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		rr := list(adminToken, query)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response ListImagesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		for _, image := range response.Images {
+			assert.Equal(t, "user", image.TypeName)
+			assert.NotEmpty(t, image.CreatedAt)
+			listed = append(listed, image.OwnerGUID)
+		}
+		if response.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", response.NextCursor)
+	}
+	assert.ElementsMatch(t, owners, listed)
+
+	// Filtering by owner
+	rr := list(adminToken, url.Values{"ownerGuid": {owners[1].String()}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response ListImagesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Images, 1)
+	assert.Equal(t, owners[1], response.Images[0].OwnerGUID)
+
+	tests := []struct {
+		name       string
+		token      string
+		query      url.Values
+		wantStatus int
+	}{
+		{name: "No token", query: url.Values{}, wantStatus: http.StatusUnauthorized},
+		{name: "Not an admin", token: newTestToken(t, owners[0].String()), query: url.Values{}, wantStatus: http.StatusForbidden},
+		{name: "Invalid owner", token: adminToken, query: url.Values{"ownerGuid": {"nope"}}, wantStatus: http.StatusBadRequest},
+		{name: "Invalid date", token: adminToken, query: url.Values{"updatedFrom": {"yesterday"}}, wantStatus: http.StatusBadRequest},
+		{name: "Invalid limit", token: adminToken, query: url.Values{"limit": {"0"}}, wantStatus: http.StatusBadRequest},
+		{name: "Invalid cursor", token: adminToken, query: url.Values{"cursor": {"!"}}, wantStatus: http.StatusBadRequest},
+		{name: "Unknown type", token: adminToken, query: url.Values{"typeName": {"unknown"}}, wantStatus: http.StatusNotFound},
+		{name: "Date range", token: adminToken, query: url.Values{"createdFrom": {"2020-01-01T00:00:00Z"}}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := list(tt.token, tt.query)
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestAdminReprocessImages(t *testing.T) {
+	imageService := newTestImageService(t)
+	jobQueue := queue.NewMemoryQueue()
+	imageService.SetQueue(jobQueue)
+	worker := queue.NewWorker(jobQueue, queue.DefaultConfig(), zap.NewNop().Sugar())
+	imageService.RegisterJobHandlers(worker)
+	imageService.SetReprocessInterval(0)
+	router := newTestRouterWithService(imageService)
+	adminToken := newAdminTestToken(t)
+
+	for i := 0; i < 2; i++ {
+		owner := uuid.New()
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte("mock-admin-image-data")))
+		req.Header.Set("Content-Type", "image/jpeg")
+		req.Header.Set("Authorization", "Bearer "+newTestToken(t, owner.String()))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	imageType, err := imageService.ImageType("user")
+	require.NoError(t, err)
+	imageType.Sizes["large"] = domain.Size{Width: 1024, Height: 1024}
+
+	reprocess := func(token, typeName string, query url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/images/"+typeName+"/reprocess?"+query.Encode(), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// A dry run pages through the stale images
+	rr := reprocess(adminToken, "user", url.Values{"dryRun": {"true"}, "limit": {"1"}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var report ReprocessReportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Scanned)
+	assert.Equal(t, 1, report.Stale)
+	assert.NotEmpty(t, report.NextCursor)
+	require.Len(t, report.Images, 1)
+	assert.Empty(t, report.Images[0].AddedSizes)
+
+	// Without dryRun the run is queued
+	rr = reprocess(adminToken, "user", url.Values{})
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	var run ReprocessRunResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &run))
+	assert.NotEmpty(t, run.RunID)
+	assert.Equal(t, "user", run.TypeName)
+
+	// The batch, and the deletion of each image's old renditions
+	processed, err := worker.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, processed)
+
+	rr = reprocess(adminToken, "user", url.Values{"dryRun": {"true"}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	report = ReprocessReportResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Scanned)
+	assert.Equal(t, 0, report.Stale)
+	assert.Empty(t, report.NextCursor)
+	assert.Empty(t, report.Images)
+
+	decodeProblem(t, reprocess(newTestToken(t, uuid.New().String()), "user", url.Values{}), http.StatusForbidden, problem.Forbidden)
+	decodeProblem(t, reprocess(adminToken, "unknown", url.Values{}), http.StatusNotFound, problem.UnknownImageType)
+	decodeProblem(t, reprocess(adminToken, "user", url.Values{"dryRun": {"maybe"}}), http.StatusBadRequest, problem.InvalidReprocessOptions)
+	decodeProblem(t, reprocess(adminToken, "user", url.Values{"dryRun": {"true"}, "cursor": {"not-a-cursor"}}), http.StatusBadRequest, problem.InvalidCursor)
+	decodeProblem(t, reprocess(adminToken, "user", url.Values{"dryRun": {"true"}, "limit": {"0"}}), http.StatusBadRequest, problem.InvalidReprocessOptions)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/google/uuid"
+)
+
+// BatchGetImagesRequest represents the request body for looking up many owners' images
+type BatchGetImagesRequest struct {
+	TypeName   string      `json:"typeName"`
+	OwnerGUIDs []uuid.UUID `json:"ownerGuids"`
+}
+
+// BatchGetImagesResponse maps every requested owner GUID to its image, or null if it has none
+type BatchGetImagesResponse struct {
+	TypeName string                       `json:"typeName"`
+	Images   map[uuid.UUID]*ImageResponse `json:"images"`
+}
+
+// BatchHandlers contains handlers for multi-owner lookups
+type BatchHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewBatchHandlers creates a new set of batch handlers
+func NewBatchHandlers(imageService *service.ImageService) *BatchHandlers {
+	return &BatchHandlers{
+		imageService: imageService,
+	}
+}
+
+// BatchGetImages handles POST /v1/images:batchGet
+func (h *BatchHandlers) BatchGetImages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req BatchGetImagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidRequest, "Request body must be JSON with a typeName and an ownerGuids array")
+			return
+		}
+		if req.TypeName == "" {
+			writeFieldError(w, r, problem.InvalidRequest, "typeName", "typeName is required")
+			return
+		}
+
+		images, err := h.imageService.GetImagesByOwners(r.Context(), req.TypeName, req.OwnerGUIDs)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		response := BatchGetImagesResponse{
+			TypeName: req.TypeName,
+			Images:   make(map[uuid.UUID]*ImageResponse, len(images)),
+		}
+		for ownerGUID, image := range images {
+			if image == nil {
+				response.Images[ownerGUID] = nil
+				continue
+			}
+			imageResponse := toImageResponse(image)
+			response.Images[ownerGUID] = &imageResponse
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchGetImages(t *testing.T) {
+	router := newTestRouter(t)
+	withImage := uuid.New()
+	withoutImage := uuid.New()
+
+	// Upload an image for one of the owners
+	req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte("mock-batch-image-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, withImage.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	batchGet := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/images:batchGet", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr = batchGet(`{"typeName":"user","ownerGuids":["` + withImage.String() + `","` + withoutImage.String() + `"]}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Missing owners are listed explicitly as null
+	var raw struct {
+		Images map[string]json.RawMessage `json:"images"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &raw))
+	assert.Equal(t, "null", string(raw.Images[withoutImage.String()]))
+
+	var response BatchGetImagesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "user", response.TypeName)
+	require.Len(t, response.Images, 2)
+	require.NotNil(t, response.Images[withImage])
+	assert.Equal(t, withImage, response.Images[withImage].OwnerGUID)
+	assert.NotEmpty(t, response.Images[withImage].SmallURL)
+
+	tooMany := make([]string, service.MaxBatchOwners+1)
+	for i := range tooMany {
+		tooMany[i] = `"` + uuid.New().String() + `"`
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "Not JSON", body: "owners", wantStatus: http.StatusBadRequest},
+		{name: "Missing type", body: `{"ownerGuids":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "Invalid GUID", body: `{"typeName":"user","ownerGuids":["nope"]}`, wantStatus: http.StatusBadRequest},
+		{name: "Unknown type", body: `{"typeName":"unknown","ownerGuids":[]}`, wantStatus: http.StatusNotFound},
+		{name: "Too many owners", body: `{"typeName":"user","ownerGuids":[` + strings.Join(tooMany, ",") + `]}`, wantStatus: http.StatusBadRequest},
+		{name: "Empty", body: `{"typeName":"user","ownerGuids":[]}`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := batchGet(tt.body)
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// FileHandlers serves stored image variants for deployments that can't expose the bucket
+type FileHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewFileHandlers creates a new set of file handlers
+func NewFileHandlers(imageService *service.ImageService) *FileHandlers {
+	return &FileHandlers{
+		imageService: imageService,
+	}
+}
+
+// ServeFile handles GET and HEAD /v1/files/{key...}
+//
+// Conditional requests (If-None-Match, If-Modified-Since, If-Range) and
+// single or multiple byte ranges are handled by http.ServeContent; storage is
+// read from the start of each range and the read is dropped once it is served.
+func (h *FileHandlers) ServeFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		file, err := h.imageService.OpenFile(r.Context(), chi.URLParam(r, "*"))
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+		defer func() {
+			if err := file.Content.Close(); err != nil {
+				// The response is already written, so there's nothing left to report
+				return
+			}
+		}()
+
+		// Set before ServeContent so it neither sniffs the type nor skips ETag validation
+		w.Header().Set("Content-Type", file.Info.ContentType)
+		w.Header().Set("Cache-Control", file.CacheControl)
+		if file.Info.ETag != "" {
+			w.Header().Set("ETag", file.Info.ETag)
+		}
+
+		http.ServeContent(w, r, "", file.Info.LastModified, file.Content)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeFile(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+
+	// Upload an image to serve
+	req := httptest.NewRequest(http.MethodPut, "/v1/images/user/"+userGUID.String(), bytes.NewReader([]byte("mock-served-image-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var uploaded ImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+	path := "/v1/files/" + strings.TrimPrefix(uploaded.SmallURL, "https://cdn.example.com/")
+
+	// Full object
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	body := rr.Body.Bytes()
+	etag := rr.Header().Get("ETag")
+	lastModified := rr.Header().Get("Last-Modified")
+	assert.NotEmpty(t, body)
+	assert.Equal(t, "image/jpeg", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=86400", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "bytes", rr.Header().Get("Accept-Ranges"))
+	assert.True(t, strings.HasPrefix(etag, `"`), etag)
+	assert.NotEmpty(t, lastModified)
+
+	t.Run("HEAD", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodHead, path, nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, etag, rr.Header().Get("ETag"))
+		assert.Empty(t, rr.Body.Bytes())
+	})
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("If-None-Match", `"other", `+etag)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Equal(t, etag, rr.Header().Get("ETag"))
+		assert.Empty(t, rr.Body.Bytes())
+	})
+
+	t.Run("If-None-Match takes precedence", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("If-None-Match", `"other"`)
+		req.Header.Set("If-Modified-Since", lastModified)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("If-Modified-Since", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("If-Modified-Since", time.Now().UTC().Add(time.Hour).Format(http.TimeFormat))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Range", "bytes=2-5")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, body[2:6], rr.Body.Bytes())
+		assert.Equal(t, "4", rr.Header().Get("Content-Length"))
+	})
+
+	t.Run("Suffix range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Range", "bytes=-3")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, body[len(body)-3:], rr.Body.Bytes())
+	})
+
+	t.Run("Stale If-Range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Range", "bytes=2-5")
+		req.Header.Set("If-Range", `"other"`)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, body, rr.Body.Bytes())
+	})
+
+	t.Run("Unsatisfiable range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Range", "bytes=100000-")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rr.Code)
+	})
+
+	for _, hidden := range []string{
+		"/v1/files/images/user/" + userGUID.String() + "/" + uuid.New().String() + "/small.jpg",
+		"/v1/files/images/unknown/" + userGUID.String() + "/" + uploaded.ImageGUID.String() + "/small.jpg",
+		"/v1/files/staging/user/" + userGUID.String() + "/" + uuid.New().String(),
+		"/v1/files/images/../resumable/" + uuid.New().String() + "/info",
+	} {
+		t.Run("Not found "+hidden, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, hidden, nil))
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/google/uuid"
+)
+
+// idempotencyReservation is how long an in-progress key blocks retries without
+// being renewed. The key is renewed every half period while its request runs,
+// so slow uploads keep it, while a request cut short by a crash releases it
+// well before the TTL expires.
+const idempotencyReservation = 2 * time.Minute
+
+// maxTrailingBodyBytes is how much unread body is drained after the handler
+// returns; handlers may stop at a multipart close boundary, not at EOF
+const maxTrailingBodyBytes = 4 * 1024
+
+// idempotent creates a middleware that stores the responses of write
+// requests sent with an Idempotency-Key header and replays them for retries.
+// Keys are scoped to the authenticated user, so it must run after jwtAuth.
+func (r *Router) idempotent() func(http.Handler) http.Handler {
+	maxReplayBytes := r.imageService.MaxUploadSize() + maxMultipartOverheadBytes
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := req.Header.Get(idempotency.HeaderName)
+			if key == "" || req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+				next.ServeHTTP(w, req)
+				return
+			}
+			if len(key) > idempotency.MaxKeyLength {
+				writeError(w, req, http.StatusBadRequest, problem.InvalidIdempotencyKey,
+					fmt.Sprintf("Idempotency-Key must be at most %d characters", idempotency.MaxKeyLength))
+				return
+			}
+
+			userID, ok := auth.GetUserIDFromContext(req.Context())
+			if !ok {
+				writeError(w, req, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+				return
+			}
+
+			record := &idempotency.Record{
+				UserID:    userID,
+				Key:       key,
+				Token:     uuid.NewString(),
+				ExpiresAt: time.Now().Add(idempotencyReservation),
+			}
+			existing, err := r.idempotencyStore.Reserve(req.Context(), record)
+			if err != nil {
+				r.logger.Errorw("Failed to reserve idempotency key", "error", err, "userID", userID)
+				writeError(w, req, http.StatusInternalServerError, problem.InternalError, "An unexpected error occurred")
+				return
+			}
+			if existing != nil {
+				replayResponse(w, req, existing, maxReplayBytes)
+				return
+			}
+
+			body := newHashingBody(req)
+			req.Body = body
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			// Release the key unless the response is stored, so the request can be retried
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := r.idempotencyStore.Release(context.WithoutCancel(req.Context()), userID, key, record.Token); err != nil {
+					r.logger.Errorw("Failed to release idempotency key", "error", err, "userID", userID)
+				}
+			}()
+
+			stopExtending := r.extendReservation(req.Context(), record, idempotencyReservation)
+			next.ServeHTTP(recorder, req)
+			stopExtending()
+
+			// Server errors are worth retrying, and a partly read body can't be compared
+			if recorder.status >= http.StatusInternalServerError || !body.finish() {
+				return
+			}
+
+			record.RequestHash = body.sum()
+			record.StatusCode = recorder.status
+			record.Header = recorder.Header().Clone()
+			record.Body = recorder.body.Bytes()
+			record.ExpiresAt = time.Now().Add(r.config.Idempotency.TTL)
+			if err := r.idempotencyStore.Complete(context.WithoutCancel(req.Context()), record); err != nil {
+				if errors.Is(err, idempotency.ErrNotReserved) {
+					// The reservation lapsed and another request holds the key now
+					r.logger.Warnw("Idempotency key was reserved again before the response was stored", "userID", userID)
+					return
+				}
+				r.logger.Errorw("Failed to store idempotent response", "error", err, "userID", userID)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// extendReservation renews the record's reservation every half period,
+// keeping it reserved for a period past each renewal, until the returned
+// function is called
+func (r *Router) extendReservation(ctx context.Context, record *idempotency.Record, period time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(period / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := r.idempotencyStore.Extend(ctx, record.UserID, record.Key, record.Token, now.Add(period)); err != nil {
+					r.logger.Warnw("Failed to extend idempotency key", "error", err, "userID", record.UserID)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// replayResponse writes the stored response of a completed key, provided the
+// request is the same one that claimed it
+func replayResponse(w http.ResponseWriter, req *http.Request, record *idempotency.Record, maxBytes int64) {
+	if !record.Completed() {
+		writeError(w, req, http.StatusConflict, problem.IdempotencyKeyInUse, "A request with this Idempotency-Key is still in progress")
+		return
+	}
+
+	body := newHashingBody(req)
+	if _, err := io.Copy(io.Discard, io.LimitReader(body, maxBytes+1)); err != nil || !body.eof || body.sum() != record.RequestHash {
+		writeError(w, req, http.StatusUnprocessableEntity, problem.IdempotencyKeyMismatch, "Idempotency-Key was already used for a different request")
+		return
+	}
+
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(idempotency.ReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	if _, err := w.Write(record.Body); err != nil {
+		// The status is already sent, so there's nothing left to report
+		return
+	}
+}
+
+// hashingBody hashes a request as its body is read: the method, URI and
+// content type, then every body byte
+type hashingBody struct {
+	body io.ReadCloser
+	hash hash.Hash
+	eof  bool
+}
+
+// newHashingBody wraps the request body; the caller replaces req.Body if the handler reads it
+func newHashingBody(req *http.Request) *hashingBody {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n%s\n", req.Method, req.URL.RequestURI(), req.Header.Get("Content-Type"))
+	return &hashingBody{body: req.Body, hash: h}
+}
+
+// Read reads from the body and hashes what was read
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.hash.Write(p[:n])
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// Close closes the underlying body
+func (b *hashingBody) Close() error {
+	return b.body.Close()
+}
+
+// finish drains a short unread tail and reports whether the whole body was hashed
+func (b *hashingBody) finish() bool {
+	if !b.eof {
+		_, _ = io.Copy(io.Discard, io.LimitReader(b, maxTrailingBodyBytes))
+	}
+	return b.eof
+}
+
+// sum returns the hex hash of everything read so far
+func (b *hashingBody) sum() string {
+	return hex.EncodeToString(b.hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader records the status code
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(p)
+	return rr.ResponseWriter.Write(p)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.Algorithm = "HS256"
+	cfg.Idempotency.TTL = time.Hour
+
+	store := idempotency.NewMemoryStore()
+	router := NewRouter(logger.Sugar(), cfg, newTestImageService(t), store, newTestWebhooks()).Handler()
+
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+
+	upload := func(key, token string, data []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "image/jpeg")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if key != "" {
+			req.Header.Set(idempotency.HeaderName, key)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	imageGUID := func(rr *httptest.ResponseRecorder) uuid.UUID {
+		var response UserImageResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.ImageGUID
+	}
+
+	imageData := []byte("mock-idempotent-image-data")
+	first := upload("upload-1", token, imageData)
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get(idempotency.ReplayedHeader))
+
+	// A retry replays the original response instead of uploading again
+	retry := upload("upload-1", token, imageData)
+	require.Equal(t, http.StatusOK, retry.Code, retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(idempotency.ReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+
+	// Reusing the key for a different request is rejected
+	rr := upload("upload-1", token, []byte("mock-other-image-data"))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+
+	// Keys are scoped to the caller
+	rr = upload("upload-1", newTestToken(t, uuid.New().String()), imageData)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Empty(t, rr.Header().Get(idempotency.ReplayedHeader))
+
+	// A new key, or none, uploads again
+	rr = upload("upload-2", token, imageData)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.NotEqual(t, imageGUID(first), imageGUID(rr))
+
+	// A key still held by an in-flight request conflicts
+	_, err := store.Reserve(context.Background(), &idempotency.Record{
+		UserID:    userGUID.String(),
+		Key:       "upload-3",
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	rr = upload("upload-3", token, imageData)
+	assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+
+	// Client errors are stored and replayed like any other response
+	rr = upload("upload-4", token, []byte{})
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	rr = upload("upload-4", token, []byte{})
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	assert.Equal(t, "true", rr.Header().Get(idempotency.ReplayedHeader))
+
+	rr = upload(string(bytes.Repeat([]byte("k"), idempotency.MaxKeyLength+1)), token, imageData)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+}
+
+func TestIdempotencyKey_ExtendReservation(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.Algorithm = "HS256"
+
+	store := idempotency.NewMemoryStore()
+	router := NewRouter(logger.Sugar(), cfg, newTestImageService(t), store, newTestWebhooks())
+
+	const period = 20 * time.Millisecond
+	ctx := context.Background()
+	reserve := func() *idempotency.Record {
+		existing, err := store.Reserve(ctx, &idempotency.Record{UserID: "user-1", Key: "slow", Token: "token-1", ExpiresAt: time.Now().Add(period)})
+		require.NoError(t, err)
+		return existing
+	}
+	require.Nil(t, reserve())
+
+	// A request running for several periods keeps its key
+	stop := router.extendReservation(ctx, &idempotency.Record{UserID: "user-1", Key: "slow", Token: "token-1"}, period)
+	time.Sleep(5 * period)
+	assert.NotNil(t, reserve())
+	stop()
+
+	// Once it stops, the key lapses after a period
+	time.Sleep(2 * period)
+	assert.Nil(t, reserve())
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ImageResponse represents the response format for the generic image endpoints
+type ImageResponse struct {
+	TypeName      string    `json:"typeName"`
+	OwnerGUID     uuid.UUID `json:"ownerGuid"`
+	ImageGUID     uuid.UUID `json:"imageGuid"`
+	SmallURL      string    `json:"smallUrl"`
+	MediumURL     string    `json:"mediumUrl"`
+	LargeURL      string    `json:"largeUrl"`
+	AltText       string    `json:"altText,omitempty"`
+	UpdatedAt     string    `json:"updatedAt,omitempty"`     // Empty for placeholders
+	IsPlaceholder bool      `json:"isPlaceholder,omitempty"` // Generated stand-in, the owner has no image
+}
+
+// ImageHandlers contains type-agnostic handlers for /v1/images/{typeName}/{ownerGuid}.
+// Each handler is bound to one configured image type when the routes are generated.
+type ImageHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewImageHandlers creates a new set of generic image handlers
+func NewImageHandlers(imageService *service.ImageService) *ImageHandlers {
+	return &ImageHandlers{
+		imageService: imageService,
+	}
+}
+
+// UploadImage handles PUT (single types) or POST (collection types) /v1/images/{typeName}/{ownerGuid}
+func (h *ImageHandlers) UploadImage(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+
+		// Enforce the type's ownership rule
+		if !authorizeOwnerWrite(w, r, &imageType, ownerGUID) {
+			return
+		}
+
+		// Read the image from a raw or multipart body
+		imageData, opts, ok := readImageUpload(w, r, h.imageService, imageType.Name)
+		if !ok {
+			return
+		}
+
+		// Process and store the image
+		opts.Precondition = parsePrecondition(r)
+		if prefersAsync(r) {
+			submitUploadJob(w, r, h.imageService, imageType.Name, ownerGUID, imageData, opts)
+			return
+		}
+		image, err := h.imageService.UploadImage(r.Context(), imageType.Name, ownerGUID, imageData, opts)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		setETag(w, image.ETag())
+		status := http.StatusOK
+		if imageType.IsCollection() {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, toImageResponse(image))
+	}
+}
+
+// GetImage handles GET /v1/images/{typeName}/{ownerGuid}
+func (h *ImageHandlers) GetImage(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+
+		image, err := h.imageService.GetImageOrPlaceholder(r.Context(), imageType.Name, ownerGUID, r.URL.Query().Get("name"))
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "Owner has no "+imageType.Name+" image")
+				return
+			}
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		setETag(w, image.ETag())
+		writeJSON(w, http.StatusOK, toImageResponse(image))
+	}
+}
+
+// RedirectImage handles GET /v1/images/{typeName}/{ownerGuid}/{size}
+func (h *ImageHandlers) RedirectImage(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+
+		redirectToImageSize(w, r, h.imageService, imageType.Name, ownerGUID)
+	}
+}
+
+// DeleteImage handles DELETE /v1/images/{typeName}/{ownerGuid}
+func (h *ImageHandlers) DeleteImage(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+
+		// Enforce the type's ownership rule
+		if !authorizeOwnerWrite(w, r, &imageType, ownerGUID) {
+			return
+		}
+
+		err := h.imageService.DeleteImageIf(r.Context(), imageType.Name, ownerGUID, parsePrecondition(r))
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "Owner has no "+imageType.Name+" image to delete")
+				return
+			}
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{
+			"message": "Image deleted successfully",
+		})
+	}
+}
+
+// CreateUploadTicketRequest is the request body for a direct-to-storage upload ticket
+type CreateUploadTicketRequest struct {
+	Method      string `json:"method,omitempty"`      // "PUT" (default) or "POST"
+	ContentType string `json:"contentType,omitempty"` // Required for PUT
+	Size        int64  `json:"size,omitempty"`        // Required for PUT: the file's exact size in bytes
+}
+
+// UploadTicketResponse describes how to upload an image directly to storage
+type UploadTicketResponse struct {
+	UploadGUID   uuid.UUID         `json:"uploadGuid"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	MaxBytes     int64             `json:"maxBytes"`
+	ContentTypes []string          `json:"contentTypes"`
+	ExpiresAt    string            `json:"expiresAt"`
+}
+
+// FinalizeUploadRequest is the optional request body for finalizing a direct upload
+type FinalizeUploadRequest struct {
+	AltText string `json:"altText,omitempty"`
+	Crop    string `json:"crop,omitempty"` // "x,y,width,height", as in multipart uploads
+}
+
+// CreateUploadTicket handles POST /v1/images/{typeName}/{ownerGuid}/uploads
+func (h *ImageHandlers) CreateUploadTicket(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+
+		// Enforce the type's ownership rule
+		if !authorizeOwnerWrite(w, r, &imageType, ownerGUID) {
+			return
+		}
+
+		var req CreateUploadTicketRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidRequest, "Request body must be JSON with a method and contentType")
+			return
+		}
+
+		ticket, err := h.imageService.CreateUploadTicket(r.Context(), imageType.Name, ownerGUID, req.Method, req.ContentType, req.Size)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, UploadTicketResponse{
+			UploadGUID:   ticket.UploadGUID,
+			Method:       ticket.Method,
+			URL:          ticket.URL,
+			Headers:      ticket.Headers,
+			Fields:       ticket.Fields,
+			MaxBytes:     ticket.MaxBytes,
+			ContentTypes: ticket.ContentTypes,
+			ExpiresAt:    ticket.ExpiresAt.Format(http.TimeFormat),
+		})
+	}
+}
+
+// FinalizeUpload handles POST /v1/images/{typeName}/{ownerGuid}/uploads/{uploadGuid}/finalize
+func (h *ImageHandlers) FinalizeUpload(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+		uploadGUID, ok := parseGUIDParam(w, r, "uploadGuid", problem.InvalidUploadID, "Upload")
+		if !ok {
+			return
+		}
+
+		// Enforce the type's ownership rule
+		if !authorizeOwnerWrite(w, r, &imageType, ownerGUID) {
+			return
+		}
+
+		// The body is optional
+		var req FinalizeUploadRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidRequest, "Request body must be JSON")
+			return
+		}
+		crop, err := parseCrop(req.Crop)
+		if err != nil {
+			writeFieldError(w, r, problem.InvalidUploadOptions, "crop", "crop must be \"x,y,width,height\"")
+			return
+		}
+
+		opts := domain.UploadOptions{AltText: strings.TrimSpace(req.AltText), Crop: crop}
+		image, err := h.imageService.FinalizeUpload(r.Context(), imageType.Name, ownerGUID, uploadGUID, opts)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.UploadNotFound, "No uploaded object for this upload ID")
+				return
+			}
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		status := http.StatusOK
+		if imageType.IsCollection() {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, toImageResponse(image))
+	}
+}
+
+// imageRedirectCacheControl keeps size redirects short-lived so they follow new uploads
+const imageRedirectCacheControl = "public, max-age=60"
+
+// redirectToImageSize answers with a 302 to the owner's current variant of the
+// {size} URL parameter, or to the type's default image or placeholder when the
+// owner has none
+func redirectToImageSize(w http.ResponseWriter, r *http.Request, imageService *service.ImageService, typeName string, ownerGUID uuid.UUID) {
+	url, err := imageService.ImageSizeURL(r.Context(), typeName, ownerGUID, chi.URLParam(r, "size"), r.URL.Query().Get("name"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "Owner has no "+typeName+" image")
+			return
+		}
+		handleImageServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", imageRedirectCacheControl)
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// toImageResponse converts an image to the generic response format
+func toImageResponse(image *domain.Image) ImageResponse {
+	return ImageResponse{
+		TypeName:      image.TypeName,
+		OwnerGUID:     image.OwnerGUID,
+		ImageGUID:     image.GUID,
+		SmallURL:      image.SmallURL,
+		MediumURL:     image.MediumURL,
+		LargeURL:      image.LargeURL,
+		AltText:       image.AltText,
+		UpdatedAt:     formatUpdatedAt(image.UpdatedAt),
+		IsPlaceholder: image.IsPlaceholder,
+	}
+}
+
+// formatUpdatedAt formats an image's update time; placeholders have none
+func formatUpdatedAt(updatedAt time.Time) string {
+	if updatedAt.IsZero() {
+		return ""
+	}
+	return updatedAt.Format(http.TimeFormat)
+}
+
+// setETag sets the ETag response header, unless there is no entity tag
+func setETag(w http.ResponseWriter, etag string) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+}
+
+// parsePrecondition reads the If-Match and If-None-Match request headers
+func parsePrecondition(r *http.Request) domain.Precondition {
+	return domain.Precondition{
+		IfMatch:     parseETagList(r.Header.Values("If-Match")),
+		IfNoneMatch: parseETagList(r.Header.Values("If-None-Match")),
+	}
+}
+
+// parseETagList splits comma-separated entity tag header values
+func parseETagList(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// authorizeOwnerWrite checks the authenticated caller against the image type's
+// ownership rule for the given owner, writing an error response if denied
+func authorizeOwnerWrite(w http.ResponseWriter, r *http.Request, imageType *domain.ImageType, ownerGUID uuid.UUID) bool {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+		return false
+	}
+
+	switch imageType.OwnershipRule() {
+	case domain.OwnershipAuthenticated:
+		return true
+	case domain.OwnershipOrganizationAdmin:
+		if auth.IsOrganizationAdmin(r.Context(), ownerGUID.String()) {
+			return true
+		}
+		writeError(w, r, http.StatusForbidden, problem.Forbidden, "Caller is not an admin of this organization")
+		return false
+	default:
+		if strings.EqualFold(userID, ownerGUID.String()) {
+			return true
+		}
+		writeError(w, r, http.StatusForbidden, problem.Forbidden, "Caller does not own this image")
+		return false
+	}
+}
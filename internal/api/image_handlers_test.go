@@ -0,0 +1,268 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/webhook"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testJWTSecret = "test-secret"
+
+const testRenderKey = "test-render-key"
+
+// newTestRouter creates the full API router with HS256 authentication
+func newTestRouter(t *testing.T) http.Handler {
+	return newTestRouterWithService(newTestImageService(t))
+}
+
+// newTestRouterWithService creates the full API router around a prepared image service
+func newTestRouterWithService(imageService *service.ImageService) http.Handler {
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.Algorithm = "HS256"
+	cfg.Render.SigningKey = testRenderKey
+
+	cfg.Idempotency.TTL = time.Hour
+
+	return NewRouter(logger.Sugar(), cfg, imageService, idempotency.NewMemoryStore(), newTestWebhooks()).Handler()
+}
+
+// newTestWebhooks creates a webhook dispatcher without subscriptions
+func newTestWebhooks() *webhook.Dispatcher {
+	return webhook.NewDispatcher(webhook.NewMemoryStore(), queue.NewMemoryQueue(), &domain.ImageConfig{}, webhook.DefaultConfig(), zap.NewNop().Sugar())
+}
+
+// newTestToken signs an HS256 token for the given subject and organization memberships
+func newTestToken(t *testing.T, subject string, orgs ...auth.OrganizationMembership) string {
+	claims := auth.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+		Organizations:    orgs,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestGenericImageRoutes_Ownership(t *testing.T) {
+	userGUID := uuid.New()
+	orgGUID := uuid.New()
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{
+			name:       "Missing token",
+			path:       "/v1/images/user/" + userGUID.String(),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Self type, own image",
+			path:       "/v1/images/user/" + userGUID.String(),
+			token:      newTestToken(t, userGUID.String()),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Self type, another user's image",
+			path:       "/v1/images/user/" + uuid.New().String(),
+			token:      newTestToken(t, userGUID.String()),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Organization type, not an admin",
+			path:       "/v1/images/organization/" + orgGUID.String(),
+			token:      newTestToken(t, userGUID.String(), auth.OrganizationMembership{GUID: orgGUID.String(), Role: "member"}),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Organization type, admin",
+			path:       "/v1/images/organization/" + orgGUID.String(),
+			token:      newTestToken(t, userGUID.String(), auth.OrganizationMembership{GUID: orgGUID.String(), Role: auth.RoleAdmin}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Unconfigured type",
+			path:       "/v1/images/banner/" + userGUID.String(),
+			token:      newTestToken(t, userGUID.String()),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+
+			req := httptest.NewRequest(http.MethodPut, tt.path, bytes.NewReader([]byte("mock-generic-image-data")))
+			req.Header.Set("Content-Type", "image/jpeg")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestGenericImageRoutes_UploadGetDelete(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+	path := "/v1/images/user/" + userGUID.String()
+	token := newTestToken(t, userGUID.String())
+
+	// Upload
+	req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader([]byte("mock-generic-image-data")))
+	req.Header.Set("Content-Type", "image/png")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var uploaded ImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+	assert.Equal(t, "user", uploaded.TypeName)
+	assert.Equal(t, userGUID, uploaded.OwnerGUID)
+
+	// Public get
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var fetched ImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &fetched))
+	assert.Equal(t, uploaded.ImageGUID, fetched.ImageGUID)
+
+	// Delete
+	req = httptest.NewRequest(http.MethodDelete, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Get after delete
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGenericImageRoutes_DirectUpload(t *testing.T) {
+	router := newTestRouter(t)
+
+	userGUID := uuid.New()
+	path := "/v1/images/user/" + userGUID.String()
+	token := newTestToken(t, userGUID.String())
+
+	// Issue a ticket
+	req := httptest.NewRequest(http.MethodPost, path+"/uploads", bytes.NewReader([]byte(`{"method": "PUT", "contentType": "image/jpeg", "size": 2048}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var ticket UploadTicketResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ticket))
+	assert.Equal(t, http.MethodPut, ticket.Method)
+	assert.NotEmpty(t, ticket.URL)
+
+	// Another user cannot finalize it
+	finalizePath := path + "/uploads/" + ticket.UploadGUID.String() + "/finalize"
+	req = httptest.NewRequest(http.MethodPost, finalizePath, nil)
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, uuid.New().String()))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Finalizing before the object exists is not found
+	req = httptest.NewRequest(http.MethodPost, finalizePath, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestImageSizeRedirect(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+
+	// Upload a user image
+	req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte("mock-redirect-image-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var uploaded UserImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "Current variant",
+			path:         "/v1/users/" + userGUID.String() + "/image/medium",
+			wantStatus:   http.StatusFound,
+			wantLocation: uploaded.MediumURL,
+		},
+		{
+			name:         "Generic route",
+			path:         "/v1/images/user/" + userGUID.String() + "/large",
+			wantStatus:   http.StatusFound,
+			wantLocation: uploaded.LargeURL,
+		},
+		{
+			name:       "Unknown size",
+			path:       "/v1/users/" + userGUID.String() + "/image/huge",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "No image and no default",
+			path:       "/v1/users/" + uuid.New().String() + "/image/small",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:         "Default image",
+			path:         "/v1/organizations/" + uuid.New().String() + "/image/small",
+			wantStatus:   http.StatusFound,
+			wantLocation: "https://cdn.example.com/defaults/organization-small.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			if tt.wantStatus == http.StatusFound {
+				assert.Equal(t, tt.wantLocation, rr.Header().Get("Location"))
+				assert.Equal(t, imageRedirectCacheControl, rr.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/fetcher"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportImageFromURL(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/avatar.png" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("mock-remote-image-data"))
+	}))
+	defer source.Close()
+
+	// Only the httptest server's loopback address is exempt from the private-range block
+	allowed, err := fetcher.ParseNetworks([]string{"127.0.0.1/32"})
+	require.NoError(t, err)
+	fetchConfig := fetcher.DefaultConfig()
+	fetchConfig.AllowedNetworks = allowed
+
+	imageService := newTestImageService(t)
+	imageService.SetRemoteFetcher(fetcher.New(fetchConfig))
+
+	router := newTestRouterWithService(imageService)
+
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Allowed source",
+			body:       `{"sourceUrl": "` + source.URL + `/avatar.png", "altText": "Avatar"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Source returns an error",
+			body:       `{"sourceUrl": "` + source.URL + `/missing.png"}`,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "Private address",
+			body:       `{"sourceUrl": "http://169.254.169.254/latest/meta-data/"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Missing sourceUrl",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var resp UserImageResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, userGUID, resp.UserGUID)
+				assert.Equal(t, "Avatar", resp.AltText)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/google/uuid"
+)
+
+// JobResponse represents an upload processed in the background
+type JobResponse struct {
+	JobGUID   uuid.UUID      `json:"jobGuid"`
+	Status    string         `json:"status"`
+	TypeName  string         `json:"typeName"`
+	OwnerGUID uuid.UUID      `json:"ownerGuid"`
+	ImageGUID *uuid.UUID     `json:"imageGuid,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+	Image     *ImageResponse `json:"image,omitempty"` // Set once the job is ready
+}
+
+// JobHandlers contains handlers for background processing jobs
+type JobHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewJobHandlers creates a new set of job handlers
+func NewJobHandlers(imageService *service.ImageService) *JobHandlers {
+	return &JobHandlers{
+		imageService: imageService,
+	}
+}
+
+// GetJob handles GET /v1/jobs/{jobGuid}
+//
+// Only the user who submitted the upload can look the job up.
+func (h *JobHandlers) GetJob() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := auth.GetUserIDFromContext(r.Context())
+		if !ok {
+			writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+			return
+		}
+
+		jobGUID, ok := parseGUIDParam(w, r, "jobGuid", problem.InvalidJobID, "Job")
+		if !ok {
+			return
+		}
+
+		job, image, err := h.imageService.GetJob(r.Context(), userID, jobGUID)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.JobNotFound, "Job not found")
+				return
+			}
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toJobResponse(job, image))
+	}
+}
+
+// prefersAsync reports whether the client asked, with Prefer: respond-async
+// (RFC 7240), for the upload to be processed in the background
+func prefersAsync(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// submitUploadJob queues an upload for background processing and answers
+// 202 Accepted with the job, whose status is polled at its Location
+func submitUploadJob(w http.ResponseWriter, r *http.Request, imageService *service.ImageService, typeName string, ownerGUID uuid.UUID, imageData io.Reader, opts domain.UploadOptions) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+		return
+	}
+
+	job, err := imageService.SubmitUpload(r.Context(), userID, typeName, ownerGUID, imageData, opts)
+	if err != nil {
+		handleImageServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v1/jobs/"+job.GUID.String())
+	w.Header().Set("Preference-Applied", "respond-async")
+	writeJSON(w, http.StatusAccepted, toJobResponse(job, nil))
+}
+
+// toJobResponse converts a job, and the image it produced if any, to the
+// response format
+func toJobResponse(job *domain.Job, image *domain.Image) JobResponse {
+	response := JobResponse{
+		JobGUID:   job.GUID,
+		Status:    job.Status,
+		TypeName:  job.TypeName,
+		OwnerGUID: job.OwnerGUID,
+		ImageGUID: job.ImageGUID,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: job.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if image != nil {
+		imageResponse := toImageResponse(image)
+		response.Image = &imageResponse
+	}
+	return response
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAsyncUpload(t *testing.T) {
+	imageService := newTestImageService(t)
+	jobQueue := queue.NewMemoryQueue()
+	imageService.SetQueue(jobQueue)
+	worker := queue.NewWorker(jobQueue, queue.DefaultConfig(), zap.NewNop().Sugar())
+	imageService.RegisterJobHandlers(worker)
+	router := newTestRouterWithService(imageService)
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+
+	send := func(method, path string, body []byte, token string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", "image/jpeg")
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	async := http.Header{"Prefer": {"wait=10, respond-async"}}
+
+	rr := send(http.MethodPut, "/v1/me/image", []byte("mock-async-image-data"), token, async)
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	assert.Equal(t, "respond-async", rr.Header().Get("Preference-Applied"))
+
+	var submitted JobResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &submitted))
+	assert.Equal(t, domain.JobPending, submitted.Status)
+	assert.Equal(t, "user", submitted.TypeName)
+	assert.Equal(t, userGUID, submitted.OwnerGUID)
+	location := rr.Header().Get("Location")
+	assert.Equal(t, "/v1/jobs/"+submitted.JobGUID.String(), location)
+
+	// Nothing is stored until the job has run
+	rr = send(http.MethodGet, "/v1/users/"+userGUID.String()+"/image", nil, token, nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = send(http.MethodGet, location, nil, token, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Only the submitting user can poll the job
+	rr = send(http.MethodGet, location, nil, newTestToken(t, uuid.New().String()), nil)
+	decodeProblem(t, rr, http.StatusNotFound, problem.JobNotFound)
+
+	// The upload, and the deletion of its original
+	processed, err := worker.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+
+	rr = send(http.MethodGet, location, nil, token, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var finished JobResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &finished))
+	assert.Equal(t, domain.JobReady, finished.Status)
+	require.NotNil(t, finished.Image)
+	require.NotNil(t, finished.ImageGUID)
+	assert.Equal(t, *finished.ImageGUID, finished.Image.ImageGUID)
+	assert.Equal(t, userGUID, finished.Image.OwnerGUID)
+
+	rr = send(http.MethodGet, "/v1/users/"+userGUID.String()+"/image", nil, token, nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Invalid uploads are rejected before a job is queued
+	rr = send(http.MethodPut, "/v1/me/image", []byte{}, token, async)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+	// Without the preference uploads are processed in the request
+	rr = send(http.MethodPut, "/v1/images/user/"+userGUID.String(), []byte("mock-async-image-data"), token, nil)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = send(http.MethodGet, "/v1/jobs/not-a-guid", nil, token, nil)
+	decodeProblem(t, rr, http.StatusBadRequest, problem.InvalidJobID)
+}
+
+func TestPrefersAsync(t *testing.T) {
+	tests := []struct {
+		prefer []string
+		want   bool
+	}{
+		{prefer: nil, want: false},
+		{prefer: []string{"respond-async"}, want: true},
+		{prefer: []string{"Respond-Async; foo=bar"}, want: true},
+		{prefer: []string{"return=minimal", "wait=5,respond-async"}, want: true},
+		{prefer: []string{"return=representation"}, want: false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/image", nil)
+		for _, value := range tt.prefer {
+			req.Header.Add("Prefer", value)
+		}
+		assert.Equal(t, tt.want, prefersAsync(req), tt.prefer)
+	}
+}
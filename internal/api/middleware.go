@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// recoverer turns a panicking handler into a 500 problem response and logs
+// the panic with its stack. Aborted handlers are re-panicked so net/http can
+// drop the connection as intended.
+func (r *Router) recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			r.logger.Errorw("Panic while handling request",
+				"panic", rvr,
+				"method", req.Method,
+				"path", req.URL.Path,
+				"requestId", middleware.GetReqID(req.Context()),
+				"stack", string(debug.Stack()))
+
+			// A partially written response can't be turned into an error anymore
+			if ww.Status() == 0 {
+				writeError(ww, req, http.StatusInternalServerError, problem.InternalError, "An unexpected error occurred")
+			}
+		}()
+
+		next.ServeHTTP(ww, req)
+	})
+}
+
+// timeout cancels the request context after d and answers 504 if the handler
+// gave up without writing a response
+func timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && ww.Status() == 0 {
+				writeError(ww, r, http.StatusGatewayTimeout, problem.Timeout, "Request did not complete in time")
+			}
+		})
+	}
+}
+
+// allowContentType rejects request bodies whose media type isn't listed with
+// 415 Unsupported Media Type. Requests without a body are always allowed.
+func allowContentType(contentTypes ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(contentTypes))
+	for _, contentType := range contentTypes {
+		allowed[strings.ToLower(strings.TrimSpace(contentType))] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+			if _, ok := allowed[strings.ToLower(strings.TrimSpace(mediaType))]; !ok {
+				writeError(w, r, http.StatusUnsupportedMediaType, problem.InvalidContentType,
+					"Content-Type must be one of "+strings.Join(contentTypes, ", "))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// notFound answers requests that match no route
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, problem.NotFound, "No resource at this path")
+}
+
+// allowMethods are the methods listed in the Allow header of 405 responses
+var allowMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// methodNotAllowed answers requests whose path exists but not for their
+// method, listing the methods that are routed for it
+func (r *Router) methodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	for _, method := range allowMethods {
+		if r.router.Match(chi.NewRouteContext(), method, req.URL.Path) {
+			w.Header().Add("Allow", method)
+		}
+	}
+	writeError(w, req, http.StatusMethodNotAllowed, problem.MethodNotAllowed, req.Method+" is not supported on this path")
+}
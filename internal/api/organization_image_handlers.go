@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/google/uuid"
+)
+
+// OrganizationImageResponse represents the response format for organization image endpoints
+type OrganizationImageResponse struct {
+	OrganizationGUID uuid.UUID `json:"organizationGuid"`
+	ImageGUID        uuid.UUID `json:"imageGuid"`
+	SmallURL         string    `json:"smallUrl"`
+	MediumURL        string    `json:"mediumUrl"`
+	LargeURL         string    `json:"largeUrl"`
+	AltText          string    `json:"altText,omitempty"`
+	UpdatedAt        string    `json:"updatedAt"`
+}
+
+// OrganizationImageHandlers contains handlers for organization image endpoints
+type OrganizationImageHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewOrganizationImageHandlers creates a new set of organization image handlers
+func NewOrganizationImageHandlers(imageService *service.ImageService) *OrganizationImageHandlers {
+	return &OrganizationImageHandlers{
+		imageService: imageService,
+	}
+}
+
+// UploadOrganizationImage handles PUT /v1/organizations/{orgGuid}/image
+func (h *OrganizationImageHandlers) UploadOrganizationImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Extract and authorize the organization GUID
+		orgGUID, ok := authorizeOrganizationAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		// Read the image from a raw or multipart body
+		imageData, opts, ok := readImageUpload(w, r, h.imageService, service.OrganizationImageType)
+		if !ok {
+			return
+		}
+
+		// Process and store the image, provided the current image matches any precondition
+		opts.Precondition = parsePrecondition(r)
+		if prefersAsync(r) {
+			submitUploadJob(w, r, h.imageService, service.OrganizationImageType, orgGUID, imageData, opts)
+			return
+		}
+		orgImage, err := h.imageService.UploadOrganizationImage(r.Context(), orgGUID, imageData, opts)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+		setETag(w, domain.ETag(orgImage.ImageGUID, orgImage.UpdatedAt))
+
+		// Prepare response
+		response := OrganizationImageResponse{
+			OrganizationGUID: orgImage.OrganizationGUID,
+			ImageGUID:        orgImage.ImageGUID,
+			SmallURL:         orgImage.SmallURL,
+			MediumURL:        orgImage.MediumURL,
+			LargeURL:         orgImage.LargeURL,
+			AltText:          orgImage.AltText,
+			UpdatedAt:        orgImage.UpdatedAt.Format(http.TimeFormat),
+		}
+
+		// Return success response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			_ = err // Acknowledge the error to satisfy linter
+		}
+	}
+}
+
+// GetOrganizationImage handles GET /v1/organizations/{orgGuid}/image
+func (h *OrganizationImageHandlers) GetOrganizationImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Extract organization GUID from URL path
+		orgGUID, ok := parseOrganizationGUID(w, r)
+		if !ok {
+			return
+		}
+
+		// Get the organization's image
+		orgImage, err := h.imageService.GetOrganizationImage(r.Context(), orgGUID)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "Organization has no image")
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to retrieve organization image")
+			return
+		}
+		setETag(w, domain.ETag(orgImage.ImageGUID, orgImage.UpdatedAt))
+
+		// Prepare response
+		response := OrganizationImageResponse{
+			OrganizationGUID: orgImage.OrganizationGUID,
+			ImageGUID:        orgImage.ImageGUID,
+			SmallURL:         orgImage.SmallURL,
+			MediumURL:        orgImage.MediumURL,
+			LargeURL:         orgImage.LargeURL,
+			AltText:          orgImage.AltText,
+			UpdatedAt:        orgImage.UpdatedAt.Format(http.TimeFormat),
+		}
+
+		// Return success response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			_ = err // Acknowledge the error to satisfy linter
+		}
+	}
+}
+
+// DeleteOrganizationImage handles DELETE /v1/organizations/{orgGuid}/image
+func (h *OrganizationImageHandlers) DeleteOrganizationImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Extract and authorize the organization GUID
+		orgGUID, ok := authorizeOrganizationAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		// Delete the organization's image, provided it matches any precondition
+		err := h.imageService.DeleteImageIf(r.Context(), service.OrganizationImageType, orgGUID, parsePrecondition(r))
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "Organization has no image to delete")
+				return
+			}
+			if errors.Is(err, service.ErrPreconditionFailed) || errors.Is(err, service.ErrConcurrentUpdate) {
+				handleImageServiceError(w, r, err)
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to delete organization image")
+			return
+		}
+
+		// Return success response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(map[string]string{
+			"message": "Image deleted successfully",
+		}); err != nil {
+			_ = err // Acknowledge the error to satisfy linter
+		}
+	}
+}
+
+// RedirectOrganizationImage handles GET /v1/organizations/{orgGuid}/image/{size}
+func (h *OrganizationImageHandlers) RedirectOrganizationImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgGUID, ok := parseOrganizationGUID(w, r)
+		if !ok {
+			return
+		}
+
+		redirectToImageSize(w, r, h.imageService, service.OrganizationImageType, orgGUID)
+	}
+}
+
+// parseOrganizationGUID extracts the organization GUID from the URL path,
+// writing an error response if it is missing or malformed
+func parseOrganizationGUID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	return parseGUIDParam(w, r, "orgGuid", problem.InvalidOrganizationID, "Organization")
+}
+
+// authorizeOrganizationAdmin extracts the organization GUID from the URL path and
+// verifies that the authenticated caller is an admin of that organization
+func authorizeOrganizationAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if _, ok := auth.GetUserIDFromContext(r.Context()); !ok {
+		writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+		return uuid.Nil, false
+	}
+
+	orgGUID, ok := parseOrganizationGUID(w, r)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	if !auth.IsOrganizationAdmin(r.Context(), orgGUID.String()) {
+		writeError(w, r, http.StatusForbidden, problem.Forbidden, "Caller is not an admin of this organization")
+		return uuid.Nil, false
+	}
+
+	return orgGUID, true
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestImageService creates an ImageService backed by mocks for handler tests
+func newTestImageService(t *testing.T) *service.ImageService {
+	logger, _ := zap.NewDevelopment()
+
+	imageConfig := &domain.ImageConfig{
+		Types: []domain.ImageType{
+			{
+				Name:      "user",
+				Ownership: domain.OwnershipSelf,
+				Sizes: domain.SizeSet{
+					"small":  {Width: 50, Height: 50},
+					"medium": {Width: 100, Height: 100},
+					"large":  {Width: 800, Height: 800},
+				},
+			},
+			{
+				Name:         "organization",
+				Ownership:    domain.OwnershipOrganizationAdmin,
+				DefaultImage: "https://cdn.example.com/defaults/organization-{size}.png",
+				Sizes: domain.SizeSet{
+					"small":  {Width: 400, Height: 0},
+					"medium": {Width: 800, Height: 0},
+					"large":  {Width: 1000, Height: 0},
+				},
+			},
+		},
+	}
+
+	return service.NewImageService(
+		repository.NewMockImageRepository(),
+		storage.NewMockS3(),
+		processor.NewMockProcessor(),
+		imageConfig,
+		logger.Sugar(),
+	)
+}
+
+// newOrganizationTestRouter wires the organization image handlers behind a mock JWT middleware
+func newOrganizationTestRouter(imageService *service.ImageService, claims *auth.JWTClaims) http.Handler {
+	handlers := NewOrganizationImageHandlers(imageService)
+
+	r := chi.NewRouter()
+	r.Get("/v1/organizations/{orgGuid}/image", handlers.GetOrganizationImage())
+	r.Group(func(protected chi.Router) {
+		protected.Use(auth.MockJWTMiddlewareWithClaims(uuid.New().String(), claims))
+		protected.Put("/v1/organizations/{orgGuid}/image", handlers.UploadOrganizationImage())
+		protected.Delete("/v1/organizations/{orgGuid}/image", handlers.DeleteOrganizationImage())
+	})
+	return r
+}
+
+func TestUploadOrganizationImage_RequiresAdmin(t *testing.T) {
+	orgGUID := uuid.New()
+	imageData := []byte("mock-organization-image-data")
+
+	tests := []struct {
+		name       string
+		claims     *auth.JWTClaims
+		wantStatus int
+	}{
+		{
+			name:       "No memberships",
+			claims:     &auth.JWTClaims{},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Member but not admin",
+			claims: &auth.JWTClaims{Organizations: []auth.OrganizationMembership{
+				{GUID: orgGUID.String(), Role: "member"},
+			}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Admin of another organization",
+			claims: &auth.JWTClaims{Organizations: []auth.OrganizationMembership{
+				{GUID: uuid.New().String(), Role: auth.RoleAdmin},
+			}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Admin of the organization",
+			claims: &auth.JWTClaims{Organizations: []auth.OrganizationMembership{
+				{GUID: orgGUID.String(), Role: auth.RoleAdmin},
+			}},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newOrganizationTestRouter(newTestImageService(t), tt.claims)
+
+			req := httptest.NewRequest(http.MethodPut, "/v1/organizations/"+orgGUID.String()+"/image", bytes.NewReader(imageData))
+			req.Header.Set("Content-Type", "image/jpeg")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestOrganizationImage_UploadGetDelete(t *testing.T) {
+	orgGUID := uuid.New()
+	claims := &auth.JWTClaims{Organizations: []auth.OrganizationMembership{
+		{GUID: orgGUID.String(), Role: auth.RoleAdmin},
+	}}
+	router := newOrganizationTestRouter(newTestImageService(t), claims)
+	path := "/v1/organizations/" + orgGUID.String() + "/image"
+
+	// Upload
+	req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader([]byte("mock-organization-image-data")))
+	req.Header.Set("Content-Type", "image/png")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var uploaded OrganizationImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+	assert.Equal(t, orgGUID, uploaded.OrganizationGUID)
+
+	// Public get
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var fetched OrganizationImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &fetched))
+	assert.Equal(t, uploaded.ImageGUID, fetched.ImageGUID)
+
+	// Delete
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, path, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Get after delete
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
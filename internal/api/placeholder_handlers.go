@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// placeholderCacheControl lets clients keep placeholders for a long time: the
+// same URL always draws the same image
+const placeholderCacheControl = "public, max-age=2592000"
+
+// PlaceholderHandlers serves generated placeholder images
+type PlaceholderHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewPlaceholderHandlers creates a new set of placeholder handlers
+func NewPlaceholderHandlers(imageService *service.ImageService) *PlaceholderHandlers {
+	return &PlaceholderHandlers{
+		imageService: imageService,
+	}
+}
+
+// ServePlaceholder handles GET /v1/placeholders/{typeName}/{ownerGuid}/{size}
+func (h *PlaceholderHandlers) ServePlaceholder(imageType domain.ImageType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ownerGUID, ok := parseGUIDParam(w, r, "ownerGuid", problem.InvalidOwnerID, "Owner")
+		if !ok {
+			return
+		}
+
+		data, err := h.imageService.RenderPlaceholder(imageType.Name, ownerGUID, chi.URLParam(r, "size"), r.URL.Query().Get("initials"))
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Cache-Control", placeholderCacheControl)
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(data); err != nil {
+			// The status code has already been sent, so there's nothing left to report
+			return
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newPlaceholderTestRouter creates the full API router with placeholders enabled for users
+func newPlaceholderTestRouter() http.Handler {
+	logger, _ := zap.NewDevelopment()
+
+	imageConfig := &domain.ImageConfig{
+		Types: []domain.ImageType{
+			{
+				Name:        "user",
+				Ownership:   domain.OwnershipSelf,
+				Placeholder: true,
+				Sizes: domain.SizeSet{
+					"small":  {Width: 50, Height: 50},
+					"medium": {Width: 100, Height: 100},
+					"large":  {Width: 800, Height: 0},
+				},
+			},
+		},
+	}
+
+	return newTestRouterWithService(service.NewImageService(
+		repository.NewMockImageRepository(),
+		storage.NewMockS3(),
+		processor.NewMockProcessor(),
+		imageConfig,
+		logger.Sugar(),
+	))
+}
+
+func TestPlaceholders(t *testing.T) {
+	router := newPlaceholderTestRouter()
+	userGUID := uuid.New()
+
+	// Metadata for a user without an image points at placeholders
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/users/"+userGUID.String()+"/image?name=Ada+Lovelace", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var response UserImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.True(t, response.IsPlaceholder)
+	assert.Empty(t, response.UpdatedAt)
+	assert.Equal(t, "/v1/placeholders/user/"+userGUID.String()+"/small?initials=AL", response.SmallURL)
+
+	// The generic endpoint flags it too
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/images/user/"+userGUID.String(), nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"isPlaceholder":true`)
+
+	// The size endpoint redirects to the placeholder
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/users/"+userGUID.String()+"/image/large?name=Ada+Lovelace", nil))
+	assert.Equal(t, http.StatusFound, rr.Code)
+	location := rr.Header().Get("Location")
+	assert.Equal(t, "/v1/placeholders/user/"+userGUID.String()+"/large?initials=AL", location)
+
+	// The placeholder itself is a cacheable PNG in the configured size
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, location, nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	assert.Equal(t, placeholderCacheControl, rr.Header().Get("Cache-Control"))
+	config, format, err := image.DecodeConfig(bytes.NewReader(rr.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 800, config.Width)
+	assert.Equal(t, 800, config.Height)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/placeholders/user/"+userGUID.String()+"/huge", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Once the user uploads, the real image replaces the placeholder
+	req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte("mock-placeholder-image-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/users/"+userGUID.String()+"/image", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var uploaded UserImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+	assert.False(t, uploaded.IsPlaceholder)
+	assert.NotEmpty(t, uploaded.UpdatedAt)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalImageWrites(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+
+	send := func(method, path string, body []byte, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", "image/jpeg")
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	imageData := []byte("mock-conditional-image-data")
+
+	rr := send(http.MethodPut, "/v1/me/image", imageData, http.Header{"If-None-Match": {"*"}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Metadata lookups carry the same tag
+	rr = send(http.MethodGet, "/v1/users/"+userGUID.String()+"/image", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, etag, rr.Header().Get("ETag"))
+
+	rr = send(http.MethodGet, "/v1/images/user/"+userGUID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, etag, rr.Header().Get("ETag"))
+
+	// Creating again is refused once an image exists
+	rr = send(http.MethodPut, "/v1/me/image", imageData, http.Header{"If-None-Match": {"*"}})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code, rr.Body.String())
+
+	// The second of two devices updating from the same version loses
+	rr = send(http.MethodPut, "/v1/me/image", imageData, http.Header{"If-Match": {etag}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	newETag := rr.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	rr = send(http.MethodPut, "/v1/images/user/"+userGUID.String(), imageData, http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code, rr.Body.String())
+
+	rr = send(http.MethodDelete, "/v1/me/image", nil, http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code, rr.Body.String())
+
+	rr = send(http.MethodDelete, "/v1/me/image", nil, http.Header{"If-Match": {`"other", ` + newETag}})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// decodeProblem checks that a response is problem details and decodes it
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantCode problem.Code) problem.Details {
+	t.Helper()
+	require.Equal(t, wantStatus, rr.Code, rr.Body.String())
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+
+	var details problem.Details
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &details))
+	assert.Equal(t, wantStatus, details.Status)
+	assert.Equal(t, wantCode, details.Code)
+	assert.Equal(t, wantCode.TypeURI(), details.Type)
+	assert.NotEmpty(t, details.Title)
+	assert.NotEmpty(t, details.RequestID)
+	return details
+}
+
+func TestProblemResponses(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+
+	send := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Missing token", func(t *testing.T) {
+		rr := send(httptest.NewRequest(http.MethodGet, "/v1/me/image", nil))
+		details := decodeProblem(t, rr, http.StatusUnauthorized, problem.Unauthorized)
+		assert.Equal(t, "/v1/me/image", details.Instance)
+		assert.Contains(t, rr.Header().Get("WWW-Authenticate"), "Bearer")
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/me/image", nil)
+		req.Header.Set("Authorization", "Bearer not-a-token")
+		decodeProblem(t, send(req), http.StatusUnauthorized, problem.InvalidToken)
+	})
+
+	t.Run("Handler error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/users/not-a-uuid/image", nil)
+		decodeProblem(t, send(req), http.StatusBadRequest, problem.InvalidUserID)
+	})
+
+	t.Run("Unknown route", func(t *testing.T) {
+		decodeProblem(t, send(httptest.NewRequest(http.MethodGet, "/v1/nothing-here", nil)), http.StatusNotFound, problem.NotFound)
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		rr := send(httptest.NewRequest(http.MethodPatch, "/v1/users/"+userGUID.String()+"/image", nil))
+		decodeProblem(t, rr, http.StatusMethodNotAllowed, problem.MethodNotAllowed)
+		assert.Contains(t, rr.Header().Values("Allow"), http.MethodGet)
+	})
+
+	t.Run("Unsupported content type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/image", strings.NewReader("<xml/>"))
+		req.Header.Set("Content-Type", "text/xml")
+		req.Header.Set("Authorization", "Bearer "+token)
+		decodeProblem(t, send(req), http.StatusUnsupportedMediaType, problem.InvalidContentType)
+	})
+
+	t.Run("Field errors", func(t *testing.T) {
+		body, contentType := newMultipartBody(t, map[string]string{"crop": "500, 0, 400, 400"}, []byte("mock-multipart-image-data"))
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/image", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		details := decodeProblem(t, send(req), http.StatusBadRequest, problem.InvalidUploadOptions)
+		require.Len(t, details.Errors, 1)
+		assert.Equal(t, "crop", details.Errors[0].Field)
+		assert.NotEmpty(t, details.Errors[0].Detail)
+	})
+}
+
+func TestProblemResponses_Panic(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.Algorithm = "HS256"
+	cfg.Idempotency.TTL = time.Hour
+
+	r := NewRouter(zap.NewNop().Sugar(), cfg, newTestImageService(t), idempotency.NewMemoryStore(), newTestWebhooks())
+	r.router.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("handler bug")
+	})
+
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	details := decodeProblem(t, rr, http.StatusInternalServerError, problem.InternalError)
+	assert.NotContains(t, details.Detail, "handler bug")
+}
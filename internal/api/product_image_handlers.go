@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ProductImageResponse represents a single image in a product gallery
+type ProductImageResponse struct {
+	ProductGUID uuid.UUID `json:"productGuid"`
+	ImageGUID   uuid.UUID `json:"imageGuid"`
+	Position    int       `json:"position"`
+	IsPrimary   bool      `json:"isPrimary"`
+	SmallURL    string    `json:"smallUrl"`
+	MediumURL   string    `json:"mediumUrl"`
+	LargeURL    string    `json:"largeUrl"`
+	AltText     string    `json:"altText,omitempty"`
+	UpdatedAt   string    `json:"updatedAt"`
+}
+
+// ProductImageListResponse represents a product gallery
+type ProductImageListResponse struct {
+	ProductGUID uuid.UUID              `json:"productGuid"`
+	Images      []ProductImageResponse `json:"images"`
+}
+
+// ReorderProductImagesRequest is the request body for reordering a gallery
+type ReorderProductImagesRequest struct {
+	ImageGUIDs []uuid.UUID `json:"imageGuids"`
+}
+
+// ProductImageHandlers contains handlers for product gallery endpoints
+type ProductImageHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewProductImageHandlers creates a new set of product image handlers
+func NewProductImageHandlers(imageService *service.ImageService) *ProductImageHandlers {
+	return &ProductImageHandlers{
+		imageService: imageService,
+	}
+}
+
+// AddProductImage handles POST /v1/products/{productGuid}/images
+func (h *ProductImageHandlers) AddProductImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productGUID, ok := parseGUIDParam(w, r, "productGuid", problem.InvalidProductID, "Product")
+		if !ok {
+			return
+		}
+
+		// Enforce the product type's ownership rule
+		if !h.authorizeProductWrite(w, r, productGUID) {
+			return
+		}
+
+		// Read the image from a raw or multipart body
+		imageData, opts, ok := readImageUpload(w, r, h.imageService, service.ProductImageType)
+		if !ok {
+			return
+		}
+
+		// Process and store the image
+		if prefersAsync(r) {
+			submitUploadJob(w, r, h.imageService, service.ProductImageType, productGUID, imageData, opts)
+			return
+		}
+		productImage, err := h.imageService.AddProductImage(r.Context(), productGUID, imageData, opts)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, toProductImageResponse(productImage))
+	}
+}
+
+// ListProductImages handles GET /v1/products/{productGuid}/images
+func (h *ProductImageHandlers) ListProductImages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productGUID, ok := parseGUIDParam(w, r, "productGuid", problem.InvalidProductID, "Product")
+		if !ok {
+			return
+		}
+
+		images, err := h.imageService.ListProductImages(r.Context(), productGUID)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to list product images")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toProductImageListResponse(productGUID, images))
+	}
+}
+
+// ReorderProductImages handles PUT /v1/products/{productGuid}/images/order
+func (h *ProductImageHandlers) ReorderProductImages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productGUID, ok := parseGUIDParam(w, r, "productGuid", problem.InvalidProductID, "Product")
+		if !ok {
+			return
+		}
+
+		// Enforce the product type's ownership rule
+		if !h.authorizeProductWrite(w, r, productGUID) {
+			return
+		}
+
+		var req ReorderProductImagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidRequest, "Request body must be JSON with an imageGuids array")
+			return
+		}
+
+		images, err := h.imageService.ReorderProductImages(r.Context(), productGUID, req.ImageGUIDs)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toProductImageListResponse(productGUID, images))
+	}
+}
+
+// SetPrimaryProductImage handles PUT /v1/products/{productGuid}/images/{imageGuid}/primary
+func (h *ProductImageHandlers) SetPrimaryProductImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productGUID, ok := parseGUIDParam(w, r, "productGuid", problem.InvalidProductID, "Product")
+		if !ok {
+			return
+		}
+
+		// Enforce the product type's ownership rule
+		if !h.authorizeProductWrite(w, r, productGUID) {
+			return
+		}
+		imageGUID, ok := parseGUIDParam(w, r, "imageGuid", problem.InvalidImageID, "Image")
+		if !ok {
+			return
+		}
+
+		productImage, err := h.imageService.SetPrimaryProductImage(r.Context(), productGUID, imageGUID)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toProductImageResponse(productImage))
+	}
+}
+
+// DeleteProductImage handles DELETE /v1/products/{productGuid}/images/{imageGuid}
+func (h *ProductImageHandlers) DeleteProductImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productGUID, ok := parseGUIDParam(w, r, "productGuid", problem.InvalidProductID, "Product")
+		if !ok {
+			return
+		}
+
+		// Enforce the product type's ownership rule
+		if !h.authorizeProductWrite(w, r, productGUID) {
+			return
+		}
+		imageGUID, ok := parseGUIDParam(w, r, "imageGuid", problem.InvalidImageID, "Image")
+		if !ok {
+			return
+		}
+
+		err := h.imageService.DeleteProductImage(r.Context(), productGUID, imageGUID)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "Product has no such image")
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to delete product image")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{
+			"message": "Image deleted successfully",
+		})
+	}
+}
+
+// authorizeProductWrite checks the authenticated caller against the product
+// image type's ownership rule, writing an error response if denied
+func (h *ProductImageHandlers) authorizeProductWrite(w http.ResponseWriter, r *http.Request, productGUID uuid.UUID) bool {
+	imageType, err := h.imageService.ImageType(service.ProductImageType)
+	if err != nil {
+		handleImageServiceError(w, r, err)
+		return false
+	}
+	return authorizeOwnerWrite(w, r, imageType, productGUID)
+}
+
+// toProductImageResponse converts a product image view to its response format
+func toProductImageResponse(image *domain.ProductImage) ProductImageResponse {
+	return ProductImageResponse{
+		ProductGUID: image.ProductGUID,
+		ImageGUID:   image.ImageGUID,
+		Position:    image.Position,
+		IsPrimary:   image.IsPrimary,
+		SmallURL:    image.SmallURL,
+		MediumURL:   image.MediumURL,
+		LargeURL:    image.LargeURL,
+		AltText:     image.AltText,
+		UpdatedAt:   image.UpdatedAt.Format(http.TimeFormat),
+	}
+}
+
+// toProductImageListResponse converts a gallery to its response format
+func toProductImageListResponse(productGUID uuid.UUID, images []*domain.ProductImage) ProductImageListResponse {
+	response := ProductImageListResponse{
+		ProductGUID: productGUID,
+		Images:      make([]ProductImageResponse, 0, len(images)),
+	}
+	for _, image := range images {
+		response.Images = append(response.Images, toProductImageResponse(image))
+	}
+	return response
+}
+
+// parseGUIDParam extracts a UUID URL parameter, writing an error response if it is missing or malformed
+func parseGUIDParam(w http.ResponseWriter, r *http.Request, param string, code problem.Code, label string) (uuid.UUID, bool) {
+	value := chi.URLParam(r, param)
+	if value == "" {
+		writeError(w, r, http.StatusBadRequest, problem.BadRequest, label+" GUID is required")
+		return uuid.Nil, false
+	}
+
+	guid, err := uuid.Parse(value)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, code, label+" ID is not a valid UUID")
+		return uuid.Nil, false
+	}
+
+	return guid, true
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newProductTestRouter creates the full API router around an image service
+// whose product galleries follow the given ownership rule
+func newProductTestRouter(ownership string, maxImages int) http.Handler {
+	imageConfig := &domain.ImageConfig{
+		Types: []domain.ImageType{
+			{
+				Name:        service.ProductImageType,
+				Cardinality: domain.CardinalityMultiple,
+				MaxImages:   maxImages,
+				Ownership:   ownership,
+				Sizes: domain.SizeSet{
+					"small":  {Width: 200, Height: 0},
+					"medium": {Width: 600, Height: 0},
+					"large":  {Width: 1200, Height: 0},
+				},
+			},
+		},
+	}
+
+	return newTestRouterWithService(service.NewImageService(
+		repository.NewMockImageRepository(),
+		storage.NewMockS3(),
+		processor.NewMockProcessor(),
+		imageConfig,
+		zap.NewNop().Sugar(),
+	))
+}
+
+// addTestProductImage uploads an image to a product gallery through the router
+func addTestProductImage(t *testing.T, router http.Handler, productGUID uuid.UUID, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/products/"+productGUID.String()+"/images", bytes.NewReader([]byte("mock-product-image-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestProductImageRoutes_Ownership(t *testing.T) {
+	userGUID := uuid.New()
+	otherGUID := uuid.New()
+	imageGUID := uuid.New()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{
+			name:   "Add",
+			method: http.MethodPost,
+			path:   "/v1/products/" + otherGUID.String() + "/images",
+			body:   "mock-product-image-data",
+		},
+		{
+			name:   "Reorder",
+			method: http.MethodPut,
+			path:   "/v1/products/" + otherGUID.String() + "/images/order",
+			body:   `{"imageGuids":["` + imageGUID.String() + `"]}`,
+		},
+		{
+			name:   "Set primary",
+			method: http.MethodPut,
+			path:   "/v1/products/" + otherGUID.String() + "/images/" + imageGUID.String() + "/primary",
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			path:   "/v1/products/" + otherGUID.String() + "/images/" + imageGUID.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Self ownership lets a caller write only their own product's gallery
+			router := newProductTestRouter(domain.OwnershipSelf, 0)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "image/jpeg")
+			req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+		})
+	}
+}
+
+func TestProductImageRoutes_Gallery(t *testing.T) {
+	router := newProductTestRouter(domain.OwnershipSelf, 0)
+	productGUID := uuid.New()
+	token := newTestToken(t, productGUID.String())
+	path := "/v1/products/" + productGUID.String() + "/images"
+
+	// Add two images; the first becomes primary
+	var added []ProductImageResponse
+	for i := 0; i < 2; i++ {
+		rr := addTestProductImage(t, router, productGUID, token)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var image ProductImageResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &image))
+		assert.Equal(t, i, image.Position)
+		assert.Equal(t, i == 0, image.IsPrimary)
+		added = append(added, image)
+	}
+
+	// Set the second as primary
+	req := httptest.NewRequest(http.MethodPut, path+"/"+added[1].ImageGUID.String()+"/primary", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Reorder
+	order := `{"imageGuids":["` + added[1].ImageGUID.String() + `","` + added[0].ImageGUID.String() + `"]}`
+	req = httptest.NewRequest(http.MethodPut, path+"/order", strings.NewReader(order))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var gallery ProductImageListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &gallery))
+	require.Len(t, gallery.Images, 2)
+	assert.Equal(t, added[1].ImageGUID, gallery.Images[0].ImageGUID)
+	assert.True(t, gallery.Images[0].IsPrimary)
+
+	// Delete
+	req = httptest.NewRequest(http.MethodDelete, path+"/"+added[0].ImageGUID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Public list
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &gallery))
+	require.Len(t, gallery.Images, 1)
+	assert.Equal(t, added[1].ImageGUID, gallery.Images[0].ImageGUID)
+}
+
+func TestAddProductImage_Concurrent(t *testing.T) {
+	const maxImages = 3
+	router := newProductTestRouter(domain.OwnershipAuthenticated, maxImages)
+	productGUID := uuid.New()
+	token := newTestToken(t, uuid.New().String())
+
+	// Concurrent uploads get distinct positions, one primary image and no
+	// more than the limit between them
+	var wg sync.WaitGroup
+	codes := make([]int, 2*maxImages)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = addTestProductImage(t, router, productGUID, token).Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			created++
+			continue
+		}
+		assert.Equal(t, http.StatusConflict, code)
+	}
+	assert.Equal(t, maxImages, created)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/products/"+productGUID.String()+"/images", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var gallery ProductImageListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &gallery))
+	require.Len(t, gallery.Images, maxImages)
+	primaries := 0
+	for position, image := range gallery.Images {
+		assert.Equal(t, position, image.Position)
+		if image.IsPrimary {
+			primaries++
+		}
+	}
+	assert.Equal(t, 1, primaries)
+}
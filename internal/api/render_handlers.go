@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/render"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// renditionCacheControl lets clients and CDNs keep renditions forever: a
+// rendition URL always produces the same bytes
+const renditionCacheControl = "public, max-age=31536000, immutable"
+
+// RenderHandlers serves signed on-the-fly renditions of stored images
+type RenderHandlers struct {
+	imageService *service.ImageService
+	signer       *render.Signer
+}
+
+// NewRenderHandlers creates a new set of render handlers
+func NewRenderHandlers(imageService *service.ImageService, signer *render.Signer) *RenderHandlers {
+	return &RenderHandlers{
+		imageService: imageService,
+		signer:       signer,
+	}
+}
+
+// RenderImage handles GET /v1/render/{signature}/{opts}/{imageGuid}
+func (h *RenderHandlers) RenderImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		imageGUID, ok := parseGUIDParam(w, r, "imageGuid", problem.InvalidImageID, "Image")
+		if !ok {
+			return
+		}
+
+		// Check the signature before doing any work
+		options := chi.URLParam(r, "opts")
+		if !h.signer.Verify(chi.URLParam(r, "signature"), options, imageGUID) {
+			writeError(w, r, http.StatusForbidden, problem.InvalidSignature, "Rendition URL signature is invalid")
+			return
+		}
+
+		opts, err := render.ParseOptions(options)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidRenderOptions, err.Error())
+			return
+		}
+
+		data, contentType, err := h.imageService.RenderImage(r.Context(), imageGUID, opts)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Cache-Control", renditionCacheControl)
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(data); err != nil {
+			// The status code has already been sent, so there's nothing left to report
+			return
+		}
+	}
+}
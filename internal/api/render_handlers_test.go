@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/render"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderImage(t *testing.T) {
+	router := newTestRouter(t)
+	signer := render.NewSigner([]byte(testRenderKey))
+	userGUID := uuid.New()
+
+	// Upload an image to render
+	req := httptest.NewRequest(http.MethodPut, "/v1/images/user/"+userGUID.String(), bytes.NewReader([]byte("mock-render-source-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var uploaded ImageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+
+	opts := domain.RenderOptions{Width: 300, Height: 200, Fit: domain.FitCover, Format: domain.FormatPNG, Quality: 80}
+	path := signer.Path(opts, uploaded.ImageGUID)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "Signed",
+			path:       path,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Tampered options",
+			path:       strings.Replace(path, "w:300", "w:3000", 1),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Another image",
+			path:       strings.Replace(path, uploaded.ImageGUID.String(), uuid.New().String(), 1),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Signed but invalid options",
+			path:       render.PathPrefix + "/" + signer.Sign("w:0", uploaded.ImageGUID) + "/w:0/" + uploaded.ImageGUID.String(),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown image",
+			path:       signer.Path(opts, uuid.Nil),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+				assert.Equal(t, renditionCacheControl, rr.Header().Get("Cache-Control"))
+				assert.NotEmpty(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
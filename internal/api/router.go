@@ -2,11 +2,13 @@ package api
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/antonrybalko/image-service-go/internal/auth"
 	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/antonrybalko/image-service-go/internal/render"
 	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
@@ -14,37 +16,40 @@ import (
 
 // Router holds the HTTP router and its dependencies
 type Router struct {
-	router       *chi.Mux
-	logger       *zap.SugaredLogger
-	config       *config.Config
-	imageService *service.ImageService
-}
-
-// ErrorResponse represents a standard error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Code    int    `json:"code"`
+	router           *chi.Mux
+	logger           *zap.SugaredLogger
+	config           *config.Config
+	imageService     *service.ImageService
+	idempotencyStore idempotency.Store
+	webhooks         *webhook.Dispatcher
 }
 
 // NewRouter creates and configures a new router
-func NewRouter(logger *zap.SugaredLogger, cfg *config.Config, imageService *service.ImageService) *Router {
+func NewRouter(logger *zap.SugaredLogger, cfg *config.Config, imageService *service.ImageService, idempotencyStore idempotency.Store, webhooks *webhook.Dispatcher) *Router {
 	r := &Router{
-		router:       chi.NewRouter(),
-		logger:       logger,
-		config:       cfg,
-		imageService: imageService,
+		router:           chi.NewRouter(),
+		logger:           logger,
+		config:           cfg,
+		imageService:     imageService,
+		idempotencyStore: idempotencyStore,
+		webhooks:         webhooks,
 	}
 
 	// Set up common middleware
 	r.router.Use(middleware.RequestID)
 	r.router.Use(middleware.RealIP)
 	r.router.Use(middleware.Logger)
-	r.router.Use(middleware.Recoverer)
-	r.router.Use(middleware.Timeout(60 * time.Second))
-	r.router.Use(middleware.AllowContentType("application/json", "image/jpeg", "image/png"))
+	r.router.Use(r.recoverer)
+	if cfg.HTTP.RequestTimeout > 0 {
+		r.router.Use(timeout(cfg.HTTP.RequestTimeout))
+	}
+	r.router.Use(allowContentType("application/json", "image/jpeg", "image/png", "multipart/form-data", tusContentType))
 	r.router.Use(middleware.SetHeader("Content-Type", "application/json"))
 
+	// Every error, including unmatched routes, is answered with problem details
+	r.router.NotFound(notFound)
+	r.router.MethodNotAllowed(r.methodNotAllowed)
+
 	// Set up routes
 	r.setupRoutes()
 
@@ -61,6 +66,36 @@ func (r *Router) setupRoutes() {
 	// Create user image handlers
 	userImageHandlers := NewUserImageHandlers(r.imageService)
 
+	// Create organization image handlers
+	orgImageHandlers := NewOrganizationImageHandlers(r.imageService)
+
+	// Create product gallery handlers
+	productImageHandlers := NewProductImageHandlers(r.imageService)
+
+	// Create generic handlers for the configured image types
+	imageHandlers := NewImageHandlers(r.imageService)
+
+	// Create resumable upload handlers
+	tusHandlers := NewTusHandlers(r.imageService)
+
+	// Create file serving handlers
+	fileHandlers := NewFileHandlers(r.imageService)
+
+	// Create placeholder handlers
+	placeholderHandlers := NewPlaceholderHandlers(r.imageService)
+
+	// Create batch lookup handlers
+	batchHandlers := NewBatchHandlers(r.imageService)
+
+	// Create admin handlers
+	adminHandlers := NewAdminHandlers(r.imageService)
+
+	// Create background job handlers
+	jobHandlers := NewJobHandlers(r.imageService)
+
+	// Create webhook delivery handlers
+	webhookHandlers := NewWebhookHandlers(r.webhooks)
+
 	// Public health check endpoint
 	r.router.Get("/health", HealthHandler())
 
@@ -68,18 +103,86 @@ func (r *Router) setupRoutes() {
 	r.router.Route("/v1", func(v1 chi.Router) {
 		// Public routes
 		v1.Get("/users/{userGuid}/image", userImageHandlers.GetUserImage())
+		v1.Get("/users/{userGuid}/image/{size}", userImageHandlers.RedirectUserImage())
+		v1.Get("/organizations/{orgGuid}/image", orgImageHandlers.GetOrganizationImage())
+		v1.Get("/organizations/{orgGuid}/image/{size}", orgImageHandlers.RedirectOrganizationImage())
+		v1.Get("/products/{productGuid}/images", productImageHandlers.ListProductImages())
+		for _, imageType := range r.imageService.ImageTypes() {
+			v1.Get("/images/"+imageType.Name+"/{ownerGuid}", imageHandlers.GetImage(imageType))
+			v1.Get("/images/"+imageType.Name+"/{ownerGuid}/{size}", imageHandlers.RedirectImage(imageType))
+			if imageType.Placeholder {
+				v1.Get("/placeholders/"+imageType.Name+"/{ownerGuid}/{size}", placeholderHandlers.ServePlaceholder(imageType))
+			}
+		}
+		v1.Post("/images:batchGet", batchHandlers.BatchGetImages())
+		v1.Options("/uploads", tusHandlers.Options())
+		v1.Get("/files/*", fileHandlers.ServeFile())
+		v1.Head("/files/*", fileHandlers.ServeFile())
+
+		// Signed renditions; disabled unless a signing key is configured
+		if r.config.Render.SigningKey != "" {
+			renderHandlers := NewRenderHandlers(r.imageService, render.NewSigner([]byte(r.config.Render.SigningKey)))
+			v1.Get("/render/{signature}/{opts}/{imageGuid}", renderHandlers.RenderImage())
+		}
 
 		// Protected routes - require authentication
 		v1.Group(func(auth chi.Router) {
 			// Apply JWT middleware to all routes in this group
 			auth.Use(r.jwtAuth())
 
+			// Replay stored responses for retried writes carrying an Idempotency-Key
+			auth.Use(r.idempotent())
+
 			// Current user routes
 			auth.Route("/me", func(me chi.Router) {
 				me.Put("/image", userImageHandlers.UploadUserImage())
 				me.Get("/image", userImageHandlers.GetCurrentUserImage())
 				me.Delete("/image", userImageHandlers.DeleteUserImage())
 			})
+
+			// Organization routes - caller must be an admin of the organization
+			auth.Put("/organizations/{orgGuid}/image", orgImageHandlers.UploadOrganizationImage())
+			auth.Delete("/organizations/{orgGuid}/image", orgImageHandlers.DeleteOrganizationImage())
+
+			// Product gallery routes
+			auth.Post("/products/{productGuid}/images", productImageHandlers.AddProductImage())
+			auth.Put("/products/{productGuid}/images/order", productImageHandlers.ReorderProductImages())
+			auth.Put("/products/{productGuid}/images/{imageGuid}/primary", productImageHandlers.SetPrimaryProductImage())
+			auth.Delete("/products/{productGuid}/images/{imageGuid}", productImageHandlers.DeleteProductImage())
+
+			// Generic routes for every configured image type; ownership rules are enforced per type
+			for _, imageType := range r.imageService.ImageTypes() {
+				path := "/images/" + imageType.Name + "/{ownerGuid}"
+				if imageType.IsCollection() {
+					auth.Post(path, imageHandlers.UploadImage(imageType))
+				} else {
+					auth.Put(path, imageHandlers.UploadImage(imageType))
+				}
+				auth.Delete(path, imageHandlers.DeleteImage(imageType))
+
+				// Direct-to-storage uploads
+				auth.Post(path+"/uploads", imageHandlers.CreateUploadTicket(imageType))
+				auth.Post(path+"/uploads/{uploadGuid}/finalize", imageHandlers.FinalizeUpload(imageType))
+			}
+
+			// Resumable uploads (tus 1.0)
+			auth.Post("/uploads", tusHandlers.CreateUpload())
+			auth.Head("/uploads/{uploadGuid}", tusHandlers.GetUploadOffset())
+			auth.Patch("/uploads/{uploadGuid}", tusHandlers.AppendUpload())
+			auth.Delete("/uploads/{uploadGuid}", tusHandlers.TerminateUpload())
+
+			// Uploads submitted with Prefer: respond-async
+			auth.Get("/jobs/{jobGuid}", jobHandlers.GetJob())
+
+			// Admin routes - caller must have the service admin role
+			auth.Route("/admin", func(admin chi.Router) {
+				admin.Use(adminHandlers.RequireAdmin)
+				admin.Get("/images", adminHandlers.ListImages())
+				admin.Post("/images/{typeName}/reprocess", adminHandlers.ReprocessImages())
+				admin.Get("/webhooks/deliveries", webhookHandlers.ListDeliveries())
+				admin.Get("/webhooks/deliveries/{deliveryGuid}", webhookHandlers.GetDelivery())
+				admin.Post("/webhooks/deliveries/{deliveryGuid}/redeliver", webhookHandlers.Redeliver())
+			})
 		})
 	})
 }
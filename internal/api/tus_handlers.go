@@ -0,0 +1,271 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/google/uuid"
+)
+
+// tus 1.0 protocol constants
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+)
+
+// Upload-Metadata keys understood by the tus endpoint
+const (
+	tusMetadataType    = "type"    // Image type name; defaults to tusDefaultImageType
+	tusMetadataOwner   = "owner"   // Owner GUID; defaults to the authenticated user
+	tusMetadataAltText = "altText" // Optional alternative text
+)
+
+// tusDefaultImageType is the image type of uploads that do not name one
+const tusDefaultImageType = service.UserImageType
+
+// TusHandlers implements a tus 1.0 resumable upload endpoint at /v1/uploads.
+// Uploads are scoped to the authenticated user and, once complete, are
+// processed like any other upload of their image type.
+type TusHandlers struct {
+	imageService *service.ImageService
+}
+
+// NewTusHandlers creates a new set of tus handlers
+func NewTusHandlers(imageService *service.ImageService) *TusHandlers {
+	return &TusHandlers{
+		imageService: imageService,
+	}
+}
+
+// Options handles OPTIONS /v1/uploads and advertises the supported protocol
+func (h *TusHandlers) Options() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Tus-Resumable", tusVersion)
+		w.Header().Set("Tus-Version", tusVersion)
+		w.Header().Set("Tus-Extension", tusExtensions)
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.imageService.MaxUploadSize(), 10))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// CreateUpload handles POST /v1/uploads (creation extension)
+func (h *TusHandlers) CreateUpload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkTusResumable(w, r) {
+			return
+		}
+
+		userID, ok := auth.GetUserIDFromContext(r.Context())
+		if !ok {
+			writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+			return
+		}
+
+		length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		if err != nil || length < 0 {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidUploadLength, "Upload-Length must be a non-negative integer")
+			return
+		}
+
+		metadata, err := parseTusMetadata(r.Header.Get("Upload-Metadata"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidUploadMetadata, "Upload-Metadata is malformed")
+			return
+		}
+
+		typeName := metadata[tusMetadataType]
+		if typeName == "" {
+			typeName = tusDefaultImageType
+		}
+		imageType, err := h.imageService.ImageType(typeName)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		owner := metadata[tusMetadataOwner]
+		if owner == "" {
+			owner = userID
+		}
+		ownerGUID, err := uuid.Parse(owner)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidOwnerID, "Invalid owner ID format")
+			return
+		}
+
+		// Enforce the type's ownership rule up front rather than after the data is sent
+		if !authorizeOwnerWrite(w, r, imageType, ownerGUID) {
+			return
+		}
+
+		upload, err := h.imageService.CreateResumableUpload(r.Context(), userID, typeName, ownerGUID, length, strings.TrimSpace(metadata[tusMetadataAltText]))
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Location", "/v1/uploads/"+upload.GUID.String())
+		w.Header().Set("Upload-Expires", upload.ExpiresAt.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// GetUploadOffset handles HEAD /v1/uploads/{uploadGuid}
+func (h *TusHandlers) GetUploadOffset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkTusResumable(w, r) {
+			return
+		}
+
+		userID, uploadGUID, ok := tusUploadRequest(w, r)
+		if !ok {
+			return
+		}
+
+		upload, err := h.imageService.GetResumableUpload(r.Context(), userID, uploadGUID)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+		writeTusUploadHeaders(w, upload)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// AppendUpload handles PATCH /v1/uploads/{uploadGuid}
+func (h *TusHandlers) AppendUpload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkTusResumable(w, r) {
+			return
+		}
+
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != tusContentType {
+			writeError(w, r, http.StatusUnsupportedMediaType, problem.InvalidContentType, "Content-Type must be "+tusContentType)
+			return
+		}
+
+		userID, uploadGUID, ok := tusUploadRequest(w, r)
+		if !ok {
+			return
+		}
+
+		offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+		if err != nil || offset < 0 {
+			writeError(w, r, http.StatusBadRequest, problem.InvalidUploadOffset, "Upload-Offset must be a non-negative integer")
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				_ = err // Acknowledge the error to satisfy linter
+			}
+		}()
+
+		upload, _, err := h.imageService.AppendResumableUpload(r.Context(), userID, uploadGUID, offset, r.Body)
+		if err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		writeTusUploadHeaders(w, upload)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// TerminateUpload handles DELETE /v1/uploads/{uploadGuid} (termination extension)
+func (h *TusHandlers) TerminateUpload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkTusResumable(w, r) {
+			return
+		}
+
+		userID, uploadGUID, ok := tusUploadRequest(w, r)
+		if !ok {
+			return
+		}
+
+		if err := h.imageService.TerminateResumableUpload(r.Context(), userID, uploadGUID); err != nil {
+			handleImageServiceError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// checkTusResumable sets the Tus-Resumable response header and rejects
+// requests for a protocol version other than the one supported
+func checkTusResumable(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		writeError(w, r, http.StatusPreconditionFailed, problem.UnsupportedVersion, "Tus-Resumable must be "+tusVersion)
+		return false
+	}
+	return true
+}
+
+// tusUploadRequest extracts the authenticated user and the upload GUID of a request
+func tusUploadRequest(w http.ResponseWriter, r *http.Request) (string, uuid.UUID, bool) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
+		return "", uuid.Nil, false
+	}
+
+	uploadGUID, ok := parseGUIDParam(w, r, "uploadGuid", problem.InvalidUploadID, "Upload")
+	if !ok {
+		return "", uuid.Nil, false
+	}
+
+	return userID, uploadGUID, true
+}
+
+// writeTusUploadHeaders writes the headers describing the current state of an upload.
+// Image-Guid tells the client which image a completed upload produced.
+func writeTusUploadHeaders(w http.ResponseWriter, upload *domain.ResumableUpload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.Format(http.TimeFormat))
+	if upload.ImageGUID != nil {
+		w.Header().Set("Image-Guid", upload.ImageGUID.String())
+	}
+}
+
+// parseTusMetadata parses an Upload-Metadata header: comma-separated pairs of a
+// key and an optional base64-encoded value
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		switch len(fields) {
+		case 1:
+			metadata[fields[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			metadata[fields[0]] = string(value)
+		default:
+			return nil, errors.New("metadata pair must be a key and an optional value")
+		}
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTusUpload(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+	imageData := []byte("mock-resumable-image-data")
+
+	newRequest := func(method, path string, body []byte) *http.Request {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Tus-Resumable", tusVersion)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", tusContentType)
+		}
+		return req
+	}
+
+	// Discovery is public
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/v1/uploads", nil))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, tusExtensions, rr.Header().Get("Tus-Extension"))
+
+	// Create
+	req := newRequest(http.MethodPost, "/v1/uploads", nil)
+	req.Header.Set("Upload-Length", strconv.Itoa(len(imageData)))
+	req.Header.Set("Upload-Metadata", "type "+base64.StdEncoding.EncodeToString([]byte("user"))+",altText "+base64.StdEncoding.EncodeToString([]byte("Me")))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	location := rr.Header().Get("Location")
+	require.NotEmpty(t, location)
+	assert.NotEmpty(t, rr.Header().Get("Upload-Expires"))
+
+	// Another user cannot see it
+	req = newRequest(http.MethodHead, location, nil)
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, uuid.New().String()))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Append the first chunk
+	req = newRequest(http.MethodPatch, location, imageData[:10])
+	req.Header.Set("Upload-Offset", "0")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	assert.Equal(t, "10", rr.Header().Get("Upload-Offset"))
+
+	// Resume from the offset reported by HEAD
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, newRequest(http.MethodHead, location, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get("Upload-Offset"))
+	assert.Equal(t, strconv.Itoa(len(imageData)), rr.Header().Get("Upload-Length"))
+
+	// A stale offset conflicts
+	req = newRequest(http.MethodPatch, location, imageData[:10])
+	req.Header.Set("Upload-Offset", "0")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// The last chunk completes the upload and processes the image
+	req = newRequest(http.MethodPatch, location, imageData[10:])
+	req.Header.Set("Upload-Offset", "10")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	assert.NotEmpty(t, rr.Header().Get("Image-Guid"))
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/images/user/"+userGUID.String(), nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Terminate
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, newRequest(http.MethodDelete, location, nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestTusUpload_Rejected(t *testing.T) {
+	router := newTestRouter(t)
+	userGUID := uuid.New()
+	token := newTestToken(t, userGUID.String())
+
+	tests := []struct {
+		name       string
+		version    string
+		metadata   string
+		wantStatus int
+	}{
+		{
+			name:       "Unsupported protocol version",
+			version:    "0.2.2",
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "Malformed metadata",
+			version:    tusVersion,
+			metadata:   "type !!!",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Another user's image",
+			version:    tusVersion,
+			metadata:   "owner " + base64.StdEncoding.EncodeToString([]byte(uuid.New().String())),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Unconfigured type",
+			version:    tusVersion,
+			metadata:   "type " + base64.StdEncoding.EncodeToString([]byte("banner")),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/uploads", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Tus-Resumable", tt.version)
+			req.Header.Set("Upload-Length", "100")
+			if tt.metadata != "" {
+				req.Header.Set("Upload-Metadata", tt.metadata)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
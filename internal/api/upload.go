@@ -0,0 +1,245 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+)
+
+// Multipart form field names accepted by the upload endpoints
+const (
+	formFieldImage   = "image"   // The image file part
+	formFieldAltText = "altText" // Optional alternative text
+	formFieldCrop    = "crop"    // Optional crop rectangle as "x,y,width,height"
+)
+
+// maxFormFieldBytes bounds the size of non-file multipart fields
+const maxFormFieldBytes = 4 * 1024
+
+// maxImportRequestBytes bounds the size of a JSON import request body
+const maxImportRequestBytes = 16 * 1024
+
+// maxMultipartOverheadBytes is allowed on top of the image size limit for the
+// boundaries, part headers and option fields of a multipart body
+const maxMultipartOverheadBytes = 64 * 1024
+
+// ImportImageRequest is the JSON body for importing an image from a URL
+type ImportImageRequest struct {
+	SourceURL string `json:"sourceUrl"`
+	AltText   string `json:"altText,omitempty"`
+	Crop      string `json:"crop,omitempty"` // "x,y,width,height", as in multipart uploads
+}
+
+// errUploadTooLarge is returned when the image part exceeds the size limit
+var errUploadTooLarge = errors.New("image exceeds maximum allowed size")
+
+// readImageUpload prepares an uploaded image of the named type and its options
+// from a raw image/jpeg or image/png body, a multipart/form-data body with an
+// "image" file part, or a JSON body whose sourceUrl is fetched server-side.
+// Raw bodies are returned as a stream for the service to read, which enforces
+// the type's size limit while reading; a declared Content-Length over the limit
+// is rejected before anything is read. Multipart bodies are streamed the same
+// way: option fields must come before the image part, which is returned
+// unread, and the whole body is capped. Declared content types are ignored:
+// the service sniffs the real format from the data. On failure an error
+// response is written.
+func readImageUpload(w http.ResponseWriter, r *http.Request, imageService *service.ImageService, typeName string) (io.Reader, domain.UploadOptions, bool) {
+	maxBytes := imageService.MaxImageSizeFor(typeName)
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, problem.InvalidContentType, "Only JPEG and PNG images are supported")
+		return nil, domain.UploadOptions{}, false
+	}
+
+	switch mediaType {
+	case "multipart/form-data":
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes+maxMultipartOverheadBytes)
+		return readMultipartUpload(w, r)
+	case "application/json":
+		return readImportRequest(w, r, imageService)
+	}
+
+	// Check content type
+	if mediaType != "image/jpeg" && mediaType != "image/png" {
+		writeError(w, r, http.StatusBadRequest, problem.InvalidContentType, "Only JPEG and PNG images are supported")
+		return nil, domain.UploadOptions{}, false
+	}
+
+	// Reject a declared length over the limit without reading the body
+	if r.ContentLength > maxBytes {
+		writeUploadReadError(w, r, errUploadTooLarge)
+		return nil, domain.UploadOptions{}, false
+	}
+
+	// Check if image data is empty
+	if r.ContentLength == 0 {
+		writeError(w, r, http.StatusBadRequest, problem.EmptyImage, "Image data is empty")
+		return nil, domain.UploadOptions{}, false
+	}
+
+	return r.Body, domain.UploadOptions{}, true
+}
+
+// readMultipartUpload streams a multipart/form-data body, collecting the option
+// fields up to the image part and returning that part as a stream. Parts after
+// the image are never read, so fields there have no effect.
+func readMultipartUpload(w http.ResponseWriter, r *http.Request) (io.Reader, domain.UploadOptions, bool) {
+	var opts domain.UploadOptions
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, problem.InvalidMultipart, "Malformed multipart body")
+		return nil, opts, false
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			writeError(w, r, http.StatusBadRequest, problem.EmptyImage, "Multipart body has no \""+formFieldImage+"\" file part")
+			return nil, opts, false
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeUploadReadError(w, r, err)
+				return nil, opts, false
+			}
+			writeError(w, r, http.StatusBadRequest, problem.InvalidMultipart, "Malformed multipart body")
+			return nil, opts, false
+		}
+
+		switch part.FormName() {
+		case formFieldImage:
+			// The service reads the part, enforcing the size limit as it goes
+			imageData := bufio.NewReader(part)
+			if _, err := imageData.Peek(1); err != nil {
+				if err == io.EOF {
+					writeError(w, r, http.StatusBadRequest, problem.EmptyImage, "Image data is empty")
+				} else {
+					writeUploadReadError(w, r, err)
+				}
+				return nil, opts, false
+			}
+			return imageData, opts, true
+		case formFieldAltText:
+			value, err := readFormField(part)
+			if err != nil {
+				writeFieldError(w, r, problem.InvalidUploadOptions, "altText", "altText field is too long")
+				return nil, opts, false
+			}
+			opts.AltText = strings.TrimSpace(value)
+		case formFieldCrop:
+			value, err := readFormField(part)
+			if err == nil {
+				opts.Crop, err = parseCrop(value)
+			}
+			if err != nil {
+				writeFieldError(w, r, problem.InvalidUploadOptions, "crop", "crop must be \"x,y,width,height\"")
+				return nil, opts, false
+			}
+		default:
+			// Ignore unknown fields, draining them so the next part can be read
+			if _, err := io.Copy(io.Discard, part); err != nil {
+				writeError(w, r, http.StatusBadRequest, problem.ReadError, "Failed to read multipart body")
+				return nil, opts, false
+			}
+		}
+		_ = part.Close()
+	}
+}
+
+// readImportRequest decodes a JSON import request and fetches the image from its source URL
+func readImportRequest(w http.ResponseWriter, r *http.Request, imageService *service.ImageService) (io.Reader, domain.UploadOptions, bool) {
+	var opts domain.UploadOptions
+
+	var req ImportImageRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxImportRequestBytes)).Decode(&req); err != nil || req.SourceURL == "" {
+		writeError(w, r, http.StatusBadRequest, problem.InvalidRequest, "Request body must be JSON with a sourceUrl")
+		return nil, opts, false
+	}
+
+	crop, err := parseCrop(req.Crop)
+	if err != nil {
+		writeFieldError(w, r, problem.InvalidUploadOptions, "crop", "crop must be \"x,y,width,height\"")
+		return nil, opts, false
+	}
+	opts.AltText = strings.TrimSpace(req.AltText)
+	opts.Crop = crop
+
+	imageData, err := imageService.FetchRemoteImage(r.Context(), req.SourceURL)
+	if err != nil {
+		handleImageServiceError(w, r, err)
+		return nil, opts, false
+	}
+
+	return bytes.NewReader(imageData), opts, true
+}
+
+// readLimited reads at most maxBytes from reader, failing with errUploadTooLarge
+// beyond that. It is meant for small fields; images are streamed instead.
+func readLimited(reader io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, errUploadTooLarge
+	}
+	return data, nil
+}
+
+// readFormField reads a small multipart text field
+func readFormField(part *multipart.Part) (string, error) {
+	data, err := readLimited(part, maxFormFieldBytes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// parseCrop parses a crop rectangle in the form "x,y,width,height"; an empty value means no crop
+func parseCrop(value string) (*domain.Crop, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(value, ",")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("crop needs 4 values, got %d", len(fields))
+	}
+
+	var numbers [4]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid crop value %q: %w", field, err)
+		}
+		numbers[i] = n
+	}
+
+	return &domain.Crop{X: numbers[0], Y: numbers[1], Width: numbers[2], Height: numbers[3]}, nil
+}
+
+// writeUploadReadError writes the response for a failed image body read
+func writeUploadReadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, errUploadTooLarge) || errors.As(err, &maxBytesErr) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, problem.ImageTooLarge, "Image exceeds maximum allowed size")
+		return
+	}
+	writeError(w, r, http.StatusBadRequest, problem.ReadError, "Failed to read image data")
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMultipartBody builds a multipart/form-data body from form fields and an optional image part
+func newMultipartBody(t *testing.T, fields map[string]string, imageData []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	if imageData != nil {
+		// The declared part type is deliberately wrong; the service sniffs the data
+		part, err := writer.CreateFormFile(formFieldImage, "photo.bin")
+		require.NoError(t, err)
+		_, err = part.Write(imageData)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestUpload_Multipart(t *testing.T) {
+	userGUID := uuid.New()
+	path := "/v1/images/user/" + userGUID.String()
+	token := newTestToken(t, userGUID.String())
+
+	tests := []struct {
+		name        string
+		fields      map[string]string
+		imageData   []byte
+		wantStatus  int
+		wantAltText string
+	}{
+		{
+			name:        "Image with alt text and crop",
+			fields:      map[string]string{"altText": "  Profile photo  ", "crop": "10, 20, 300, 300"},
+			imageData:   []byte("mock-multipart-image-data"),
+			wantStatus:  http.StatusOK,
+			wantAltText: "Profile photo",
+		},
+		{
+			name:       "Image only",
+			imageData:  []byte("mock-multipart-image-data"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Missing image part",
+			fields:     map[string]string{"altText": "Profile photo"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Malformed crop",
+			fields:     map[string]string{"crop": "10,20,300"},
+			imageData:  []byte("mock-multipart-image-data"),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Crop outside the image",
+			fields:     map[string]string{"crop": "700,0,200,200"},
+			imageData:  []byte("mock-multipart-image-data"),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+			body, contentType := newMultipartBody(t, tt.fields, tt.imageData)
+
+			req := httptest.NewRequest(http.MethodPut, path, body)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var resp ImageResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantAltText, resp.AltText)
+			}
+		})
+	}
+}
+
+func TestUpload_RawBodyTooLarge(t *testing.T) {
+	imageService := newTestImageService(t)
+	imageService.SetMaxImageSize(16)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(bytes.Repeat([]byte{0xFF}, 17)))
+	req.Header.Set("Content-Type", "image/jpeg")
+	_, _, ok := readImageUpload(rr, req, imageService, "user")
+
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func TestUpload_StreamedBodyTooLarge(t *testing.T) {
+	imageService := newTestImageService(t)
+	imageService.SetMaxImageSize(16)
+	router := newTestRouterWithService(imageService)
+	userGUID := uuid.New()
+
+	// Without a Content-Length the limit is enforced while the body is read
+	req := httptest.NewRequest(http.MethodPut, "/v1/images/user/"+userGUID.String(), io.MultiReader(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 17))))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code, rr.Body.String())
+}
+
+func TestUpload_MultipartTooLarge(t *testing.T) {
+	const maxBytes = 1024
+	imageService := newTestImageService(t)
+	imageService.SetMaxImageSize(maxBytes)
+	router := newTestRouterWithService(imageService)
+	userGUID := uuid.New()
+
+	// Stream a field, then an image part far over the limit, counting what
+	// the server reads of it
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	written := make(chan int64, 1)
+	go func() {
+		var n int64
+		defer func() { written <- n }()
+		if err := writer.WriteField(formFieldAltText, "Huge"); err != nil {
+			return
+		}
+		part, err := writer.CreateFormFile(formFieldImage, "huge.jpg")
+		if err != nil {
+			return
+		}
+		chunk := bytes.Repeat([]byte{0xFF}, maxBytes)
+		for i := 0; i < 100; i++ {
+			if _, err := part.Write(chunk); err != nil {
+				return
+			}
+			n += maxBytes
+		}
+		_ = pw.CloseWithError(writer.Close())
+	}()
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/images/user/"+userGUID.String(), pr)
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	_ = pr.Close()
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code, rr.Body.String())
+	// Reading stopped at the limit, give or take the read buffers
+	assert.Less(t, <-written, int64(16*maxBytes))
+}
+
+func TestParseCrop(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    *domain.Crop
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "0,0,100,50", want: &domain.Crop{X: 0, Y: 0, Width: 100, Height: 50}},
+		{value: " 5 , 6 , 7 , 8 ", want: &domain.Crop{X: 5, Y: 6, Width: 7, Height: 8}},
+		{value: "1,2,3", wantErr: true},
+		{value: "a,b,c,d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			crop, err := parseCrop(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, crop)
+		})
+	}
+}
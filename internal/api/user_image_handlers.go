@@ -3,10 +3,11 @@ package api
 import (
 	"encoding/json"
 	"errors"
-	"io"
 	"net/http"
 
 	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/problem"
 	"github.com/antonrybalko/image-service-go/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -14,12 +15,14 @@ import (
 
 // UserImageResponse represents the response format for user image endpoints
 type UserImageResponse struct {
-	UserGUID  uuid.UUID `json:"userGuid"`
-	ImageGUID uuid.UUID `json:"imageGuid"`
-	SmallURL  string    `json:"smallUrl"`
-	MediumURL string    `json:"mediumUrl"`
-	LargeURL  string    `json:"largeUrl"`
-	UpdatedAt string    `json:"updatedAt"`
+	UserGUID      uuid.UUID `json:"userGuid"`
+	ImageGUID     uuid.UUID `json:"imageGuid"`
+	SmallURL      string    `json:"smallUrl"`
+	MediumURL     string    `json:"mediumUrl"`
+	LargeURL      string    `json:"largeUrl"`
+	AltText       string    `json:"altText,omitempty"`
+	UpdatedAt     string    `json:"updatedAt,omitempty"`     // Empty for placeholders
+	IsPlaceholder bool      `json:"isPlaceholder,omitempty"` // Generated stand-in, the user has no image
 }
 
 // UserImageHandlers contains handlers for user image endpoints
@@ -40,50 +43,35 @@ func (h *UserImageHandlers) UploadUserImage() http.HandlerFunc {
 		// Get user ID from context (set by JWT middleware)
 		userIDStr, ok := auth.GetUserIDFromContext(r.Context())
 		if !ok {
-			writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid or missing authentication")
+			writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
 			return
 		}
 
 		// Parse user ID
 		userGUID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			writeError(w, http.StatusUnauthorized, "InvalidUserID", "User ID is not a valid UUID")
+			writeError(w, r, http.StatusUnauthorized, problem.InvalidUserID, "User ID is not a valid UUID")
 			return
 		}
 
-		// Check content type
-		contentType := r.Header.Get("Content-Type")
-		if contentType != "image/jpeg" && contentType != "image/png" {
-			writeError(w, http.StatusBadRequest, "InvalidContentType", "Only JPEG and PNG images are supported")
+		// Read the image from a raw or multipart body
+		imageData, opts, ok := readImageUpload(w, r, h.imageService, service.UserImageType)
+		if !ok {
 			return
 		}
 
-		// Read image data
-		imageData, err := io.ReadAll(r.Body)
-		if err != nil {
-			writeError(w, http.StatusBadRequest, "ReadError", "Failed to read image data")
+		// Process and store the image, provided the caller's current image matches any precondition
+		opts.Precondition = parsePrecondition(r)
+		if prefersAsync(r) {
+			submitUploadJob(w, r, h.imageService, service.UserImageType, userGUID, imageData, opts)
 			return
 		}
-		defer func() {
-			if err := r.Body.Close(); err != nil {
-				// Log the error but don't fail the request since we've already read the body
-				// This is mainly for cleanup
-				_ = err // Acknowledge the error to satisfy linter
-			}
-		}()
-
-		// Check if image data is empty
-		if len(imageData) == 0 {
-			writeError(w, http.StatusBadRequest, "EmptyImage", "Image data is empty")
-			return
-		}
-
-		// Process and store the image
-		userImage, err := h.imageService.UploadUserImage(r.Context(), userGUID, imageData)
+		userImage, err := h.imageService.UploadUserImage(r.Context(), userGUID, imageData, opts)
 		if err != nil {
-			handleImageServiceError(w, err)
+			handleImageServiceError(w, r, err)
 			return
 		}
+		setETag(w, domain.ETag(userImage.ImageGUID, userImage.UpdatedAt))
 
 		// Prepare response
 		response := UserImageResponse{
@@ -92,6 +80,7 @@ func (h *UserImageHandlers) UploadUserImage() http.HandlerFunc {
 			SmallURL:  userImage.SmallURL,
 			MediumURL: userImage.MediumURL,
 			LargeURL:  userImage.LargeURL,
+			AltText:   userImage.AltText,
 			UpdatedAt: userImage.UpdatedAt.Format(http.TimeFormat),
 		}
 
@@ -112,36 +101,40 @@ func (h *UserImageHandlers) GetCurrentUserImage() http.HandlerFunc {
 		// Get user ID from context (set by JWT middleware)
 		userIDStr, ok := auth.GetUserIDFromContext(r.Context())
 		if !ok {
-			writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid or missing authentication")
+			writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
 			return
 		}
 
 		// Parse user ID
 		userGUID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			writeError(w, http.StatusUnauthorized, "InvalidUserID", "User ID is not a valid UUID")
+			writeError(w, r, http.StatusUnauthorized, problem.InvalidUserID, "User ID is not a valid UUID")
 			return
 		}
 
-		// Get the user's image
-		userImage, err := h.imageService.GetUserImage(r.Context(), userGUID)
+		// Get the user's image, or a placeholder if placeholders are enabled
+		image, err := h.imageService.GetImageOrPlaceholder(r.Context(), service.UserImageType, userGUID, r.URL.Query().Get("name"))
 		if err != nil {
 			if errors.Is(err, service.ErrNotFound) {
-				writeError(w, http.StatusNotFound, "ImageNotFound", "User has no image")
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "User has no image")
 				return
 			}
-			writeError(w, http.StatusInternalServerError, "ServiceError", "Failed to retrieve user image")
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to retrieve user image")
 			return
 		}
 
 		// Prepare response
+		setETag(w, image.ETag())
+		userImage := image.ToUserImage()
 		response := UserImageResponse{
-			UserGUID:  userImage.UserGUID,
-			ImageGUID: userImage.ImageGUID,
-			SmallURL:  userImage.SmallURL,
-			MediumURL: userImage.MediumURL,
-			LargeURL:  userImage.LargeURL,
-			UpdatedAt: userImage.UpdatedAt.Format(http.TimeFormat),
+			UserGUID:      userImage.UserGUID,
+			ImageGUID:     userImage.ImageGUID,
+			SmallURL:      userImage.SmallURL,
+			MediumURL:     userImage.MediumURL,
+			LargeURL:      userImage.LargeURL,
+			AltText:       userImage.AltText,
+			UpdatedAt:     formatUpdatedAt(userImage.UpdatedAt),
+			IsPlaceholder: userImage.IsPlaceholder,
 		}
 
 		// Return success response
@@ -161,25 +154,29 @@ func (h *UserImageHandlers) DeleteUserImage() http.HandlerFunc {
 		// Get user ID from context (set by JWT middleware)
 		userIDStr, ok := auth.GetUserIDFromContext(r.Context())
 		if !ok {
-			writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid or missing authentication")
+			writeError(w, r, http.StatusUnauthorized, problem.Unauthorized, "Invalid or missing authentication")
 			return
 		}
 
 		// Parse user ID
 		userGUID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			writeError(w, http.StatusUnauthorized, "InvalidUserID", "User ID is not a valid UUID")
+			writeError(w, r, http.StatusUnauthorized, problem.InvalidUserID, "User ID is not a valid UUID")
 			return
 		}
 
-		// Delete the user's image
-		err = h.imageService.DeleteUserImage(r.Context(), userGUID)
+		// Delete the user's image, provided it matches any precondition
+		err = h.imageService.DeleteImageIf(r.Context(), service.UserImageType, userGUID, parsePrecondition(r))
 		if err != nil {
 			if errors.Is(err, service.ErrNotFound) {
-				writeError(w, http.StatusNotFound, "ImageNotFound", "User has no image to delete")
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "User has no image to delete")
 				return
 			}
-			writeError(w, http.StatusInternalServerError, "ServiceError", "Failed to delete user image")
+			if errors.Is(err, service.ErrPreconditionFailed) || errors.Is(err, service.ErrConcurrentUpdate) {
+				handleImageServiceError(w, r, err)
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to delete user image")
 			return
 		}
 
@@ -202,36 +199,40 @@ func (h *UserImageHandlers) GetUserImage() http.HandlerFunc {
 		// Extract user GUID from URL path
 		userGuidStr := chi.URLParam(r, "userGuid")
 		if userGuidStr == "" {
-			writeError(w, http.StatusBadRequest, "BadRequest", "User GUID is required")
+			writeError(w, r, http.StatusBadRequest, problem.BadRequest, "User GUID is required")
 			return
 		}
 
 		// Parse user GUID
 		userGUID, err := uuid.Parse(userGuidStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "InvalidUserID", "User ID is not a valid UUID")
+			writeError(w, r, http.StatusBadRequest, problem.InvalidUserID, "User ID is not a valid UUID")
 			return
 		}
 
-		// Get the user's image
-		userImage, err := h.imageService.GetUserImage(r.Context(), userGUID)
+		// Get the user's image, or a placeholder if placeholders are enabled
+		image, err := h.imageService.GetImageOrPlaceholder(r.Context(), service.UserImageType, userGUID, r.URL.Query().Get("name"))
 		if err != nil {
 			if errors.Is(err, service.ErrNotFound) {
-				writeError(w, http.StatusNotFound, "ImageNotFound", "User has no image")
+				writeError(w, r, http.StatusNotFound, problem.ImageNotFound, "User has no image")
 				return
 			}
-			writeError(w, http.StatusInternalServerError, "ServiceError", "Failed to retrieve user image")
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to retrieve user image")
 			return
 		}
 
 		// Prepare response
+		setETag(w, image.ETag())
+		userImage := image.ToUserImage()
 		response := UserImageResponse{
-			UserGUID:  userImage.UserGUID,
-			ImageGUID: userImage.ImageGUID,
-			SmallURL:  userImage.SmallURL,
-			MediumURL: userImage.MediumURL,
-			LargeURL:  userImage.LargeURL,
-			UpdatedAt: userImage.UpdatedAt.Format(http.TimeFormat),
+			UserGUID:      userImage.UserGUID,
+			ImageGUID:     userImage.ImageGUID,
+			SmallURL:      userImage.SmallURL,
+			MediumURL:     userImage.MediumURL,
+			LargeURL:      userImage.LargeURL,
+			AltText:       userImage.AltText,
+			UpdatedAt:     formatUpdatedAt(userImage.UpdatedAt),
+			IsPlaceholder: userImage.IsPlaceholder,
 		}
 
 		// Return success response
@@ -245,41 +246,90 @@ func (h *UserImageHandlers) GetUserImage() http.HandlerFunc {
 	}
 }
 
-// Helper functions
+// RedirectUserImage handles GET /v1/users/{userGuid}/image/{size}
+func (h *UserImageHandlers) RedirectUserImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userGUID, ok := parseGUIDParam(w, r, "userGuid", problem.InvalidUserID, "User")
+		if !ok {
+			return
+		}
 
-// writeError writes a standardized error response
-func writeError(w http.ResponseWriter, status int, errType, message string) {
-	resp := ErrorResponse{
-		Error:   errType,
-		Message: message,
-		Code:    status,
+		redirectToImageSize(w, r, h.imageService, service.UserImageType, userGUID)
 	}
+}
 
+// Helper functions
+
+// writeError writes a problem details error response
+func writeError(w http.ResponseWriter, r *http.Request, status int, code problem.Code, message string) {
+	problem.Error(w, r, status, code, message)
+}
+
+// writeFieldError writes a 400 problem naming the invalid request field
+func writeFieldError(w http.ResponseWriter, r *http.Request, code problem.Code, field, message string) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, code, message).
+		WithFields(problem.FieldError{Field: field, Detail: message}))
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		// At this point we've already written the status code, so we can't change it
 		_ = err // Acknowledge the error to satisfy linter
 	}
 }
 
 // handleImageServiceError maps service errors to HTTP responses
-func handleImageServiceError(w http.ResponseWriter, err error) {
+func handleImageServiceError(w http.ResponseWriter, r *http.Request, err error) {
 	switch {
 	case errors.Is(err, service.ErrInvalidImage):
-		writeError(w, http.StatusBadRequest, "InvalidImage", "Invalid image data")
+		writeError(w, r, http.StatusBadRequest, problem.InvalidImage, "Invalid image data")
 	case errors.Is(err, service.ErrImageTooLarge):
-		writeError(w, http.StatusRequestEntityTooLarge, "ImageTooLarge", "Image exceeds maximum allowed size")
+		writeError(w, r, http.StatusRequestEntityTooLarge, problem.ImageTooLarge, "Image exceeds maximum allowed size")
 	case errors.Is(err, service.ErrUnsupportedType):
-		writeError(w, http.StatusUnsupportedMediaType, "UnsupportedType", "Unsupported image format")
+		writeError(w, r, http.StatusUnsupportedMediaType, problem.UnsupportedType, "Unsupported image format")
 	case errors.Is(err, service.ErrProcessingFailed):
-		writeError(w, http.StatusUnprocessableEntity, "ProcessingFailed", "Failed to process image")
+		writeError(w, r, http.StatusUnprocessableEntity, problem.ProcessingFailed, "Failed to process image")
 	case errors.Is(err, service.ErrStorageFailed):
-		writeError(w, http.StatusInternalServerError, "StorageFailed", "Failed to store image")
+		writeError(w, r, http.StatusInternalServerError, problem.StorageFailed, "Failed to store image")
 	case errors.Is(err, service.ErrNotFound):
-		writeError(w, http.StatusNotFound, "NotFound", "Image not found")
+		writeError(w, r, http.StatusNotFound, problem.NotFound, "Image not found")
 	case errors.Is(err, service.ErrUnauthorized):
-		writeError(w, http.StatusForbidden, "Forbidden", "Unauthorized access to image")
+		writeError(w, r, http.StatusForbidden, problem.Forbidden, "Unauthorized access to image")
+	case errors.Is(err, service.ErrImageLimit):
+		writeError(w, r, http.StatusConflict, problem.ImageLimitReached, "Maximum number of images reached")
+	case errors.Is(err, service.ErrInvalidOrder):
+		writeError(w, r, http.StatusBadRequest, problem.InvalidOrder, err.Error())
+	case errors.Is(err, service.ErrInvalidOptions):
+		var fieldErr *service.FieldError
+		if errors.As(err, &fieldErr) {
+			writeFieldError(w, r, problem.InvalidUploadOptions, fieldErr.Field, fieldErr.Message)
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, problem.InvalidUploadOptions, err.Error())
+	case errors.Is(err, service.ErrInvalidSource):
+		writeError(w, r, http.StatusBadRequest, problem.InvalidSourceURL, err.Error())
+	case errors.Is(err, service.ErrSourceFailed):
+		writeError(w, r, http.StatusBadGateway, problem.SourceUnavailable, "Failed to fetch image from source URL")
+	case errors.Is(err, service.ErrUnknownType):
+		writeError(w, r, http.StatusNotFound, problem.UnknownImageType, "Image type is not configured")
+	case errors.Is(err, service.ErrUnknownSize):
+		writeError(w, r, http.StatusNotFound, problem.UnknownImageSize, "Image size is not configured")
+	case errors.Is(err, service.ErrOffsetMismatch):
+		writeError(w, r, http.StatusConflict, problem.OffsetMismatch, "Upload-Offset does not match the current upload offset")
+	case errors.Is(err, service.ErrUploadExpired):
+		writeError(w, r, http.StatusGone, problem.UploadExpired, "Upload has expired")
+	case errors.Is(err, service.ErrBatchTooLarge):
+		writeError(w, r, http.StatusBadRequest, problem.BatchTooLarge, err.Error())
+	case errors.Is(err, service.ErrInvalidCursor):
+		writeError(w, r, http.StatusBadRequest, problem.InvalidCursor, "Page cursor is malformed")
+	case errors.Is(err, service.ErrPreconditionFailed):
+		writeError(w, r, http.StatusPreconditionFailed, problem.PreconditionFailed, "Current image does not match If-Match or If-None-Match")
+	case errors.Is(err, service.ErrConcurrentUpdate):
+		writeError(w, r, http.StatusConflict, problem.ConcurrentUpdate, "Image was modified concurrently, retry the request")
 	default:
-		writeError(w, http.StatusInternalServerError, "InternalError", "An unexpected error occurred")
+		writeError(w, r, http.StatusInternalServerError, problem.InternalError, "An unexpected error occurred")
 	}
 }
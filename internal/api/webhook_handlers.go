@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/webhook"
+	"github.com/google/uuid"
+)
+
+// WebhookDeliveryResponse represents a queued webhook delivery
+type WebhookDeliveryResponse struct {
+	DeliveryGUID   uuid.UUID       `json:"deliveryGuid"`
+	EventGUID      uuid.UUID       `json:"eventGuid"`
+	EventType      string          `json:"eventType"`
+	TypeName       string          `json:"typeName"`
+	Webhook        string          `json:"webhook"`
+	URL            string          `json:"url"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"nextAttemptAt,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	CreatedAt      string          `json:"createdAt"`
+	DeliveredAt    string          `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// ListWebhookDeliveriesResponse represents the deliveries listing
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// WebhookHandlers contains the admin handlers for inspecting and redelivering
+// webhook deliveries
+type WebhookHandlers struct {
+	webhooks *webhook.Dispatcher
+}
+
+// NewWebhookHandlers creates a new set of webhook handlers
+func NewWebhookHandlers(webhooks *webhook.Dispatcher) *WebhookHandlers {
+	return &WebhookHandlers{
+		webhooks: webhooks,
+	}
+}
+
+// ListDeliveries handles GET /v1/admin/webhooks/deliveries
+//
+// Query parameters: status (pending, delivered or dead) and limit.
+func (h *WebhookHandlers) ListDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		status := query.Get("status")
+		switch status {
+		case "", webhook.StatusPending, webhook.StatusDelivered, webhook.StatusDead:
+		default:
+			writeFieldError(w, r, problem.InvalidFilter, "status", "status must be pending, delivered or dead")
+			return
+		}
+
+		limit := service.DefaultListLimit
+		if value := query.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				writeFieldError(w, r, problem.InvalidFilter, "limit", "limit must be a positive integer")
+				return
+			}
+			limit = min(parsed, service.MaxListLimit)
+		}
+
+		deliveries, err := h.webhooks.List(r.Context(), status, limit)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to list webhook deliveries")
+			return
+		}
+
+		response := ListWebhookDeliveriesResponse{
+			Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+		}
+		for _, delivery := range deliveries {
+			response.Deliveries = append(response.Deliveries, toWebhookDeliveryResponse(delivery))
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// GetDelivery handles GET /v1/admin/webhooks/deliveries/{deliveryGuid}
+func (h *WebhookHandlers) GetDelivery() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryGUID, ok := parseGUIDParam(w, r, "deliveryGuid", problem.InvalidDeliveryID, "Delivery")
+		if !ok {
+			return
+		}
+
+		delivery, err := h.webhooks.Get(r.Context(), deliveryGUID)
+		if err != nil {
+			handleWebhookStoreError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toWebhookDeliveryResponse(delivery))
+	}
+}
+
+// Redeliver handles POST /v1/admin/webhooks/deliveries/{deliveryGuid}/redeliver
+//
+// The delivery is queued again with a fresh attempt budget and sent as soon
+// as a job worker picks it up.
+func (h *WebhookHandlers) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryGUID, ok := parseGUIDParam(w, r, "deliveryGuid", problem.InvalidDeliveryID, "Delivery")
+		if !ok {
+			return
+		}
+
+		delivery, err := h.webhooks.Redeliver(r.Context(), deliveryGUID)
+		if err != nil {
+			handleWebhookStoreError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, toWebhookDeliveryResponse(delivery))
+	}
+}
+
+// handleWebhookStoreError maps delivery store errors to HTTP responses
+func handleWebhookStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, webhook.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, problem.DeliveryNotFound, "Webhook delivery not found")
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, problem.InternalError, "Failed to load webhook delivery")
+}
+
+// toWebhookDeliveryResponse converts a delivery to the admin response format
+func toWebhookDeliveryResponse(delivery *webhook.Delivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		DeliveryGUID:   delivery.ID,
+		EventGUID:      delivery.EventID,
+		EventType:      delivery.EventType,
+		TypeName:       delivery.TypeName,
+		Webhook:        delivery.Webhook,
+		URL:            delivery.URL,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		LastStatusCode: delivery.LastStatusCode,
+		CreatedAt:      delivery.CreatedAt.UTC().Format(time.RFC3339),
+		Payload:        json.RawMessage(delivery.Payload),
+	}
+	if delivery.Status == webhook.StatusPending {
+		response.NextAttemptAt = delivery.NextAttemptAt.UTC().Format(time.RFC3339)
+	}
+	if delivery.DeliveredAt != nil {
+		response.DeliveredAt = delivery.DeliveredAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/idempotency"
+	"github.com/antonrybalko/image-service-go/internal/outbox"
+	"github.com/antonrybalko/image-service-go/internal/problem"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/antonrybalko/image-service-go/internal/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	// A receiver that is down
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	webhookConfig := &domain.ImageConfig{
+		Types: []domain.ImageType{
+			{
+				Name:      "user",
+				Ownership: domain.OwnershipSelf,
+				Sizes: domain.SizeSet{
+					"small":  {Width: 50, Height: 50},
+					"medium": {Width: 100, Height: 100},
+					"large":  {Width: 800, Height: 800},
+				},
+				Webhooks: []domain.Webhook{{Name: "search", URL: receiver.URL, Secret: "secret"}},
+			},
+		},
+	}
+	repo := repository.NewMockImageRepository()
+	imageService := service.NewImageService(repo, storage.NewMockS3(), processor.NewMockProcessor(), webhookConfig, zap.NewNop().Sugar())
+
+	// One failed attempt is enough to dead-letter a delivery
+	jobQueue := queue.NewMemoryQueue()
+	dispatcherConfig := webhook.DefaultConfig()
+	dispatcherConfig.MaxAttempts = 1
+	dispatcher := webhook.NewDispatcher(webhook.NewMemoryStore(), jobQueue, webhookConfig, dispatcherConfig, zap.NewNop().Sugar())
+	worker := queue.NewWorker(jobQueue, queue.DefaultConfig(), zap.NewNop().Sugar())
+	dispatcher.RegisterJobHandlers(worker)
+	relay := outbox.NewRelay(repo, webhook.NewPublisher(dispatcher), zap.NewNop().Sugar())
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.Algorithm = "HS256"
+	cfg.Idempotency.TTL = time.Hour
+	router := NewRouter(zap.NewNop().Sugar(), cfg, imageService, idempotency.NewMemoryStore(), dispatcher).Handler()
+
+	send := func(method, target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Uploading records an event, which the relay queues for delivery
+	userGUID := uuid.New()
+	req := httptest.NewRequest(http.MethodPut, "/v1/me/image", bytes.NewReader([]byte("mock-webhook-image-data")))
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t, userGUID.String()))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	published, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+
+	processed, err := worker.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	adminToken := newAdminTestToken(t)
+
+	// Deliveries are only visible to service admins
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries", newTestToken(t, userGUID.String()))
+	decodeProblem(t, rr, http.StatusForbidden, problem.Forbidden)
+
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries?status=dead", adminToken)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var list ListWebhookDeliveriesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.Len(t, list.Deliveries, 1)
+
+	dead := list.Deliveries[0]
+	assert.Equal(t, domain.EventImageUploaded, dead.EventType)
+	assert.Equal(t, "search", dead.Webhook)
+	assert.Equal(t, webhook.StatusDead, dead.Status)
+	assert.Equal(t, 1, dead.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, dead.LastStatusCode)
+	assert.Empty(t, dead.NextAttemptAt)
+
+	var event domain.ImageEvent
+	require.NoError(t, json.Unmarshal(dead.Payload, &event))
+	assert.Equal(t, userGUID, event.Image.OwnerGUID)
+
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries/"+dead.DeliveryGUID.String(), adminToken)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Redelivering queues it again
+	rr = send(http.MethodPost, "/v1/admin/webhooks/deliveries/"+dead.DeliveryGUID.String()+"/redeliver", adminToken)
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	var redelivered WebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &redelivered))
+	assert.Equal(t, webhook.StatusPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+	assert.NotEmpty(t, redelivered.NextAttemptAt)
+
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries?status=dead", adminToken)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	assert.Empty(t, list.Deliveries)
+
+	// The receiver is still down, so the new attempt dead-letters it again
+	processed, err = worker.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries/"+dead.DeliveryGUID.String(), adminToken)
+	var again WebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &again))
+	assert.Equal(t, webhook.StatusDead, again.Status)
+	assert.Equal(t, 1, again.Attempts)
+
+	// Errors
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries?status=failed", adminToken)
+	details := decodeProblem(t, rr, http.StatusBadRequest, problem.InvalidFilter)
+	assert.Equal(t, "status", details.Errors[0].Field)
+
+	rr = send(http.MethodGet, "/v1/admin/webhooks/deliveries/not-a-uuid", adminToken)
+	decodeProblem(t, rr, http.StatusBadRequest, problem.InvalidDeliveryID)
+
+	rr = send(http.MethodPost, "/v1/admin/webhooks/deliveries/"+uuid.NewString()+"/redeliver", adminToken)
+	decodeProblem(t, rr, http.StatusNotFound, problem.DeliveryNotFound)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +13,16 @@ type Config struct {
 	// Core service configuration
 	Environment string `mapstructure:"ENVIRONMENT"`
 	Port        int    `mapstructure:"PORT"`
+	GRPCPort    int    `mapstructure:"GRPC_PORT"` // 0 disables the gRPC server
+
+	// HTTP server configuration
+	HTTP struct {
+		ReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"` // Time limit for reading request headers
+		ReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`        // Time limit for reading a whole request, upload bodies included
+		WriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`       // Time limit from the end of the headers to the end of the response
+		IdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`        // How long idle keep-alive connections are kept open
+		RequestTimeout    time.Duration `mapstructure:"HTTP_REQUEST_TIMEOUT"`     // Time limit for handlers, answered with 504; 0 means none
+	} `mapstructure:",squash"`
 
 	// Database configuration
 	DB struct {
@@ -21,7 +32,7 @@ type Config struct {
 		Password string `mapstructure:"DB_PASSWORD"`
 		Name     string `mapstructure:"DB_NAME"`
 		SSLMode  string `mapstructure:"DB_SSLMODE"`
-	}
+	} `mapstructure:",squash"`
 
 	// S3 storage configuration
 	S3 struct {
@@ -32,19 +43,65 @@ type Config struct {
 		Endpoint        string `mapstructure:"S3_ENDPOINT"`
 		CDNBaseURL      string `mapstructure:"S3_CDN_BASE_URL"`
 		UsePathStyle    bool   `mapstructure:"S3_USE_PATH_STYLE"`
-	}
+		OriginalsBucket string `mapstructure:"S3_ORIGINALS_BUCKET"` // Empty keeps originals in Bucket
+	} `mapstructure:",squash"`
 
 	// JWT Authentication configuration
 	JWT struct {
 		PublicKeyURL string `mapstructure:"JWT_PUBLIC_KEY_URL"`
 		Secret       string `mapstructure:"JWT_SECRET"`
 		Algorithm    string `mapstructure:"JWT_ALGORITHM"`
-	}
+	} `mapstructure:",squash"`
 
 	// Image configuration
 	ImageConfig struct {
 		ConfigPath string `mapstructure:"IMAGE_CONFIG_PATH"`
-	}
+	} `mapstructure:",squash"`
+
+	// Upload configuration
+	Upload struct {
+		MaxImageSize int64 `mapstructure:"MAX_IMAGE_SIZE"` // Default limit in bytes; image types may set their own maxBytes
+	} `mapstructure:",squash"`
+
+	// On-the-fly rendering configuration
+	Render struct {
+		SigningKey string `mapstructure:"RENDER_SIGNING_KEY"` // HMAC key for rendition URLs; rendering is disabled when empty
+	} `mapstructure:",squash"`
+
+	// Idempotency-Key configuration
+	Idempotency struct {
+		TTL time.Duration `mapstructure:"IDEMPOTENCY_TTL"` // How long stored responses are replayed
+	} `mapstructure:",squash"`
+
+	// Event outbox configuration
+	Outbox struct {
+		PollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"` // How often recorded events are relayed
+		Retention    time.Duration `mapstructure:"OUTBOX_RETENTION"`     // How long published events are kept
+		Publisher    string        `mapstructure:"OUTBOX_PUBLISHER"`     // Built-in publisher: memory or file
+		FilePath     string        `mapstructure:"OUTBOX_FILE_PATH"`     // JSON lines file written by the file publisher
+	} `mapstructure:",squash"`
+
+	// Background processing configuration
+	Jobs struct {
+		Workers           int           `mapstructure:"JOB_WORKERS"`            // Queued jobs processed concurrently
+		PollInterval      time.Duration `mapstructure:"JOB_POLL_INTERVAL"`      // How often the queue is polled when idle
+		VisibilityTimeout time.Duration `mapstructure:"JOB_VISIBILITY_TIMEOUT"` // How long a claimed job may run before another worker retries it
+		Retention         time.Duration `mapstructure:"JOB_RETENTION"`          // How long done and dead jobs are kept
+		ReprocessInterval time.Duration `mapstructure:"REPROCESS_INTERVAL"`     // Pause after each image regenerated by reprocessing runs
+	} `mapstructure:",squash"`
+
+	// Webhook delivery configuration
+	Webhook struct {
+		MaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"` // Attempts before a delivery is dead-lettered
+		Timeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`      // Time limit for a single delivery attempt
+	} `mapstructure:",squash"`
+
+	// Remote image import configuration
+	Fetch struct {
+		Timeout         time.Duration `mapstructure:"FETCH_TIMEOUT"`
+		MaxRedirects    int           `mapstructure:"FETCH_MAX_REDIRECTS"`
+		AllowedNetworks []string      `mapstructure:"FETCH_ALLOWED_NETWORKS"` // CIDRs exempt from the private-range block
+	} `mapstructure:",squash"`
 }
 
 // Load reads the configuration from environment variables and returns a Config struct
@@ -58,6 +115,13 @@ func Load() (*Config, error) {
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	// Keys without defaults must be bound explicitly to be picked up by Unmarshal
+	for _, key := range []string{"S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "JWT_PUBLIC_KEY_URL", "JWT_SECRET", "RENDER_SIGNING_KEY"} {
+		if err := v.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("failed to bind %s: %w", key, err)
+		}
+	}
+
 	// Optional: Read from config file if specified
 	configFile := v.GetString("CONFIG_FILE")
 	if configFile != "" {
@@ -81,6 +145,15 @@ func setDefaults(v *viper.Viper) {
 	// Core service defaults
 	v.SetDefault("ENVIRONMENT", "development")
 	v.SetDefault("PORT", 8080)
+	v.SetDefault("GRPC_PORT", 9090)
+
+	// HTTP server defaults; the write timeout outlasts the request timeout so
+	// the 504 still reaches the client
+	v.SetDefault("HTTP_READ_HEADER_TIMEOUT", 10*time.Second)
+	v.SetDefault("HTTP_READ_TIMEOUT", 60*time.Second)
+	v.SetDefault("HTTP_WRITE_TIMEOUT", 70*time.Second)
+	v.SetDefault("HTTP_IDLE_TIMEOUT", 60*time.Second)
+	v.SetDefault("HTTP_REQUEST_TIMEOUT", 60*time.Second)
 
 	// Database defaults
 	v.SetDefault("DB_HOST", "localhost")
@@ -96,10 +169,39 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("S3_ENDPOINT", "")
 	v.SetDefault("S3_CDN_BASE_URL", "")
 	v.SetDefault("S3_USE_PATH_STYLE", false)
+	v.SetDefault("S3_ORIGINALS_BUCKET", "")
 
 	// JWT defaults
 	v.SetDefault("JWT_ALGORITHM", "RS256")
 
 	// Image config defaults - use the nested key format
 	v.SetDefault("IMAGE_CONFIG_PATH", "config/images.yaml")
+
+	// Upload defaults
+	v.SetDefault("MAX_IMAGE_SIZE", 15*1024*1024)
+
+	// Idempotency defaults
+	v.SetDefault("IDEMPOTENCY_TTL", 24*time.Hour)
+
+	// Outbox defaults
+	v.SetDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	v.SetDefault("OUTBOX_RETENTION", 24*time.Hour)
+	v.SetDefault("OUTBOX_PUBLISHER", "memory")
+	v.SetDefault("OUTBOX_FILE_PATH", "events.jsonl")
+
+	// Background processing defaults
+	v.SetDefault("JOB_WORKERS", 4)
+	v.SetDefault("JOB_POLL_INTERVAL", time.Second)
+	v.SetDefault("JOB_VISIBILITY_TIMEOUT", 10*time.Minute)
+	v.SetDefault("JOB_RETENTION", 24*time.Hour)
+	v.SetDefault("REPROCESS_INTERVAL", 100*time.Millisecond)
+
+	// Webhook defaults
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	v.SetDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+
+	// Remote import defaults
+	v.SetDefault("FETCH_TIMEOUT", 10*time.Second)
+	v.SetDefault("FETCH_MAX_REDIRECTS", 3)
+	v.SetDefault("FETCH_ALLOWED_NETWORKS", []string{})
 }
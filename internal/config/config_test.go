@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,6 +21,7 @@ func TestLoad_DefaultValues(t *testing.T) {
 	// Verify default values
 	assert.Equal(t, "development", cfg.Environment)
 	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 9090, cfg.GRPCPort)
 
 	// Database defaults
 	assert.Equal(t, "localhost", cfg.DB.Host)
@@ -35,12 +37,48 @@ func TestLoad_DefaultValues(t *testing.T) {
 	assert.Equal(t, "", cfg.S3.Endpoint)
 	assert.Equal(t, "", cfg.S3.CDNBaseURL)
 	assert.Equal(t, false, cfg.S3.UsePathStyle)
+	assert.Equal(t, "", cfg.S3.OriginalsBucket)
 
 	// JWT defaults
 	assert.Equal(t, "RS256", cfg.JWT.Algorithm)
 
 	// Image config defaults
 	assert.Equal(t, "config/images.yaml", cfg.ImageConfig.ConfigPath)
+
+	// Upload defaults
+	assert.Equal(t, int64(15*1024*1024), cfg.Upload.MaxImageSize)
+
+	// HTTP server defaults
+	assert.Equal(t, 10*time.Second, cfg.HTTP.ReadHeaderTimeout)
+	assert.Equal(t, 60*time.Second, cfg.HTTP.ReadTimeout)
+	assert.Equal(t, 70*time.Second, cfg.HTTP.WriteTimeout)
+	assert.Equal(t, 60*time.Second, cfg.HTTP.IdleTimeout)
+	assert.Equal(t, 60*time.Second, cfg.HTTP.RequestTimeout)
+
+	// Idempotency defaults
+	assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+
+	// Outbox defaults
+	assert.Equal(t, time.Second, cfg.Outbox.PollInterval)
+	assert.Equal(t, 24*time.Hour, cfg.Outbox.Retention)
+	assert.Equal(t, "memory", cfg.Outbox.Publisher)
+	assert.Equal(t, "events.jsonl", cfg.Outbox.FilePath)
+
+	// Background processing defaults
+	assert.Equal(t, 4, cfg.Jobs.Workers)
+	assert.Equal(t, time.Second, cfg.Jobs.PollInterval)
+	assert.Equal(t, 10*time.Minute, cfg.Jobs.VisibilityTimeout)
+	assert.Equal(t, 24*time.Hour, cfg.Jobs.Retention)
+	assert.Equal(t, 100*time.Millisecond, cfg.Jobs.ReprocessInterval)
+
+	// Webhook defaults
+	assert.Equal(t, 10, cfg.Webhook.MaxAttempts)
+	assert.Equal(t, 10*time.Second, cfg.Webhook.Timeout)
+
+	// Remote import defaults
+	assert.Equal(t, 10*time.Second, cfg.Fetch.Timeout)
+	assert.Equal(t, 3, cfg.Fetch.MaxRedirects)
+	assert.Empty(t, cfg.Fetch.AllowedNetworks)
 }
 
 func TestLoad_EnvironmentVariables(t *testing.T) {
@@ -49,25 +87,42 @@ func TestLoad_EnvironmentVariables(t *testing.T) {
 
 	// Set environment variables
 	envVars := map[string]string{
-		"ENVIRONMENT":          "production",
-		"PORT":                 "9090",
-		"DB_HOST":              "db.example.com",
-		"DB_PORT":              "5433",
-		"DB_USER":              "dbuser",
-		"DB_PASSWORD":          "dbpass",
-		"DB_NAME":              "imagedb",
-		"DB_SSLMODE":           "require",
-		"S3_REGION":            "eu-west-1",
-		"S3_BUCKET":            "my-images",
-		"S3_ACCESS_KEY_ID":     "access123",
-		"S3_SECRET_ACCESS_KEY": "secret456",
-		"S3_ENDPOINT":          "https://minio.example.com",
-		"S3_CDN_BASE_URL":      "https://cdn.example.com",
-		"S3_USE_PATH_STYLE":    "true",
-		"JWT_PUBLIC_KEY_URL":   "https://auth.example.com/.well-known/jwks.json",
-		"JWT_SECRET":           "supersecret",
-		"JWT_ALGORITHM":        "HS256",
-		"IMAGE_CONFIG_PATH":    "test/images.yaml",
+		"ENVIRONMENT":            "production",
+		"PORT":                   "9090",
+		"GRPC_PORT":              "9191",
+		"DB_HOST":                "db.example.com",
+		"DB_PORT":                "5433",
+		"DB_USER":                "dbuser",
+		"DB_PASSWORD":            "dbpass",
+		"DB_NAME":                "imagedb",
+		"DB_SSLMODE":             "require",
+		"S3_REGION":              "eu-west-1",
+		"S3_BUCKET":              "my-images",
+		"S3_ACCESS_KEY_ID":       "access123",
+		"S3_SECRET_ACCESS_KEY":   "secret456",
+		"S3_ENDPOINT":            "https://minio.example.com",
+		"S3_CDN_BASE_URL":        "https://cdn.example.com",
+		"S3_USE_PATH_STYLE":      "true",
+		"S3_ORIGINALS_BUCKET":    "my-originals",
+		"JWT_PUBLIC_KEY_URL":     "https://auth.example.com/.well-known/jwks.json",
+		"JWT_SECRET":             "supersecret",
+		"JWT_ALGORITHM":          "HS256",
+		"IMAGE_CONFIG_PATH":      "test/images.yaml",
+		"MAX_IMAGE_SIZE":         "1048576",
+		"RENDER_SIGNING_KEY":     "render-key",
+		"OUTBOX_PUBLISHER":       "file",
+		"OUTBOX_FILE_PATH":       "/var/log/events.jsonl",
+		"JOB_WORKERS":            "8",
+		"JOB_POLL_INTERVAL":      "30s",
+		"JOB_VISIBILITY_TIMEOUT": "2m",
+		"HTTP_READ_TIMEOUT":      "5m",
+		"HTTP_WRITE_TIMEOUT":     "6m",
+		"HTTP_REQUEST_TIMEOUT":   "5m",
+		"REPROCESS_INTERVAL":     "1s",
+		"WEBHOOK_MAX_ATTEMPTS":   "4",
+		"FETCH_TIMEOUT":          "3s",
+		"FETCH_MAX_REDIRECTS":    "1",
+		"FETCH_ALLOWED_NETWORKS": "10.20.0.0/16,192.168.5.5",
 	}
 
 	for k, v := range envVars {
@@ -84,6 +139,7 @@ func TestLoad_EnvironmentVariables(t *testing.T) {
 	// Verify environment variables were loaded correctly
 	assert.Equal(t, "production", cfg.Environment)
 	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, 9191, cfg.GRPCPort)
 
 	// Database config
 	assert.Equal(t, "db.example.com", cfg.DB.Host)
@@ -101,6 +157,7 @@ func TestLoad_EnvironmentVariables(t *testing.T) {
 	assert.Equal(t, "https://minio.example.com", cfg.S3.Endpoint)
 	assert.Equal(t, "https://cdn.example.com", cfg.S3.CDNBaseURL)
 	assert.Equal(t, true, cfg.S3.UsePathStyle)
+	assert.Equal(t, "my-originals", cfg.S3.OriginalsBucket)
 
 	// JWT config
 	assert.Equal(t, "https://auth.example.com/.well-known/jwks.json", cfg.JWT.PublicKeyURL)
@@ -110,6 +167,35 @@ func TestLoad_EnvironmentVariables(t *testing.T) {
 	// Image config
 	assert.Equal(t, "test/images.yaml", cfg.ImageConfig.ConfigPath)
 
+	// HTTP server config
+	assert.Equal(t, 5*time.Minute, cfg.HTTP.ReadTimeout)
+	assert.Equal(t, 6*time.Minute, cfg.HTTP.WriteTimeout)
+	assert.Equal(t, 5*time.Minute, cfg.HTTP.RequestTimeout)
+
+	// Upload config
+	assert.Equal(t, int64(1048576), cfg.Upload.MaxImageSize)
+
+	// Render config
+	assert.Equal(t, "render-key", cfg.Render.SigningKey)
+
+	// Outbox config
+	assert.Equal(t, "file", cfg.Outbox.Publisher)
+	assert.Equal(t, "/var/log/events.jsonl", cfg.Outbox.FilePath)
+
+	// Background processing config
+	assert.Equal(t, 8, cfg.Jobs.Workers)
+	assert.Equal(t, 30*time.Second, cfg.Jobs.PollInterval)
+	assert.Equal(t, 2*time.Minute, cfg.Jobs.VisibilityTimeout)
+	assert.Equal(t, time.Second, cfg.Jobs.ReprocessInterval)
+
+	// Webhook config
+	assert.Equal(t, 4, cfg.Webhook.MaxAttempts)
+
+	// Remote import config
+	assert.Equal(t, 3*time.Second, cfg.Fetch.Timeout)
+	assert.Equal(t, 1, cfg.Fetch.MaxRedirects)
+	assert.Equal(t, []string{"10.20.0.0/16", "192.168.5.5"}, cfg.Fetch.AllowedNetworks)
+
 	// Clean up
 	os.Clearenv()
 }
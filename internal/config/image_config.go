@@ -3,8 +3,12 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/antonrybalko/image-service-go/internal/domain"
 	"gopkg.in/yaml.v3"
@@ -29,6 +33,14 @@ func LoadImageConfig(configPath string) (*domain.ImageConfig, error) {
 		return nil, fmt.Errorf("failed to parse image config YAML: %w", err)
 	}
 
+	// Webhook secrets are kept out of the file by referencing environment variables
+	for i := range config.Types {
+		for j := range config.Types[i].Webhooks {
+			webhook := &config.Types[i].Webhooks[j]
+			webhook.Secret = os.ExpandEnv(webhook.Secret)
+		}
+	}
+
 	// Validate config
 	if err := validateImageConfig(&config); err != nil {
 		return nil, fmt.Errorf("invalid image config: %w", err)
@@ -61,6 +73,51 @@ func validateImageConfig(config *domain.ImageConfig) error {
 		}
 		typeNames[imageType.Name] = true
 
+		// Check cardinality
+		switch imageType.Cardinality {
+		case "", domain.CardinalitySingle:
+			if imageType.MaxImages != 0 {
+				return fmt.Errorf("image type '%s' sets maxImages but is not a collection", imageType.Name)
+			}
+		case domain.CardinalityMultiple:
+			if imageType.MaxImages < 0 {
+				return fmt.Errorf("image type '%s' has negative maxImages", imageType.Name)
+			}
+		default:
+			return fmt.Errorf("image type '%s' has invalid cardinality '%s': must be '%s' or '%s'",
+				imageType.Name, imageType.Cardinality, domain.CardinalitySingle, domain.CardinalityMultiple)
+		}
+
+		// Check ownership rule
+		switch imageType.Ownership {
+		case "", domain.OwnershipSelf, domain.OwnershipOrganizationAdmin, domain.OwnershipAuthenticated:
+		default:
+			return fmt.Errorf("image type '%s' has invalid ownership '%s': must be '%s', '%s' or '%s'",
+				imageType.Name, imageType.Ownership,
+				domain.OwnershipSelf, domain.OwnershipOrganizationAdmin, domain.OwnershipAuthenticated)
+		}
+
+		// Check the default image, which clients are redirected to
+		if imageType.DefaultImage != "" && imageType.Placeholder {
+			return fmt.Errorf("image type '%s' sets both defaultImage and placeholder", imageType.Name)
+		}
+		if imageType.DefaultImage != "" {
+			if err := validateDefaultImage(imageType.DefaultImage); err != nil {
+				return fmt.Errorf("image type '%s' has invalid defaultImage: %w", imageType.Name, err)
+			}
+		}
+
+		// Check original retention
+		switch imageType.OriginalRetention {
+		case "", domain.RetainOriginalForever, domain.RetainOriginalNone:
+		default:
+			period, err := time.ParseDuration(imageType.OriginalRetention)
+			if err != nil || period <= 0 {
+				return fmt.Errorf("image type '%s' has invalid originalRetention '%s': must be '%s', '%s' or a positive duration",
+					imageType.Name, imageType.OriginalRetention, domain.RetainOriginalForever, domain.RetainOriginalNone)
+			}
+		}
+
 		// Check sizes
 		if len(imageType.Sizes) == 0 {
 			return fmt.Errorf("image type '%s' has no sizes defined", imageType.Name)
@@ -71,21 +128,114 @@ func validateImageConfig(config *domain.ImageConfig) error {
 			if sizeName == "" {
 				return fmt.Errorf("image type '%s' has a size with no name", imageType.Name)
 			}
+			if !slices.Contains(domain.VariantSizes, sizeName) {
+				return fmt.Errorf("image type '%s' has unknown size '%s': must be one of %s",
+					imageType.Name, sizeName, strings.Join(domain.VariantSizes, ", "))
+			}
 
 			// At least one dimension must be specified
 			if size.Width <= 0 && size.Height <= 0 {
 				return fmt.Errorf("image type '%s', size '%s' has invalid dimensions: width and height cannot both be zero or negative",
 					imageType.Name, sizeName)
 			}
+
+			if err := validateSizeFit(size); err != nil {
+				return fmt.Errorf("image type '%s', size '%s' %w", imageType.Name, sizeName, err)
+			}
 		}
 
 		// Check for required size names: small, medium, large
-		requiredSizes := []string{"small", "medium", "large"}
-		for _, required := range requiredSizes {
+		for _, required := range domain.VariantSizes {
 			if _, exists := imageType.Sizes[required]; !exists {
 				return fmt.Errorf("image type '%s' is missing required size '%s'", imageType.Name, required)
 			}
 		}
+
+		// Check webhook subscriptions
+		webhookNames := make(map[string]bool)
+		for j := range imageType.Webhooks {
+			webhook := &imageType.Webhooks[j]
+			if err := validateWebhook(webhook); err != nil {
+				return fmt.Errorf("image type '%s' has invalid webhook at index %d: %w", imageType.Name, j, err)
+			}
+			if webhookNames[webhook.Name] {
+				return fmt.Errorf("image type '%s' has duplicate webhook name: %s", imageType.Name, webhook.Name)
+			}
+			webhookNames[webhook.Name] = true
+		}
+	}
+
+	return nil
+}
+
+// validateSizeFit checks the fit mode, gravity and background of a size,
+// which only fixed-size variants can use
+func validateSizeFit(size domain.Size) error {
+	if !size.IsFixed() {
+		if size.Fit != "" || size.Gravity != "" || size.Background != "" {
+			return errors.New("sets fit, gravity or background without both width and height")
+		}
+		return nil
+	}
+
+	if size.Fit != "" && !slices.Contains(domain.SizeFits, size.Fit) {
+		return fmt.Errorf("has invalid fit '%s': must be one of %s", size.Fit, strings.Join(domain.SizeFits, ", "))
+	}
+	if size.Gravity != "" {
+		if !slices.Contains(domain.Gravities, size.Gravity) {
+			return fmt.Errorf("has invalid gravity '%s': must be one of %s", size.Gravity, strings.Join(domain.Gravities, ", "))
+		}
+		if fit := size.EffectiveFit(); fit != domain.FitCover && fit != domain.FitContain {
+			return fmt.Errorf("sets gravity, which %s fit ignores", fit)
+		}
+	}
+	if size.Background != "" {
+		if size.EffectiveFit() != domain.FitContain {
+			return fmt.Errorf("sets background, which only %s fit uses", domain.FitContain)
+		}
+		if _, err := size.BackgroundColor(); err != nil {
+			return fmt.Errorf("has invalid background: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateDefaultImage checks that a default image is an absolute http(s) URL or an absolute path
+func validateDefaultImage(defaultImage string) error {
+	u, err := url.Parse(strings.ReplaceAll(defaultImage, "{size}", "size"))
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" && u.Host == "" && strings.HasPrefix(u.Path, "/") && !strings.HasPrefix(u.Path, "//") {
+		return nil
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http(s) URL or an absolute path")
+	}
+	return nil
+}
+
+// validateWebhook checks that a webhook subscription is complete and only
+// subscribes to known events
+func validateWebhook(webhook *domain.Webhook) error {
+	if webhook.Name == "" {
+		return errors.New("name is required")
+	}
+
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook '%s' url must be an http(s) URL", webhook.Name)
+	}
+
+	if webhook.Secret == "" {
+		return fmt.Errorf("webhook '%s' has no secret", webhook.Name)
+	}
+
+	for _, event := range webhook.Events {
+		if !slices.Contains(domain.EventTypes, event) {
+			return fmt.Errorf("webhook '%s' has unknown event '%s': must be one of %s",
+				webhook.Name, event, strings.Join(domain.EventTypes, ", "))
+		}
 	}
 
 	return nil
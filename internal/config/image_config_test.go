@@ -36,6 +36,9 @@ images:
       large:
         width: 800
         height: 800
+        fit: contain
+        gravity: north
+        background: "#202020"
   - name: organization
     sizes:
       small:
@@ -69,6 +72,9 @@ images:
 	assert.Equal(t, 100, userType.Sizes["medium"].Height)
 	assert.Equal(t, 800, userType.Sizes["large"].Width)
 	assert.Equal(t, 800, userType.Sizes["large"].Height)
+	assert.Equal(t, domain.FitContain, userType.Sizes["large"].Fit)
+	assert.Equal(t, domain.GravityNorth, userType.Sizes["large"].Gravity)
+	assert.Equal(t, "#202020", userType.Sizes["large"].Background)
 
 	// Verify organization image type
 	orgType := config.Types[1]
@@ -119,6 +125,47 @@ images:
 	assert.Contains(t, err.Error(), "failed to parse")
 }
 
+// TestLoadImageConfig_WebhookSecrets tests that webhook secrets are read from the environment
+func TestLoadImageConfig_WebhookSecrets(t *testing.T) {
+	t.Setenv("SEARCH_WEBHOOK_SECRET", "from-env")
+
+	configPath := filepath.Join(t.TempDir(), "webhooks.yaml")
+	configContent := `
+images:
+  - name: user
+    sizes:
+      small:
+        width: 50
+        height: 50
+      medium:
+        width: 100
+        height: 100
+      large:
+        width: 800
+        height: 800
+    webhooks:
+      - name: search
+        url: https://search.example.com/hooks/images
+        secret: ${SEARCH_WEBHOOK_SECRET}
+        events: [image.uploaded, image.deleted]
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	config, err := LoadImageConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, config.Types[0].Webhooks, 1)
+
+	webhook := config.Types[0].Webhooks[0]
+	assert.Equal(t, "from-env", webhook.Secret)
+	assert.True(t, webhook.Wants(domain.EventImageDeleted))
+	assert.False(t, webhook.Wants(domain.EventImageReplaced))
+
+	// An unset variable leaves the webhook without a secret
+	t.Setenv("SEARCH_WEBHOOK_SECRET", "")
+	_, err = LoadImageConfig(configPath)
+	assert.ErrorContains(t, err, "has no secret")
+}
+
 // TestValidateImageConfig tests the validation of image configurations
 func TestValidateImageConfig(t *testing.T) {
 	tests := []struct {
@@ -213,6 +260,124 @@ func TestValidateImageConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid dimensions",
 		},
+		{
+			name: "Invalid fit",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50, Fit: "stretch"},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid fit 'stretch'",
+		},
+		{
+			name: "Fit on auto-scaled size",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Fit: domain.FitCover},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "without both width and height",
+		},
+		{
+			name: "Gravity ignored by fit",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50, Fit: domain.FitFill, Gravity: domain.GravityNorth},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "sets gravity",
+		},
+		{
+			name: "Invalid gravity",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50, Gravity: "top"},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid gravity 'top'",
+		},
+		{
+			name: "Background without contain",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50, Background: "#000000"},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "sets background",
+		},
+		{
+			name: "Invalid background",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50, Fit: domain.FitContain, Background: "black"},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid background",
+		},
+		{
+			name: "Fit and gravity",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50, Fit: domain.FitContain, Gravity: domain.GravitySouthEast, Background: "#FFCC00"},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
 		{
 			name: "Missing required size",
 			config: &domain.ImageConfig{
@@ -230,6 +395,266 @@ func TestValidateImageConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "missing required size",
 		},
+		{
+			name: "Unknown size",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+							"xlarge": {Width: 1600, Height: 1600},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "unknown size 'xlarge'",
+		},
+		{
+			name: "Invalid cardinality",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:        "product",
+						Cardinality: "many",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 200, Height: 0},
+							"medium": {Width: 600, Height: 0},
+							"large":  {Width: 1200, Height: 0},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid cardinality",
+		},
+		{
+			name: "Max images on single type",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:      "user",
+						MaxImages: 5,
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "not a collection",
+		},
+		{
+			name: "Invalid ownership",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:      "user",
+						Ownership: "anyone",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid ownership",
+		},
+		{
+			name: "Invalid original retention",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:              "user",
+						OriginalRetention: "a while",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid originalRetention",
+		},
+		{
+			name: "Original retention period",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:              "user",
+						OriginalRetention: "720h",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid collection type",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:        "product",
+						Cardinality: domain.CardinalityMultiple,
+						MaxImages:   20,
+						Sizes: domain.SizeSet{
+							"small":  {Width: 200, Height: 0},
+							"medium": {Width: 600, Height: 0},
+							"large":  {Width: 1200, Height: 0},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid default image",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:         "user",
+						DefaultImage: "ftp://example.com/avatar-{size}.png",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid defaultImage",
+		},
+		{
+			name: "Default image and placeholder",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:         "user",
+						DefaultImage: "/static/avatar-{size}.png",
+						Placeholder:  true,
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "both defaultImage and placeholder",
+		},
+		{
+			name: "Default image path",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name:         "user",
+						DefaultImage: "/static/avatar-{size}.png",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Webhook without URL",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+						Webhooks: []domain.Webhook{
+							{Name: "search", URL: "ftp://search.example.com", Secret: "secret"},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "url must be an http(s) URL",
+		},
+		{
+			name: "Webhook without secret",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+						Webhooks: []domain.Webhook{
+							{Name: "search", URL: "https://search.example.com/hooks"},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "has no secret",
+		},
+		{
+			name: "Webhook with unknown event",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+						Webhooks: []domain.Webhook{
+							{Name: "search", URL: "https://search.example.com/hooks", Secret: "secret", Events: []string{"image.resized"}},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "unknown event 'image.resized'",
+		},
+		{
+			name: "Duplicate webhook names",
+			config: &domain.ImageConfig{
+				Types: []domain.ImageType{
+					{
+						Name: "user",
+						Sizes: domain.SizeSet{
+							"small":  {Width: 50, Height: 50},
+							"medium": {Width: 100, Height: 100},
+							"large":  {Width: 800, Height: 800},
+						},
+						Webhooks: []domain.Webhook{
+							{Name: "search", URL: "https://search.example.com/hooks", Secret: "secret"},
+							{Name: "search", URL: "https://search.example.com/other", Secret: "secret"},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "duplicate webhook name",
+		},
 		{
 			name: "Valid config",
 			config: &domain.ImageConfig{
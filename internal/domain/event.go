@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Image lifecycle event types
+const (
+	EventImageUploaded = "image.uploaded" // A new image was added for an owner
+	EventImageReplaced = "image.replaced" // An owner's image of a single-image type was replaced
+	EventImageDeleted  = "image.deleted"  // An image was removed
+)
+
+// EventTypes lists every image lifecycle event type
+var EventTypes = []string{EventImageUploaded, EventImageReplaced, EventImageDeleted}
+
+// ImageEvent describes a committed change to an image. For deletions Image
+// is the image as it was before it was removed.
+type ImageEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Image      *Image    `json:"image"`
+}
+
+// NewImageEvent creates an event of the given type for an image
+func NewImageEvent(eventType string, image *Image) ImageEvent {
+	return ImageEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Image:      image,
+	}
+}
+
+// Webhook subscribes an HTTP endpoint to the lifecycle events of an image type
+type Webhook struct {
+	Name   string   `json:"name" yaml:"name"`     // Unique within the image type
+	URL    string   `json:"url" yaml:"url"`       // http(s) endpoint receiving the events
+	Secret string   `json:"-" yaml:"secret"`      // HMAC-SHA256 key; ${VAR} references are expanded from the environment
+	Events []string `json:"events" yaml:"events"` // Event types to deliver, empty means all
+}
+
+// Wants reports whether the webhook subscribes to the event type
+func (w *Webhook) Wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,13 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,17 +15,222 @@ import (
 
 // Size represents the dimensions for an image variant
 type Size struct {
-	Width  int `json:"width" yaml:"width"`
-	Height int `json:"height" yaml:"height"` // 0 means auto-scale height proportionally
+	Width      int    `json:"width" yaml:"width"`
+	Height     int    `json:"height" yaml:"height"`                   // 0 means auto-scale height proportionally
+	Fit        string `json:"fit,omitempty" yaml:"fit"`               // How a fixed-size variant fills its box, empty means DefaultSizeFit
+	Gravity    string `json:"gravity,omitempty" yaml:"gravity"`       // Part kept by cover, or where contain places the image; empty means center
+	Background string `json:"background,omitempty" yaml:"background"` // Padding color of contain as "#rrggbb", empty means DefaultBackground
+}
+
+// Fit modes of fixed-size variants, whose width and height are both set.
+// Cover and fill work as for renditions, but contain pads the variant out to
+// the whole box; inside leaves it smaller, as contain does for renditions.
+const (
+	FitInside = "inside" // Scale to fit inside the box, preserving aspect ratio
+
+	DefaultSizeFit    = FitCover
+	DefaultBackground = "#ffffff"
+)
+
+// SizeFits lists the fit modes of fixed-size variants
+var SizeFits = []string{FitCover, FitContain, FitFill, FitInside}
+
+// Gravities anchoring the part of an image kept by cover, or the image within
+// the padding of contain
+const (
+	GravityCenter    = "center"
+	GravityNorth     = "north"
+	GravityNorthEast = "northeast"
+	GravityEast      = "east"
+	GravitySouthEast = "southeast"
+	GravitySouth     = "south"
+	GravitySouthWest = "southwest"
+	GravityWest      = "west"
+	GravityNorthWest = "northwest"
+)
+
+// Gravities lists every gravity
+var Gravities = []string{
+	GravityCenter, GravityNorth, GravityNorthEast, GravityEast, GravitySouthEast,
+	GravitySouth, GravitySouthWest, GravityWest, GravityNorthWest,
+}
+
+// IsFixed reports whether both dimensions are set, so the variant has a fixed
+// box that Fit decides how to fill
+func (s Size) IsFixed() bool {
+	return s.Width > 0 && s.Height > 0
+}
+
+// EffectiveFit returns the fit mode of a fixed-size variant, applying the default
+func (s Size) EffectiveFit() string {
+	if s.Fit == "" {
+		return DefaultSizeFit
+	}
+	return s.Fit
+}
+
+// EffectiveGravity returns the gravity, applying the default
+func (s Size) EffectiveGravity() string {
+	if s.Gravity == "" {
+		return GravityCenter
+	}
+	return s.Gravity
+}
+
+// BackgroundColor returns the padding color of contain, applying the default
+func (s Size) BackgroundColor() (color.RGBA, error) {
+	background := s.Background
+	if background == "" {
+		background = DefaultBackground
+	}
+	return ParseColor(background)
+}
+
+// normalized returns the size with defaults applied and the settings that
+// have no effect on it cleared, so equivalent definitions compare equal
+func (s Size) normalized() Size {
+	normalized := Size{Width: s.Width, Height: s.Height}
+	if !s.IsFixed() {
+		return normalized
+	}
+
+	normalized.Fit = s.EffectiveFit()
+	switch normalized.Fit {
+	case FitCover:
+		normalized.Gravity = s.EffectiveGravity()
+	case FitContain:
+		normalized.Gravity = s.EffectiveGravity()
+		background, _ := s.BackgroundColor()
+		normalized.Background = fmt.Sprintf("#%02x%02x%02x", background.R, background.G, background.B)
+	}
+	return normalized
+}
+
+// ParseColor parses an opaque "#rrggbb" color
+func ParseColor(value string) (color.RGBA, error) {
+	if len(value) != 7 || value[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("invalid color %q: must be #rrggbb", value)
+	}
+	rgb, err := strconv.ParseUint(value[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q: must be #rrggbb", value)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
 }
 
 // SizeSet is a map of named sizes (small, medium, large) to their dimensions
 type SizeSet map[string]Size
 
+// VariantSizes are the size names every image type defines, and no others;
+// image metadata keeps one URL per size
+var VariantSizes = []string{"small", "medium", "large"}
+
+// Image type cardinalities
+const (
+	CardinalitySingle   = "single"   // One image per owner; uploads replace the existing image
+	CardinalityMultiple = "multiple" // An ordered collection of images per owner
+)
+
+// Ownership rules deciding who may write images of a type for a given owner GUID
+const (
+	OwnershipSelf              = "self"              // Owner GUID must be the authenticated user
+	OwnershipOrganizationAdmin = "organizationAdmin" // Caller must be an admin of the owner organization
+	OwnershipAuthenticated     = "authenticated"     // Any authenticated caller
+)
+
 // ImageType represents a category of images with specific size configurations
 type ImageType struct {
-	Name  string  `json:"name" yaml:"name"`
-	Sizes SizeSet `json:"sizes" yaml:"sizes"`
+	Name              string    `json:"name" yaml:"name"`
+	Cardinality       string    `json:"cardinality,omitempty" yaml:"cardinality"`             // Empty means single
+	MaxImages         int       `json:"maxImages,omitempty" yaml:"maxImages"`                 // Collection limit, 0 means unlimited
+	Ownership         string    `json:"ownership,omitempty" yaml:"ownership"`                 // Empty means self
+	MaxBytes          int64     `json:"maxBytes,omitempty" yaml:"maxBytes"`                   // Upload size limit, 0 means the service default
+	CacheControl      string    `json:"cacheControl,omitempty" yaml:"cacheControl"`           // Cache-Control for served files, empty means DefaultCacheControl
+	DefaultImage      string    `json:"defaultImage,omitempty" yaml:"defaultImage"`           // URL used when an owner has no image; "{size}" is replaced by the size name
+	Placeholder       bool      `json:"placeholder,omitempty" yaml:"placeholder"`             // Generate initials/identicon placeholders for owners without an image
+	OriginalRetention string    `json:"originalRetention,omitempty" yaml:"originalRetention"` // How long uploaded originals are kept: "forever" (the default), "none", or a duration such as "720h"
+	Sizes             SizeSet   `json:"sizes" yaml:"sizes"`
+	Webhooks          []Webhook `json:"webhooks,omitempty" yaml:"webhooks"` // Endpoints notified of the type's image lifecycle events
+}
+
+// Webhook returns the type's webhook with the given name
+func (t *ImageType) Webhook(name string) (*Webhook, bool) {
+	for i := range t.Webhooks {
+		if t.Webhooks[i].Name == name {
+			return &t.Webhooks[i], true
+		}
+	}
+	return nil, false
+}
+
+// Original retention policies; any other OriginalRetention is a duration
+const (
+	RetainOriginalForever = "forever" // Keep originals as long as the image
+	RetainOriginalNone    = "none"    // Don't store originals
+)
+
+// OriginalRetentionPeriod reports whether the type stores uploaded originals
+// and for how long after the upload they are kept, 0 meaning as long as the
+// image. Invalid retentions are rejected when the configuration is loaded,
+// and keep originals forever here.
+func (t *ImageType) OriginalRetentionPeriod() (keep bool, period time.Duration) {
+	switch t.OriginalRetention {
+	case "", RetainOriginalForever:
+		return true, 0
+	case RetainOriginalNone:
+		return false, 0
+	}
+	period, err := time.ParseDuration(t.OriginalRetention)
+	if err != nil || period <= 0 {
+		return true, 0
+	}
+	return true, period
+}
+
+// DefaultCacheControl is the Cache-Control header for served files of types that don't set one
+const DefaultCacheControl = "public, max-age=86400"
+
+// CacheControlHeader returns the type's Cache-Control header, defaulting to DefaultCacheControl
+func (t *ImageType) CacheControlHeader() string {
+	if t.CacheControl == "" {
+		return DefaultCacheControl
+	}
+	return t.CacheControl
+}
+
+// OwnershipRule returns the type's ownership rule, defaulting to OwnershipSelf
+func (t *ImageType) OwnershipRule() string {
+	if t.Ownership == "" {
+		return OwnershipSelf
+	}
+	return t.Ownership
+}
+
+// DefaultImageURL returns the type's default image URL for a size, or "" if the type has none
+func (t *ImageType) DefaultImageURL(size string) string {
+	return strings.ReplaceAll(t.DefaultImage, "{size}", size)
+}
+
+// SizesHash fingerprints the type's size definitions. Images record the hash
+// their variants were generated with, so those made before a change to the
+// sizes can be found and reprocessed.
+func (t *ImageType) SizesHash() string {
+	// Maps marshal with sorted keys, so equal size sets hash the same
+	sizes := make(SizeSet, len(t.Sizes))
+	for name, size := range t.Sizes {
+		sizes[name] = size.normalized()
+	}
+	encoded, err := json.Marshal(sizes)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:8])
+}
+
+// IsCollection reports whether owners can have multiple images of this type
+func (t *ImageType) IsCollection() bool {
+	return t.Cardinality == CardinalityMultiple
 }
 
 // ImageConfig holds the configuration for all image types
@@ -28,27 +240,153 @@ type ImageConfig struct {
 
 // Image represents a stored image with its metadata and URLs
 type Image struct {
-	GUID           uuid.UUID `json:"guid" db:"guid"`
-	OwnerGUID      uuid.UUID `json:"ownerGuid" db:"owner_guid"` // User or Organization GUID
-	TypeName       string    `json:"typeName" db:"type_name"`   // "user", "organization", etc.
-	SmallURL       string    `json:"smallUrl" db:"small_url"`
-	MediumURL      string    `json:"mediumUrl" db:"medium_url"`
-	LargeURL       string    `json:"largeUrl" db:"large_url"`
-	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
-	ContentType    string    `json:"contentType,omitempty" db:"content_type"`
-	OriginalWidth  int       `json:"originalWidth,omitempty" db:"original_width"`
-	OriginalHeight int       `json:"originalHeight,omitempty" db:"original_height"`
+	GUID             uuid.UUID `json:"guid" db:"guid"`
+	OwnerGUID        uuid.UUID `json:"ownerGuid" db:"owner_guid"` // User or Organization GUID
+	TypeName         string    `json:"typeName" db:"type_name"`   // "user", "organization", etc.
+	SmallURL         string    `json:"smallUrl" db:"small_url"`
+	MediumURL        string    `json:"mediumUrl" db:"medium_url"`
+	LargeURL         string    `json:"largeUrl" db:"large_url"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time `json:"updatedAt" db:"updated_at"`
+	ContentType      string    `json:"contentType,omitempty" db:"content_type"`
+	OriginalWidth    int       `json:"originalWidth,omitempty" db:"original_width"`
+	OriginalHeight   int       `json:"originalHeight,omitempty" db:"original_height"`
+	Position         int       `json:"position" db:"position"`    // Order within a collection, starting at 0
+	IsPrimary        bool      `json:"isPrimary" db:"is_primary"` // Primary image of a collection
+	AltText          string    `json:"altText,omitempty" db:"alt_text"`
+	Version          int64     `json:"version" db:"version"`                              // Incremented on every change, for conditional writes
+	SizesHash        string    `json:"sizesHash,omitempty" db:"sizes_hash"`               // ImageType.SizesHash of the sizes the variants were generated with
+	OriginalKey      string    `json:"-" db:"original_key"`                               // Storage key of the uploaded original, "" if none is kept
+	OriginalChecksum string    `json:"originalChecksum,omitempty" db:"original_checksum"` // SHA-256 of the original, hex encoded
+	OriginalSize     int64     `json:"originalSize,omitempty" db:"original_size"`         // Size of the original in bytes
+	OriginalCrop     *Crop     `json:"originalCrop,omitempty" db:"original_crop"`         // Crop applied to the original before resizing, nil for none
+	IsPlaceholder    bool      `json:"isPlaceholder,omitempty" db:"-"`                    // Generated stand-in, never stored
+}
+
+// ETag returns the entity tag of an image's metadata, or "" for placeholders
+func (i *Image) ETag() string {
+	if i.IsPlaceholder {
+		return ""
+	}
+	return ETag(i.GUID, i.UpdatedAt)
+}
+
+// ETag derives a strong entity tag from an image GUID and update time. The
+// time is truncated to microseconds, the precision the database stores.
+func ETag(imageGUID uuid.UUID, updatedAt time.Time) string {
+	return fmt.Sprintf(`"%s-%x"`, imageGUID, updatedAt.UnixMicro())
+}
+
+// Precondition holds the If-Match and If-None-Match conditions of a write.
+// Each lists entity tags, or "*" for any current image.
+type Precondition struct {
+	IfMatch     []string
+	IfNoneMatch []string
+}
+
+// IsZero reports whether the precondition has no conditions
+func (p Precondition) IsZero() bool {
+	return len(p.IfMatch) == 0 && len(p.IfNoneMatch) == 0
+}
+
+// Allows reports whether a write may proceed given the owner's current image,
+// nil if the owner has none. Weak tags never match, as writes need strong comparison.
+func (p Precondition) Allows(current *Image) bool {
+	if len(p.IfMatch) > 0 && !matchesETag(p.IfMatch, current) {
+		return false
+	}
+	if len(p.IfNoneMatch) > 0 && matchesETag(p.IfNoneMatch, current) {
+		return false
+	}
+	return true
+}
+
+// matchesETag reports whether any of the tags matches the current image
+func matchesETag(tags []string, current *Image) bool {
+	if current == nil {
+		return false
+	}
+	for _, tag := range tags {
+		if tag == "*" || tag == current.ETag() {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxAltTextLength is the maximum length of an image's alternative text, in characters
+const MaxAltTextLength = 500
+
+// Crop is a rectangle, in pixels of the uploaded image, to keep before resizing
+type Crop struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// UploadOptions holds optional metadata supplied alongside an uploaded image
+type UploadOptions struct {
+	AltText      string
+	Crop         *Crop        // nil keeps the whole image
+	Precondition Precondition // Checked against the owner's current image before it is replaced
+}
+
+// Direct-to-storage upload methods
+const (
+	UploadMethodPut  = "PUT"  // Raw body PUT to a presigned URL
+	UploadMethodPost = "POST" // Browser form POST with a signed policy
+)
+
+// UploadTicket authorizes a client to upload one image directly to storage.
+// The upload only becomes an Image once it is finalized.
+type UploadTicket struct {
+	UploadGUID   uuid.UUID         `json:"uploadGuid"`
+	TypeName     string            `json:"typeName"`
+	OwnerGUID    uuid.UUID         `json:"ownerGuid"`
+	Key          string            `json:"key"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	MaxBytes     int64             `json:"maxBytes"`
+	ContentTypes []string          `json:"contentTypes"`
+	ExpiresAt    time.Time         `json:"expiresAt"`
+}
+
+// ResumableUpload is the state of a chunked upload that is appended to over
+// several requests. It is persisted in a resumable.Store so any instance can
+// resume it.
+type ResumableUpload struct {
+	GUID      uuid.UUID  `json:"guid"`
+	UserID    string     `json:"userId"` // The authenticated user the upload belongs to
+	TypeName  string     `json:"typeName"`
+	OwnerGUID uuid.UUID  `json:"ownerGuid"`
+	Length    int64      `json:"length"`
+	Offset    int64      `json:"offset"`
+	Chunks    []string   `json:"chunks"` // Names of the stored chunks, in order
+	AltText   string     `json:"altText,omitempty"`
+	ImageGUID *uuid.UUID `json:"imageGuid,omitempty"` // Set once the upload is complete and processed
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	Version   int64      `json:"version"` // Incremented on every update, to detect concurrent appends
+}
+
+// IsComplete reports whether all bytes of the upload have been received
+func (u *ResumableUpload) IsComplete() bool {
+	return u.Offset == u.Length
 }
 
 // UserImage is a specialized view of Image for user images
 type UserImage struct {
-	UserGUID  uuid.UUID `json:"userGuid"`
-	ImageGUID uuid.UUID `json:"imageGuid"`
-	SmallURL  string    `json:"smallUrl"`
-	MediumURL string    `json:"mediumUrl"`
-	LargeURL  string    `json:"largeUrl"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	UserGUID      uuid.UUID `json:"userGuid"`
+	ImageGUID     uuid.UUID `json:"imageGuid"`
+	SmallURL      string    `json:"smallUrl"`
+	MediumURL     string    `json:"mediumUrl"`
+	LargeURL      string    `json:"largeUrl"`
+	AltText       string    `json:"altText,omitempty"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	IsPlaceholder bool      `json:"isPlaceholder,omitempty"`
 }
 
 // OrganizationImage is a specialized view of Image for organization images
@@ -58,9 +396,23 @@ type OrganizationImage struct {
 	SmallURL         string    `json:"smallUrl"`
 	MediumURL        string    `json:"mediumUrl"`
 	LargeURL         string    `json:"largeUrl"`
+	AltText          string    `json:"altText,omitempty"`
 	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
+// ProductImage is a specialized view of Image for product gallery images
+type ProductImage struct {
+	ProductGUID uuid.UUID `json:"productGuid"`
+	ImageGUID   uuid.UUID `json:"imageGuid"`
+	Position    int       `json:"position"`
+	IsPrimary   bool      `json:"isPrimary"`
+	SmallURL    string    `json:"smallUrl"`
+	MediumURL   string    `json:"mediumUrl"`
+	LargeURL    string    `json:"largeUrl"`
+	AltText     string    `json:"altText,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
 // NewImage creates a new Image instance with generated GUID and timestamps
 func NewImage(ownerGUID uuid.UUID, typeName string) *Image {
 	now := time.Now().UTC()
@@ -76,12 +428,14 @@ func NewImage(ownerGUID uuid.UUID, typeName string) *Image {
 // ToUserImage converts an Image to a UserImage view
 func (i *Image) ToUserImage() *UserImage {
 	return &UserImage{
-		UserGUID:  i.OwnerGUID,
-		ImageGUID: i.GUID,
-		SmallURL:  i.SmallURL,
-		MediumURL: i.MediumURL,
-		LargeURL:  i.LargeURL,
-		UpdatedAt: i.UpdatedAt,
+		UserGUID:      i.OwnerGUID,
+		ImageGUID:     i.GUID,
+		SmallURL:      i.SmallURL,
+		MediumURL:     i.MediumURL,
+		LargeURL:      i.LargeURL,
+		AltText:       i.AltText,
+		UpdatedAt:     i.UpdatedAt,
+		IsPlaceholder: i.IsPlaceholder,
 	}
 }
 
@@ -93,10 +447,26 @@ func (i *Image) ToOrganizationImage() *OrganizationImage {
 		SmallURL:         i.SmallURL,
 		MediumURL:        i.MediumURL,
 		LargeURL:         i.LargeURL,
+		AltText:          i.AltText,
 		UpdatedAt:        i.UpdatedAt,
 	}
 }
 
+// ToProductImage converts an Image to a ProductImage view
+func (i *Image) ToProductImage() *ProductImage {
+	return &ProductImage{
+		ProductGUID: i.OwnerGUID,
+		ImageGUID:   i.GUID,
+		Position:    i.Position,
+		IsPrimary:   i.IsPrimary,
+		SmallURL:    i.SmallURL,
+		MediumURL:   i.MediumURL,
+		LargeURL:    i.LargeURL,
+		AltText:     i.AltText,
+		UpdatedAt:   i.UpdatedAt,
+	}
+}
+
 // GetImageTypeByName returns the ImageType with the given name from the config
 func GetImageTypeByName(config *ImageConfig, name string) (*ImageType, bool) {
 	for _, t := range config.Types {
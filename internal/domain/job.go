@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Processing job statuses
+const (
+	JobPending    = "pending"    // Queued, waiting for a worker
+	JobProcessing = "processing" // A worker is generating the variants
+	JobReady      = "ready"      // The image is stored; ImageGUID is set
+	JobFailed     = "failed"     // Processing failed; Error says why
+)
+
+// Job is an upload processed in the background. The original is kept in
+// storage until a worker has processed it through the regular upload pipeline.
+type Job struct {
+	GUID      uuid.UUID  `json:"guid"`
+	UserID    string     `json:"userId"` // The authenticated user the job belongs to
+	TypeName  string     `json:"typeName"`
+	OwnerGUID uuid.UUID  `json:"ownerGuid"`
+	Status    string     `json:"status"`
+	Options   JobOptions `json:"options"`
+	ImageGUID *uuid.UUID `json:"imageGuid,omitempty"` // Set once the job is ready
+	Error     string     `json:"error,omitempty"`     // Set once the job has failed
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// JobOptions are the upload options a job is processed with
+type JobOptions struct {
+	AltText     string   `json:"altText,omitempty"`
+	Crop        *Crop    `json:"crop,omitempty"`
+	IfMatch     []string `json:"ifMatch,omitempty"`
+	IfNoneMatch []string `json:"ifNoneMatch,omitempty"`
+}
+
+// NewJobOptions captures upload options for a job
+func NewJobOptions(opts UploadOptions) JobOptions {
+	return JobOptions{
+		AltText:     opts.AltText,
+		Crop:        opts.Crop,
+		IfMatch:     opts.Precondition.IfMatch,
+		IfNoneMatch: opts.Precondition.IfNoneMatch,
+	}
+}
+
+// UploadOptions returns the upload options the job was submitted with
+func (o JobOptions) UploadOptions() UploadOptions {
+	return UploadOptions{
+		AltText: o.AltText,
+		Crop:    o.Crop,
+		Precondition: Precondition{
+			IfMatch:     o.IfMatch,
+			IfNoneMatch: o.IfNoneMatch,
+		},
+	}
+}
+
+// IsFinished reports whether the job has reached a final status
+func (j *Job) IsFinished() bool {
+	return j.Status == JobReady || j.Status == JobFailed
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render fit modes deciding how an image is fitted into the requested box
+const (
+	FitContain = "contain" // Scale down to fit inside the box, preserving aspect ratio (default)
+	FitCover   = "cover"   // Scale to cover the box, preserving aspect ratio, and crop the overflow centrally
+	FitFill    = "fill"    // Stretch to exactly the box
+)
+
+// Render output formats
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// Render limits and defaults
+const (
+	MaxRenderDimension   = 4096 // Largest width or height that may be requested
+	DefaultRenderQuality = 85   // JPEG quality used when none is requested
+)
+
+// RenderOptions describes an on-the-fly rendition of a stored image.
+// A zero Width or Height is derived from the other one and the source
+// aspect ratio; if both are zero the source size is kept.
+type RenderOptions struct {
+	Width   int
+	Height  int
+	Fit     string // One of the Fit* modes
+	Format  string // One of the Format* values
+	Quality int    // JPEG quality, 1-100
+}
+
+// ContentType returns the MIME type of the rendered output
+func (o RenderOptions) ContentType() string {
+	if o.Format == FormatPNG {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// String returns the canonical form of the options; equal renditions have equal
+// strings. Unset dimensions are left out.
+func (o RenderOptions) String() string {
+	var b strings.Builder
+	if o.Width > 0 {
+		fmt.Fprintf(&b, "w:%d,", o.Width)
+	}
+	if o.Height > 0 {
+		fmt.Fprintf(&b, "h:%d,", o.Height)
+	}
+	fmt.Fprintf(&b, "fit:%s,f:%s,q:%d", o.Fit, o.Format, o.Quality)
+	return b.String()
+}
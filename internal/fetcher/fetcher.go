@@ -0,0 +1,252 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Common fetcher errors
+var (
+	ErrInvalidURL     = errors.New("invalid source URL")
+	ErrBlockedAddress = errors.New("source address is not allowed")
+	ErrTooManyHops    = errors.New("too many redirects")
+	ErrTooLarge       = errors.New("remote image too large")
+	ErrBadResponse    = errors.New("unexpected response from source")
+)
+
+// blockedNetworks lists the address ranges a fetch may never connect to unless
+// explicitly allowed: loopback, private, link-local (including cloud metadata
+// endpoints), carrier-grade NAT, multicast and other reserved ranges.
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// Config holds the limits applied to remote fetches
+type Config struct {
+	Timeout      time.Duration // Overall time limit for a fetch, including redirects
+	MaxRedirects int           // Maximum number of redirects to follow
+	MaxBytes     int64         // Maximum response body size
+
+	// AllowedNetworks are exempt from the blocked ranges, e.g. an internal
+	// asset host or, in tests, the loopback address of an httptest server
+	AllowedNetworks []*net.IPNet
+}
+
+// DefaultConfig returns conservative limits for fetching user-supplied URLs
+func DefaultConfig() Config {
+	return Config{
+		Timeout:      10 * time.Second,
+		MaxRedirects: 3,
+		MaxBytes:     15 * 1024 * 1024,
+	}
+}
+
+// ParseNetworks parses a list of CIDRs or single IP addresses
+func ParseNetworks(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", value, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Fetcher downloads images from user-supplied URLs with a hardened HTTP client
+type Fetcher struct {
+	client *http.Client
+	config Config
+}
+
+// New creates a new Fetcher
+func New(config Config) *Fetcher {
+	f := &Fetcher{config: config}
+
+	// Addresses are checked in the dialer's Control hook, i.e. after DNS
+	// resolution and for every connection, so a hostname cannot resolve to a
+	// blocked address and redirects are checked the same way as the first hop
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: f.checkConnection,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // Never route user-supplied URLs through a proxy
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.Timeout,
+		ResponseHeaderTimeout: config.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return ErrTooManyHops
+			}
+			return validateURL(req.URL)
+		},
+	}
+
+	return f
+}
+
+// Fetch downloads the resource at rawURL and returns its body
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	sourceURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := validateURL(sourceURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("Accept", "image/jpeg, image/png")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// Surface our own sentinel errors through the url.Error wrapper
+		for _, sentinel := range []error{ErrBlockedAddress, ErrTooManyHops, ErrInvalidURL} {
+			if errors.Is(err, sentinel) {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("%w: %v", ErrBadResponse, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			_ = err // Acknowledge the error to satisfy linter
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrBadResponse, resp.StatusCode)
+	}
+	if f.config.MaxBytes > 0 && resp.ContentLength > f.config.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	// Never trust Content-Length alone; stop reading one byte past the limit
+	reader := io.Reader(resp.Body)
+	if f.config.MaxBytes > 0 {
+		reader = io.LimitReader(resp.Body, f.config.MaxBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadResponse, err)
+	}
+	if f.config.MaxBytes > 0 && int64(len(data)) > f.config.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	return data, nil
+}
+
+// checkConnection rejects connections to blocked addresses that are not explicitly allowed
+func (f *Fetcher) checkConnection(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+
+	if !f.isAllowed(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+
+	return nil
+}
+
+// isAllowed reports whether the fetcher may connect to ip
+func (f *Fetcher) isAllowed(ip net.IP) bool {
+	for _, network := range f.config.AllowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	// Check IPv4-mapped IPv6 addresses against the IPv4 ranges
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateURL checks that a URL is an absolute http(s) URL without credentials
+func validateURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidURL)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: host is required", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	return nil
+}
+
+// mustParseCIDRs parses a fixed list of CIDRs, panicking on error
+func mustParseCIDRs(values ...string) []*net.IPNet {
+	networks, err := ParseNetworks(values)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
@@ -0,0 +1,71 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	imagev1 "github.com/antonrybalko/image-service-go/proto/image/v1"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toImage converts an image to its protobuf message
+func toImage(image *domain.Image) *imagev1.Image {
+	return &imagev1.Image{
+		TypeName:      image.TypeName,
+		OwnerGuid:     image.OwnerGUID.String(),
+		ImageGuid:     image.GUID.String(),
+		SmallUrl:      image.SmallURL,
+		MediumUrl:     image.MediumURL,
+		LargeUrl:      image.LargeURL,
+		AltText:       image.AltText,
+		ContentType:   image.ContentType,
+		CreatedAt:     toTimestamp(image.CreatedAt),
+		UpdatedAt:     toTimestamp(image.UpdatedAt),
+		Position:      int32(image.Position),
+		IsPrimary:     image.IsPrimary,
+		IsPlaceholder: image.IsPlaceholder,
+		Etag:          image.ETag(),
+	}
+}
+
+// toTimestamp converts a time, leaving zero times unset
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// toCrop converts an optional crop region
+func toCrop(crop *imagev1.Crop) *domain.Crop {
+	if crop == nil {
+		return nil
+	}
+	return &domain.Crop{
+		X:      int(crop.GetX()),
+		Y:      int(crop.GetY()),
+		Width:  int(crop.GetWidth()),
+		Height: int(crop.GetHeight()),
+	}
+}
+
+// toPrecondition converts an optional write precondition
+func toPrecondition(precondition *imagev1.Precondition) domain.Precondition {
+	return domain.Precondition{
+		IfMatch:     precondition.GetIfMatch(),
+		IfNoneMatch: precondition.GetIfNoneMatch(),
+	}
+}
+
+// parseGUID parses a GUID request field
+func parseGUID(field, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, invalidField(field, field+" is required")
+	}
+	guid, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, invalidField(field, field+" is not a valid UUID")
+	}
+	return guid, nil
+}
@@ -0,0 +1,83 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serviceErrorCodes maps the image service's sentinel errors to status codes,
+// following the HTTP statuses the REST API uses for them
+var serviceErrorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{service.ErrInvalidImage, codes.InvalidArgument},
+	{service.ErrImageTooLarge, codes.ResourceExhausted},
+	{service.ErrUnsupportedType, codes.InvalidArgument},
+	{service.ErrProcessingFailed, codes.InvalidArgument},
+	{service.ErrStorageFailed, codes.Internal},
+	{service.ErrNotFound, codes.NotFound},
+	{service.ErrUnauthorized, codes.PermissionDenied},
+	{service.ErrImageLimit, codes.FailedPrecondition},
+	{service.ErrInvalidOrder, codes.InvalidArgument},
+	{service.ErrUnknownType, codes.NotFound},
+	{service.ErrUnknownSize, codes.NotFound},
+	{service.ErrInvalidOptions, codes.InvalidArgument},
+	{service.ErrInvalidSource, codes.InvalidArgument},
+	{service.ErrSourceFailed, codes.Unavailable},
+	{service.ErrOffsetMismatch, codes.FailedPrecondition},
+	{service.ErrUploadExpired, codes.NotFound},
+	{service.ErrBatchTooLarge, codes.InvalidArgument},
+	{service.ErrInvalidCursor, codes.InvalidArgument},
+	{service.ErrPreconditionFailed, codes.FailedPrecondition},
+	{service.ErrConcurrentUpdate, codes.Aborted},
+}
+
+// toStatus converts an image service error to a gRPC status error. Invalid
+// arguments keep the full error message; anything else reports only the
+// sentinel's, so storage and database details don't leak to callers.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var fieldErr *service.FieldError
+	if errors.As(err, &fieldErr) {
+		return invalidField(fieldErr.Field, err.Error())
+	}
+
+	for _, mapping := range serviceErrorCodes {
+		if errors.Is(err, mapping.err) {
+			message := mapping.err.Error()
+			if mapping.code == codes.InvalidArgument {
+				message = err.Error()
+			}
+			return status.Error(mapping.code, message)
+		}
+	}
+	return status.Error(codes.Internal, "an unexpected error occurred")
+}
+
+// invalidField returns an InvalidArgument error naming the offending request field
+func invalidField(field, message string) error {
+	st := status.New(codes.InvalidArgument, message)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: message}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,271 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	imagev1 "github.com/antonrybalko/image-service-go/proto/image/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UploadImage stores the image sent in chunks after the metadata message.
+// The chunks are streamed into the image service, which enforces the type's
+// size limit while reading.
+func (s *Server) UploadImage(stream imagev1.ImageService_UploadImageServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "stream has no metadata message")
+	}
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	if meta == nil {
+		return status.Error(codes.InvalidArgument, "the first message must carry the metadata")
+	}
+
+	imageType, err := s.imageType(meta.GetTypeName())
+	if err != nil {
+		return err
+	}
+	ownerGUID, err := parseGUID("metadata.owner_guid", meta.GetOwnerGuid())
+	if err != nil {
+		return err
+	}
+	if err := authorizeOwnerWrite(ctx, imageType, ownerGUID); err != nil {
+		return err
+	}
+
+	opts := domain.UploadOptions{
+		AltText:      strings.TrimSpace(meta.GetAltText()),
+		Crop:         toCrop(meta.GetCrop()),
+		Precondition: toPrecondition(meta.GetPrecondition()),
+	}
+	chunks := &chunkReader{stream: stream}
+	image, err := s.imageService.UploadImage(ctx, imageType.Name, ownerGUID, chunks, opts)
+	if err != nil {
+		// Report a broken stream rather than the unreadable image it caused
+		if chunks.err != nil {
+			return chunks.err
+		}
+		return toStatus(err)
+	}
+
+	return stream.SendAndClose(toImage(image))
+}
+
+// GetImage returns an owner's image, or its placeholder if the type has one
+func (s *Server) GetImage(ctx context.Context, req *imagev1.GetImageRequest) (*imagev1.Image, error) {
+	imageType, err := s.imageType(req.GetTypeName())
+	if err != nil {
+		return nil, err
+	}
+	ownerGUID, err := parseGUID("owner_guid", req.GetOwnerGuid())
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := s.imageService.GetImageOrPlaceholder(ctx, imageType.Name, ownerGUID, req.GetDisplayName())
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "owner has no %s image", imageType.Name)
+		}
+		return nil, toStatus(err)
+	}
+	return toImage(image), nil
+}
+
+// BatchGetImages looks up the images of many owners with a single query.
+// Like POST /v1/images:batchGet, every requested owner has an entry.
+func (s *Server) BatchGetImages(ctx context.Context, req *imagev1.BatchGetImagesRequest) (*imagev1.BatchGetImagesResponse, error) {
+	imageType, err := s.imageType(req.GetTypeName())
+	if err != nil {
+		return nil, err
+	}
+	ownerGUIDs := make([]uuid.UUID, 0, len(req.GetOwnerGuids()))
+	for _, owner := range req.GetOwnerGuids() {
+		ownerGUID, err := parseGUID("owner_guids", owner)
+		if err != nil {
+			return nil, err
+		}
+		ownerGUIDs = append(ownerGUIDs, ownerGUID)
+	}
+
+	images, err := s.imageService.GetImagesByOwners(ctx, imageType.Name, ownerGUIDs)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &imagev1.BatchGetImagesResponse{
+		TypeName: imageType.Name,
+		Images:   make(map[string]*imagev1.Image, len(images)),
+	}
+	for ownerGUID, image := range images {
+		if image == nil {
+			// Message map values can't be null, so an owner without an image
+			// maps to an image naming only the owner
+			response.Images[ownerGUID.String()] = &imagev1.Image{TypeName: imageType.Name, OwnerGuid: ownerGUID.String()}
+			continue
+		}
+		response.Images[ownerGUID.String()] = toImage(image)
+	}
+	return response, nil
+}
+
+// DeleteImage removes an owner's image, following the type's ownership rule
+func (s *Server) DeleteImage(ctx context.Context, req *imagev1.DeleteImageRequest) (*imagev1.DeleteImageResponse, error) {
+	imageType, err := s.imageType(req.GetTypeName())
+	if err != nil {
+		return nil, err
+	}
+	ownerGUID, err := parseGUID("owner_guid", req.GetOwnerGuid())
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOwnerWrite(ctx, imageType, ownerGUID); err != nil {
+		return nil, err
+	}
+
+	err = s.imageService.DeleteImageIf(ctx, imageType.Name, ownerGUID, toPrecondition(req.GetPrecondition()))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "owner has no %s image to delete", imageType.Name)
+		}
+		return nil, toStatus(err)
+	}
+	return &imagev1.DeleteImageResponse{}, nil
+}
+
+// ListImages pages through all images; callers need the service admin role
+func (s *Server) ListImages(ctx context.Context, req *imagev1.ListImagesRequest) (*imagev1.ListImagesResponse, error) {
+	if !auth.IsServiceAdmin(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "caller is not a service admin")
+	}
+
+	filter := repository.ImageFilter{
+		TypeName:    req.GetTypeName(),
+		ContentType: req.GetContentType(),
+	}
+	if owner := req.GetOwnerGuid(); owner != "" {
+		ownerGUID, err := parseGUID("owner_guid", owner)
+		if err != nil {
+			return nil, err
+		}
+		filter.OwnerGUID = ownerGUID
+	}
+	for _, bound := range []struct {
+		field string
+		value *timestamppb.Timestamp
+		dst   *time.Time
+	}{
+		{"created_from", req.GetCreatedFrom(), &filter.CreatedFrom},
+		{"created_to", req.GetCreatedTo(), &filter.CreatedTo},
+		{"updated_from", req.GetUpdatedFrom(), &filter.UpdatedFrom},
+		{"updated_to", req.GetUpdatedTo(), &filter.UpdatedTo},
+	} {
+		if bound.value == nil {
+			continue
+		}
+		if err := bound.value.CheckValid(); err != nil {
+			return nil, invalidField(bound.field, bound.field+" is not a valid timestamp")
+		}
+		*bound.dst = bound.value.AsTime()
+	}
+	if req.GetPageSize() < 0 {
+		return nil, invalidField("page_size", "page_size must not be negative")
+	}
+
+	list, err := s.imageService.ListImages(ctx, filter, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &imagev1.ListImagesResponse{
+		Images:        make([]*imagev1.Image, 0, len(list.Images)),
+		NextPageToken: list.NextCursor,
+	}
+	for _, image := range list.Images {
+		response.Images = append(response.Images, toImage(image))
+	}
+	return response, nil
+}
+
+// imageType looks up a configured image type by name
+func (s *Server) imageType(typeName string) (*domain.ImageType, error) {
+	if typeName == "" {
+		return nil, invalidField("type_name", "type_name is required")
+	}
+	imageType, err := s.imageService.ImageType(typeName)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return imageType, nil
+}
+
+// authorizeOwnerWrite enforces the type's ownership rule for the caller
+func authorizeOwnerWrite(ctx context.Context, imageType *domain.ImageType, ownerGUID uuid.UUID) error {
+	userID, ok := auth.GetUserIDFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "invalid or missing authentication")
+	}
+
+	switch imageType.OwnershipRule() {
+	case domain.OwnershipAuthenticated:
+		return nil
+	case domain.OwnershipOrganizationAdmin:
+		if auth.IsOrganizationAdmin(ctx, ownerGUID.String()) {
+			return nil
+		}
+		return status.Error(codes.PermissionDenied, "caller is not an admin of this organization")
+	default:
+		if strings.EqualFold(userID, ownerGUID.String()) {
+			return nil
+		}
+		return status.Error(codes.PermissionDenied, "caller does not own this image")
+	}
+}
+
+// chunkReader reads the image chunks following an upload's metadata message
+type chunkReader struct {
+	stream imagev1.ImageService_UploadImageServer
+	buf    []byte
+	err    error // Stream or protocol error that stopped reading
+}
+
+// Read implements io.Reader
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		msg, err := c.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		if err != nil {
+			c.err = err
+			return 0, err
+		}
+		if msg.GetMetadata() != nil {
+			c.err = status.Error(codes.InvalidArgument, "only the first message may carry metadata")
+			return 0, c.err
+		}
+		c.buf = msg.GetChunk()
+	}
+
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
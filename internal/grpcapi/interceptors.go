@@ -0,0 +1,111 @@
+package grpcapi
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	imagev1 "github.com/antonrybalko/image-service-go/proto/image/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without a token, like their HTTP counterparts
+var publicMethods = map[string]bool{
+	imagev1.ImageService_GetImage_FullMethodName:       true,
+	imagev1.ImageService_BatchGetImages_FullMethodName: true,
+}
+
+// authenticate validates the bearer token in the call's "authorization"
+// metadata and returns a context carrying the caller's identity, as the HTTP
+// JWT middleware does. Public methods skip authentication.
+func authenticate(ctx context.Context, fullMethod string, config auth.JWTConfig) (context.Context, error) {
+	if publicMethods[fullMethod] {
+		return ctx, nil
+	}
+
+	var tokenString string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			tokenString = auth.ParseBearerToken(values[0])
+		}
+	}
+	if tokenString == "" {
+		return nil, status.Error(codes.Unauthenticated, "no token provided")
+	}
+
+	ctx, err := auth.Authenticate(ctx, tokenString, config)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return ctx, nil
+}
+
+// authenticateUnary authenticates unary calls
+func authenticateUnary(config auth.JWTConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, info.FullMethod, config)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticateStream authenticates streaming calls
+func authenticateStream(config auth.JWTConfig) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), info.FullMethod, config)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// contextStream is a server stream with a replaced context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// recoverUnary turns a panicking unary handler into an Internal error
+func recoverUnary(logger *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				err = recovered(logger, info.FullMethod, rvr)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// recoverStream turns a panicking stream handler into an Internal error
+func recoverStream(logger *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				err = recovered(logger, info.FullMethod, rvr)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// recovered logs a recovered panic and returns the error reported for it
+func recovered(logger *zap.SugaredLogger, fullMethod string, rvr any) error {
+	logger.Errorw("Panic while handling gRPC call",
+		"panic", rvr,
+		"method", fullMethod,
+		"stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "an unexpected error occurred")
+}
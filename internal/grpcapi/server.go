@@ -0,0 +1,41 @@
+// Package grpcapi serves the image API over gRPC for backend services. It
+// mirrors the HTTP endpoints on top of the same image service, with the same
+// authentication, ownership rules and error semantics.
+package grpcapi
+
+import (
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	imagev1 "github.com/antonrybalko/image-service-go/proto/image/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// Server implements imagev1.ImageServiceServer
+type Server struct {
+	imagev1.UnimplementedImageServiceServer
+
+	logger       *zap.SugaredLogger
+	imageService *service.ImageService
+}
+
+// NewServer creates a gRPC server exposing the image service, with panic
+// recovery and JWT authentication on every call
+func NewServer(logger *zap.SugaredLogger, cfg *config.Config, imageService *service.ImageService) *grpc.Server {
+	jwtConfig := auth.JWTConfig{
+		PublicKeyURL: cfg.JWT.PublicKeyURL,
+		Secret:       cfg.JWT.Secret,
+		Algorithm:    cfg.JWT.Algorithm,
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoverUnary(logger), authenticateUnary(jwtConfig)),
+		grpc.ChainStreamInterceptor(recoverStream(logger), authenticateStream(jwtConfig)),
+	)
+	imagev1.RegisterImageServiceServer(server, &Server{
+		logger:       logger,
+		imageService: imageService,
+	})
+	return server
+}
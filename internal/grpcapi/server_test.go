@@ -0,0 +1,243 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/auth"
+	"github.com/antonrybalko/image-service-go/internal/config"
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/service"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	imagev1 "github.com/antonrybalko/image-service-go/proto/image/v1"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testJWTSecret = "test-secret"
+
+// newTestClient serves the image service over an in-memory connection
+func newTestClient(t *testing.T) imagev1.ImageServiceClient {
+	imageConfig := &domain.ImageConfig{
+		Types: []domain.ImageType{
+			{
+				Name:      "user",
+				Ownership: domain.OwnershipSelf,
+				Sizes: domain.SizeSet{
+					"small":  {Width: 50, Height: 50},
+					"medium": {Width: 100, Height: 100},
+					"large":  {Width: 800, Height: 800},
+				},
+			},
+		},
+	}
+	imageService := service.NewImageService(
+		repository.NewMockImageRepository(),
+		storage.NewMockS3(),
+		processor.NewMockProcessor(),
+		imageConfig,
+		zap.NewNop().Sugar(),
+	)
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.Algorithm = "HS256"
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(zap.NewNop().Sugar(), cfg, imageService)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return imagev1.NewImageServiceClient(conn)
+}
+
+// withToken returns a context sending an HS256 token for the subject
+func withToken(t *testing.T, subject string, roles ...string) context.Context {
+	claims := auth.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+		Roles:            roles,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// upload streams an image in small chunks
+func upload(ctx context.Context, client imagev1.ImageServiceClient, meta *imagev1.UploadImageMetadata, data []byte) (*imagev1.Image, error) {
+	stream, err := client.UploadImage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&imagev1.UploadImageRequest{Data: &imagev1.UploadImageRequest_Metadata{Metadata: meta}}); err != nil {
+		return nil, err
+	}
+	for len(data) > 0 {
+		n := min(len(data), 7)
+		if err := stream.Send(&imagev1.UploadImageRequest{Data: &imagev1.UploadImageRequest_Chunk{Chunk: data[:n]}}); err != nil {
+			return nil, err
+		}
+		data = data[n:]
+	}
+	return stream.CloseAndRecv()
+}
+
+func TestImageService_UploadGetDelete(t *testing.T) {
+	client := newTestClient(t)
+	userGUID := uuid.New()
+	ctx := withToken(t, userGUID.String())
+	meta := &imagev1.UploadImageMetadata{TypeName: "user", OwnerGuid: userGUID.String(), AltText: " Avatar "}
+
+	// Uploading needs a token
+	_, err := upload(context.Background(), client, meta, []byte("mock-grpc-image-data"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Only the owner may upload
+	_, err = upload(withToken(t, uuid.NewString()), client, meta, []byte("mock-grpc-image-data"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	uploaded, err := upload(ctx, client, meta, []byte("mock-grpc-image-data"))
+	require.NoError(t, err)
+	assert.Equal(t, userGUID.String(), uploaded.OwnerGuid)
+	assert.Equal(t, "Avatar", uploaded.AltText)
+	assert.NotEmpty(t, uploaded.Etag)
+	assert.NotNil(t, uploaded.UpdatedAt)
+
+	// Lookups are public
+	fetched, err := client.GetImage(context.Background(), &imagev1.GetImageRequest{TypeName: "user", OwnerGuid: userGUID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, uploaded.ImageGuid, fetched.ImageGuid)
+
+	withoutImage := uuid.NewString()
+	batch, err := client.BatchGetImages(context.Background(), &imagev1.BatchGetImagesRequest{
+		TypeName:   "user",
+		OwnerGuids: []string{userGUID.String(), withoutImage},
+	})
+	require.NoError(t, err)
+	require.Len(t, batch.Images, 2)
+	assert.Equal(t, uploaded.ImageGuid, batch.Images[userGUID.String()].ImageGuid)
+
+	// Owners without an image have an entry without one
+	require.NotNil(t, batch.Images[withoutImage])
+	assert.Equal(t, withoutImage, batch.Images[withoutImage].OwnerGuid)
+	assert.Empty(t, batch.Images[withoutImage].ImageGuid)
+	assert.Empty(t, batch.Images[withoutImage].LargeUrl)
+
+	// A stale entity tag fails the precondition
+	_, err = client.DeleteImage(ctx, &imagev1.DeleteImageRequest{
+		TypeName:     "user",
+		OwnerGuid:    userGUID.String(),
+		Precondition: &imagev1.Precondition{IfMatch: []string{`"stale"`}},
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.DeleteImage(ctx, &imagev1.DeleteImageRequest{
+		TypeName:     "user",
+		OwnerGuid:    userGUID.String(),
+		Precondition: &imagev1.Precondition{IfMatch: []string{uploaded.Etag}},
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetImage(context.Background(), &imagev1.GetImageRequest{TypeName: "user", OwnerGuid: userGUID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestImageService_Errors(t *testing.T) {
+	client := newTestClient(t)
+	userGUID := uuid.New()
+	ctx := withToken(t, userGUID.String())
+
+	// Unknown types map to NotFound
+	_, err := client.GetImage(ctx, &imagev1.GetImageRequest{TypeName: "banner", OwnerGuid: userGUID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Invalid fields are named in the error details
+	_, err = client.GetImage(ctx, &imagev1.GetImageRequest{TypeName: "user", OwnerGuid: "not-a-uuid"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	assert.Equal(t, "owner_guid", badRequest.FieldViolations[0].Field)
+
+	// Uploads must start with the metadata
+	stream, err := client.UploadImage(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&imagev1.UploadImageRequest{Data: &imagev1.UploadImageRequest_Chunk{Chunk: []byte("data")}}))
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Invalid tokens are rejected
+	badCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-token")
+	_, err = client.DeleteImage(badCtx, &imagev1.DeleteImageRequest{TypeName: "user", OwnerGuid: userGUID.String()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestImageService_ListImages(t *testing.T) {
+	client := newTestClient(t)
+	userGUID := uuid.New()
+	ctx := withToken(t, userGUID.String())
+
+	_, err := upload(ctx, client, &imagev1.UploadImageMetadata{TypeName: "user", OwnerGuid: userGUID.String()}, []byte("mock-grpc-image-data"))
+	require.NoError(t, err)
+
+	// Listing needs the service admin role
+	_, err = client.ListImages(ctx, &imagev1.ListImagesRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	list, err := client.ListImages(withToken(t, uuid.NewString(), auth.RoleAdmin), &imagev1.ListImagesRequest{TypeName: "user"})
+	require.NoError(t, err)
+	require.Len(t, list.Images, 1)
+	assert.Equal(t, userGUID.String(), list.Images[0].OwnerGuid)
+	assert.Empty(t, list.NextPageToken)
+
+	_, err = client.ListImages(withToken(t, uuid.NewString(), auth.RoleAdmin), &imagev1.ListImagesRequest{PageToken: "garbage"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"Not found", service.ErrNotFound, codes.NotFound},
+		{"Wrapped invalid image", service.ErrInvalidImage, codes.InvalidArgument},
+		{"Too large", service.ErrImageTooLarge, codes.ResourceExhausted},
+		{"Concurrent update", service.ErrConcurrentUpdate, codes.Aborted},
+		{"Precondition", service.ErrPreconditionFailed, codes.FailedPrecondition},
+		{"Canceled", context.Canceled, codes.Canceled},
+		{"Unknown", assert.AnError, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, status.Code(toStatus(tt.err)))
+		})
+	}
+
+	// Internal errors don't leak their cause
+	assert.NotContains(t, status.Convert(toStatus(assert.AnError)).Message(), assert.AnError.Error())
+}
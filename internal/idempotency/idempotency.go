@@ -0,0 +1,86 @@
+// Package idempotency stores the responses of write requests sent with an
+// Idempotency-Key header, so that retries of a request replay its original
+// response instead of performing the write again.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HeaderName is the request header carrying the client's idempotency key
+const HeaderName = "Idempotency-Key"
+
+// ReplayedHeader marks responses replayed from a stored record
+const ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength is the longest accepted idempotency key
+const MaxKeyLength = 255
+
+// ErrNotReserved is returned when completing a key whose reservation has
+// expired and been taken over, or that was completed already
+var ErrNotReserved = errors.New("idempotency key is not reserved by this request")
+
+// Record is an idempotency key claimed by a user, with the response of the
+// request that claimed it once that request has completed
+type Record struct {
+	UserID      string
+	Key         string
+	Token       string // Identifies the reservation; only its holder may extend, complete or release it
+	RequestHash string // Hex SHA-256 of the request; empty while in progress
+	StatusCode  int    // Zero while in progress
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the record holds a stored response
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Store persists idempotency records. Records past their expiry are treated
+// as absent, so an abandoned in-progress key becomes usable again.
+type Store interface {
+	// Reserve claims the record's key for its user. If a live record already
+	// holds the key, that record is returned and nothing is stored; otherwise
+	// the record is stored in progress and nil is returned.
+	Reserve(ctx context.Context, record *Record) (*Record, error)
+
+	// Extend moves the expiry of a key that is still in progress under the
+	// reservation token to expiresAt, so a long-running request keeps it
+	// reserved. Completed, unknown and otherwise reserved keys are left alone.
+	Extend(ctx context.Context, userID, key, token string, expiresAt time.Time) error
+
+	// Complete stores the response of a key still in progress under the
+	// record's reservation token, returning ErrNotReserved otherwise
+	Complete(ctx context.Context, record *Record) error
+
+	// Release deletes a key held under the reservation token so the request
+	// can be retried. Otherwise reserved keys are left alone.
+	Release(ctx context.Context, userID, key, token string) error
+
+	// DeleteExpired removes records that expired before now
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// RunCleanup deletes expired records every interval until ctx is done
+func RunCleanup(ctx context.Context, store Store, interval time.Duration, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := store.DeleteExpired(ctx, now); err != nil {
+				logger.Errorw("Failed to delete expired idempotency keys", "error", err)
+			}
+		}
+	}
+}
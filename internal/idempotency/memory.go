@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore implements Store in memory, for development and tests
+type MemoryStore struct {
+	mutex   sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryStore creates a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+	}
+}
+
+// Reserve claims the record's key unless a live record already holds it
+func (m *MemoryStore) Reserve(ctx context.Context, record *Record) (*Record, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	id := recordID(record.UserID, record.Key)
+	if existing, exists := m.records[id]; exists && time.Now().Before(existing.ExpiresAt) {
+		return copyRecord(existing), nil
+	}
+
+	m.records[id] = copyRecord(record)
+	return nil, nil
+}
+
+// Extend moves the expiry of a key in progress under the reservation token
+func (m *MemoryStore) Extend(ctx context.Context, userID, key, token string, expiresAt time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if record, exists := m.records[recordID(userID, key)]; exists && record.Token == token && !record.Completed() {
+		record.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+// Complete stores the response of a key in progress under the record's
+// reservation token
+func (m *MemoryStore) Complete(ctx context.Context, record *Record) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	id := recordID(record.UserID, record.Key)
+	if stored, exists := m.records[id]; !exists || stored.Token != record.Token || stored.Completed() {
+		return ErrNotReserved
+	}
+
+	m.records[id] = copyRecord(record)
+	return nil
+}
+
+// Release deletes a key held under the reservation token
+func (m *MemoryStore) Release(ctx context.Context, userID, key, token string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	id := recordID(userID, key)
+	if record, exists := m.records[id]; exists && record.Token == token {
+		delete(m.records, id)
+	}
+	return nil
+}
+
+// DeleteExpired removes records that expired before now
+func (m *MemoryStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for id, record := range m.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(m.records, id)
+		}
+	}
+	return nil
+}
+
+// recordID keys records by user and idempotency key
+func recordID(userID, key string) string {
+	return userID + "\x00" + key
+}
+
+// copyRecord returns a deep copy so callers can't modify stored records
+func copyRecord(record *Record) *Record {
+	recordCopy := *record
+	recordCopy.Header = record.Header.Clone()
+	recordCopy.Body = append([]byte(nil), record.Body...)
+	return &recordCopy
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	record := &Record{UserID: "user-1", Key: "key-1", Token: "token-1", ExpiresAt: time.Now().Add(time.Minute)}
+	existing, err := store.Reserve(ctx, record)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// A second reservation sees the in-progress record
+	existing, err = store.Reserve(ctx, &Record{UserID: "user-1", Key: "key-1", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.False(t, existing.Completed())
+
+	// Keys are scoped to the user
+	existing, err = store.Reserve(ctx, &Record{UserID: "user-2", Key: "key-1", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// A long-running request keeps its key reserved past the first expiry
+	require.NoError(t, store.Extend(ctx, "user-1", "key-1", "token-1", time.Now().Add(time.Hour)))
+	require.NoError(t, store.DeleteExpired(ctx, time.Now().Add(2*time.Minute)))
+	existing, err = store.Reserve(ctx, &Record{UserID: "user-1", Key: "key-1", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+
+	record.RequestHash = "hash"
+	record.StatusCode = http.StatusCreated
+	record.Header = http.Header{"Content-Type": {"application/json"}}
+	record.Body = []byte(`{"ok":true}`)
+	require.NoError(t, store.Complete(ctx, record))
+
+	existing, err = store.Reserve(ctx, &Record{UserID: "user-1", Key: "key-1", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.True(t, existing.Completed())
+	assert.Equal(t, "hash", existing.RequestHash)
+	assert.Equal(t, http.StatusCreated, existing.StatusCode)
+	assert.Equal(t, "application/json", existing.Header.Get("Content-Type"))
+	assert.Equal(t, `{"ok":true}`, string(existing.Body))
+
+	// Completed keys keep the TTL they were stored with
+	require.NoError(t, store.Extend(ctx, "user-1", "key-1", "token-1", time.Now().Add(time.Hour)))
+	assert.Equal(t, record.ExpiresAt, store.records[recordID("user-1", "key-1")].ExpiresAt)
+
+	// A completed key can't be completed again
+	assert.ErrorIs(t, store.Complete(ctx, record), ErrNotReserved)
+
+	// Released keys can be reserved again
+	require.NoError(t, store.Release(ctx, "user-1", "key-1", "token-1"))
+	existing, err = store.Reserve(ctx, &Record{UserID: "user-1", Key: "key-1", ExpiresAt: time.Now().Add(-time.Second)})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// So can expired ones, which DeleteExpired removes
+	existing, err = store.Reserve(ctx, &Record{UserID: "user-1", Key: "key-1", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	require.NoError(t, store.DeleteExpired(ctx, time.Now().Add(2*time.Minute)))
+	assert.Empty(t, store.records)
+}
+
+func TestMemoryStore_ReservationTakenOver(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	// A request's reservation lapses and another request reserves the key
+	stale := &Record{UserID: "user-1", Key: "key-1", Token: "token-1", ExpiresAt: time.Now().Add(-time.Second)}
+	existing, err := store.Reserve(ctx, stale)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+	current := &Record{UserID: "user-1", Key: "key-1", Token: "token-2", ExpiresAt: time.Now().Add(time.Minute)}
+	existing, err = store.Reserve(ctx, current)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+
+	// The first request can no longer touch the key
+	require.NoError(t, store.Extend(ctx, "user-1", "key-1", "token-1", time.Now().Add(time.Hour)))
+	assert.Equal(t, current.ExpiresAt, store.records[recordID("user-1", "key-1")].ExpiresAt)
+
+	stale.StatusCode = http.StatusCreated
+	assert.ErrorIs(t, store.Complete(ctx, stale), ErrNotReserved)
+	assert.False(t, store.records[recordID("user-1", "key-1")].Completed())
+
+	require.NoError(t, store.Release(ctx, "user-1", "key-1", "token-1"))
+	require.Contains(t, store.records, recordID("user-1", "key-1"))
+
+	// While the second completes it
+	current.StatusCode = http.StatusCreated
+	require.NoError(t, store.Complete(ctx, current))
+	assert.True(t, store.records[recordID("user-1", "key-1")].Completed())
+}
@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostgresStore implements Store using the idempotency_keys table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new PostgresStore
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// Reserve claims the record's key unless a live record already holds it.
+// An expired record under the same key is overwritten in the same statement,
+// so concurrent requests can't both claim a key.
+func (p *PostgresStore) Reserve(ctx context.Context, record *Record) (*Record, error) {
+	var userID string
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, token, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+			token = EXCLUDED.token,
+			request_hash = '',
+			status_code = 0,
+			headers = NULL,
+			body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING user_id`,
+		record.UserID, record.Key, record.Token, record.ExpiresAt).Scan(&userID)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	// A live record holds the key
+	existing := Record{UserID: record.UserID, Key: record.Key}
+	var headers []byte
+	err = p.db.QueryRowContext(ctx, `
+		SELECT token, request_hash, status_code, headers, body, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2`,
+		record.UserID, record.Key).Scan(
+		&existing.Token,
+		&existing.RequestHash,
+		&existing.StatusCode,
+		&headers,
+		&existing.Body,
+		&existing.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+	if headers != nil {
+		if err := json.Unmarshal(headers, &existing.Header); err != nil {
+			return nil, fmt.Errorf("failed to decode stored headers: %w", err)
+		}
+	}
+
+	return &existing, nil
+}
+
+// Extend moves the expiry of a key in progress under the reservation token
+func (p *PostgresStore) Extend(ctx context.Context, userID, key, token string, expiresAt time.Time) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET expires_at = $4
+		WHERE user_id = $1 AND idempotency_key = $2 AND token = $3 AND status_code = 0`,
+		userID, key, token, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to extend idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Complete stores the response of a key in progress under the record's
+// reservation token
+func (p *PostgresStore) Complete(ctx context.Context, record *Record) error {
+	headers, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode headers: %w", err)
+	}
+
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET request_hash = $4, status_code = $5, headers = $6, body = $7, expires_at = $8
+		WHERE user_id = $1 AND idempotency_key = $2 AND token = $3 AND status_code = 0`,
+		record.UserID, record.Key, record.Token, record.RequestHash, record.StatusCode, headers, record.Body, record.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotReserved
+	}
+
+	return nil
+}
+
+// Release deletes a key held under the reservation token
+func (p *PostgresStore) Release(ctx context.Context, userID, key, token string) error {
+	_, err := p.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2 AND token = $3`,
+		userID, key, token)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes records that expired before now
+func (p *PostgresStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package jobs persists the status of uploads processed in the background,
+// so any instance can report on a job. The work itself is dispatched through
+// the queue package, which owns attempts, leases and retries; a job here only
+// records what clients are told and shares its GUID with its queue job, so an
+// unfinished job whose queue job is dead can be failed when it is looked up.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned for unknown jobs
+	ErrNotFound = errors.New("job not found")
+
+	// ErrFinished is returned when finishing a job that already finished
+	ErrFinished = errors.New("job already finished")
+)
+
+// Store persists processing jobs
+type Store interface {
+	// Create stores a new pending job
+	Create(ctx context.Context, job *domain.Job) error
+
+	// Get returns a job by GUID
+	Get(ctx context.Context, jobGUID uuid.UUID) (*domain.Job, error)
+
+	// Claim moves a pending job, or a processing one whose earlier attempt
+	// failed, to processing at now and returns it. It returns nil if the job
+	// has finished, so a job redelivered by the queue isn't processed again.
+	Claim(ctx context.Context, jobGUID uuid.UUID, now time.Time) (*domain.Job, error)
+
+	// Finish stores the final status, image and error of an unfinished job.
+	// It returns ErrFinished, and writes nothing, if the job has finished
+	// meanwhile.
+	Finish(ctx context.Context, job *domain.Job) error
+
+	// DeleteFinished removes jobs that finished before the given time
+	DeleteFinished(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// MemoryStore implements Store in memory, for development and tests
+type MemoryStore struct {
+	mutex sync.Mutex
+	jobs  map[uuid.UUID]*domain.Job
+}
+
+// NewMemoryStore creates a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs: make(map[uuid.UUID]*domain.Job),
+	}
+}
+
+// Create stores a new pending job
+func (m *MemoryStore) Create(ctx context.Context, job *domain.Job) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.jobs[job.GUID] = copyJob(job)
+	return nil
+}
+
+// Get returns a job by GUID
+func (m *MemoryStore) Get(ctx context.Context, jobGUID uuid.UUID) (*domain.Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, exists := m.jobs[jobGUID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyJob(job), nil
+}
+
+// Claim moves an unfinished job to processing
+func (m *MemoryStore) Claim(ctx context.Context, jobGUID uuid.UUID, now time.Time) (*domain.Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, exists := m.jobs[jobGUID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	if job.IsFinished() {
+		return nil, nil
+	}
+	job.Status = domain.JobProcessing
+	job.UpdatedAt = now
+	return copyJob(job), nil
+}
+
+// Finish stores the outcome of an unfinished job
+func (m *MemoryStore) Finish(ctx context.Context, job *domain.Job) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.jobs[job.GUID]
+	if !exists {
+		return ErrNotFound
+	}
+	if stored.IsFinished() {
+		return ErrFinished
+	}
+	m.jobs[job.GUID] = copyJob(job)
+	return nil
+}
+
+// DeleteFinished removes jobs that finished before the given time
+func (m *MemoryStore) DeleteFinished(ctx context.Context, before time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for jobGUID, job := range m.jobs {
+		if job.IsFinished() && job.UpdatedAt.Before(before) {
+			delete(m.jobs, jobGUID)
+		}
+	}
+	return nil
+}
+
+// copyJob returns a deep copy so callers can't modify stored jobs
+func copyJob(job *domain.Job) *domain.Job {
+	jobCopy := *job
+	if job.ImageGUID != nil {
+		imageGUID := *job.ImageGUID
+		jobCopy.ImageGUID = &imageGUID
+	}
+	if job.Options.Crop != nil {
+		crop := *job.Options.Crop
+		jobCopy.Options.Crop = &crop
+	}
+	jobCopy.Options.IfMatch = append([]string(nil), job.Options.IfMatch...)
+	jobCopy.Options.IfNoneMatch = append([]string(nil), job.Options.IfNoneMatch...)
+	return &jobCopy
+}
@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJob(createdAt time.Time) *domain.Job {
+	return &domain.Job{
+		GUID:      uuid.New(),
+		UserID:    "user-1",
+		TypeName:  "user",
+		OwnerGUID: uuid.New(),
+		Status:    domain.JobPending,
+		Options:   domain.JobOptions{IfMatch: []string{`"etag"`}},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	_, err := store.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	older := newTestJob(now.Add(-time.Minute))
+	newer := newTestJob(now)
+	require.NoError(t, store.Create(ctx, newer))
+	require.NoError(t, store.Create(ctx, older))
+
+	// Stored jobs can't be modified through the caller's copy
+	older.Options.IfMatch[0] = "changed"
+	stored, err := store.Get(ctx, older.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"etag"`}, stored.Options.IfMatch)
+
+	claimed, err := store.Claim(ctx, older.GUID, now)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, domain.JobProcessing, claimed.Status)
+
+	// A job is claimed again while it is unfinished, as when the queue
+	// retries it
+	claimed, err = store.Claim(ctx, older.GUID, now.Add(time.Second))
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, now.Add(time.Second), claimed.UpdatedAt)
+
+	_, err = store.Claim(ctx, uuid.New(), now)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	imageGUID := uuid.New()
+	claimed.Status = domain.JobReady
+	claimed.ImageGUID = &imageGUID
+	claimed.UpdatedAt = now
+	require.NoError(t, store.Finish(ctx, claimed))
+
+	stored, err = store.Get(ctx, older.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobReady, stored.Status)
+	assert.Equal(t, imageGUID, *stored.ImageGUID)
+
+	// Finished jobs aren't claimed or finished again
+	claimed.Status = domain.JobFailed
+	assert.ErrorIs(t, store.Finish(ctx, claimed), ErrFinished)
+	stored, err = store.Get(ctx, older.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobReady, stored.Status)
+	assert.ErrorIs(t, store.Finish(ctx, newTestJob(now)), ErrNotFound)
+
+	claimed, err = store.Claim(ctx, older.GUID, now)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// Only finished jobs are deleted
+	require.NoError(t, store.DeleteFinished(ctx, now.Add(time.Second)))
+	_, err = store.Get(ctx, older.GUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Get(ctx, newer.GUID)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, store.Finish(ctx, older), ErrNotFound)
+}
@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// jobColumns lists the image_jobs columns in scanJob order
+const jobColumns = `guid, user_id, type_name, owner_guid, status, options, image_guid, error, created_at, updated_at`
+
+// PostgresStore implements Store using the image_jobs table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new PostgresStore
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// Create stores a new pending job
+func (p *PostgresStore) Create(ctx context.Context, job *domain.Job) error {
+	options, err := json.Marshal(job.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode job options: %w", err)
+	}
+
+	// The options are passed as text, as lib/pq sends []byte as bytea
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO image_jobs (`+jobColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		job.GUID, job.UserID, job.TypeName, job.OwnerGUID, job.Status, string(options),
+		job.ImageGUID, job.Error, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a job by GUID
+func (p *PostgresStore) Get(ctx context.Context, jobGUID uuid.UUID) (*domain.Job, error) {
+	row := p.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM image_jobs WHERE guid = $1`, jobGUID)
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// Claim moves an unfinished job to processing with a conditional update, so
+// a finished job is never claimed again
+func (p *PostgresStore) Claim(ctx context.Context, jobGUID uuid.UUID, now time.Time) (*domain.Job, error) {
+	row := p.db.QueryRowContext(ctx, `
+		UPDATE image_jobs
+		SET status = 'processing', updated_at = $2
+		WHERE guid = $1 AND status IN ('pending', 'processing')
+		RETURNING `+jobColumns,
+		jobGUID, now)
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// Finish stores the outcome of an unfinished job with a conditional update,
+// so a finished job is never overwritten
+func (p *PostgresStore) Finish(ctx context.Context, job *domain.Job) error {
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE image_jobs
+		SET status = $2, image_guid = $3, error = $4, updated_at = $5
+		WHERE guid = $1 AND status IN ('pending', 'processing')`,
+		job.GUID, job.Status, job.ImageGUID, job.Error, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	if rowsAffected == 0 {
+		// Tell a finished job from a missing one
+		if _, err := p.Get(ctx, job.GUID); err != nil {
+			return err
+		}
+		return ErrFinished
+	}
+	return nil
+}
+
+// DeleteFinished removes jobs that finished before the given time
+func (p *PostgresStore) DeleteFinished(ctx context.Context, before time.Time) error {
+	_, err := p.db.ExecContext(ctx, `
+		DELETE FROM image_jobs
+		WHERE status IN ('ready', 'failed') AND updated_at < $1`,
+		before)
+	if err != nil {
+		return fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanJob reads a row selected with jobColumns
+func scanJob(row rowScanner) (*domain.Job, error) {
+	var job domain.Job
+	var options []byte
+	var imageGUID uuid.NullUUID
+	err := row.Scan(&job.GUID, &job.UserID, &job.TypeName, &job.OwnerGUID, &job.Status, &options,
+		&imageGUID, &job.Error, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(options, &job.Options); err != nil {
+		return nil, fmt.Errorf("failed to decode job options: %w", err)
+	}
+	if imageGUID.Valid {
+		job.ImageGUID = &imageGUID.UUID
+	}
+	return &job, nil
+}
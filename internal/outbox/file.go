@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+)
+
+// FilePublisher appends events to a file as JSON lines, so they can be
+// consumed without a message broker
+type FilePublisher struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFilePublisher opens the file at path for appending, creating it if needed
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+	return &FilePublisher{
+		file: file,
+	}, nil
+}
+
+// Publish appends the event as a single line
+func (f *FilePublisher) Publish(ctx context.Context, event domain.ImageEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (f *FilePublisher) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+)
+
+// MemoryPublisher keeps the most recent events in memory, for development
+// and tests
+type MemoryPublisher struct {
+	mutex    sync.Mutex
+	events   []domain.ImageEvent
+	capacity int
+}
+
+// NewMemoryPublisher creates a publisher keeping up to capacity events
+func NewMemoryPublisher(capacity int) *MemoryPublisher {
+	return &MemoryPublisher{
+		capacity: capacity,
+	}
+}
+
+// Publish stores the event, dropping the oldest one when full
+func (m *MemoryPublisher) Publish(ctx context.Context, event domain.ImageEvent) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.events) == m.capacity {
+		m.events = append(m.events[:0], m.events[1:]...)
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+// Events returns the stored events, oldest first
+func (m *MemoryPublisher) Events() []domain.ImageEvent {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]domain.ImageEvent(nil), m.events...)
+}
@@ -0,0 +1,41 @@
+// Package outbox publishes the image lifecycle events recorded by the image
+// repository.
+//
+// Image writes record the events they cause in the same transaction as the
+// change (the transactional outbox pattern). A Relay polls for recorded
+// events and passes them to a Publisher in the order they were recorded,
+// marking them published once it accepts them. Publishing is at least once:
+// an event is published again if marking it fails, so consumers should
+// deduplicate on the event ID.
+package outbox
+
+import (
+	"context"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+)
+
+// Publisher delivers events to their consumers, e.g. a message broker
+type Publisher interface {
+	Publish(ctx context.Context, event domain.ImageEvent) error
+}
+
+// multiPublisher publishes each event to several publishers
+type multiPublisher []Publisher
+
+// Multi combines publishers into one that publishes each event to all of
+// them in order. It fails as soon as one of them does, so when the event is
+// retried the publishers before it see it again.
+func Multi(publishers ...Publisher) Publisher {
+	return multiPublisher(publishers)
+}
+
+// Publish publishes the event to every publisher in order
+func (m multiPublisher) Publish(ctx context.Context, event domain.ImageEvent) error {
+	for _, publisher := range m {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
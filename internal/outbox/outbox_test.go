@@ -0,0 +1,210 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// flakyPublisher fails the first failures events it is given
+type flakyPublisher struct {
+	failures  int
+	published []domain.ImageEvent
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event domain.ImageEvent) error {
+	if p.failures > 0 {
+		p.failures--
+		return assert.AnError
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+// saveWithEvent saves a new user image, recording an event of the given type
+func saveWithEvent(t *testing.T, repo repository.ImageRepository, eventType string) domain.ImageEvent {
+	image := domain.NewImage(uuid.New(), "user")
+	event := domain.NewImageEvent(eventType, image)
+	require.NoError(t, repo.SaveImage(context.Background(), image, event))
+	return event
+}
+
+// eventIDs returns the IDs of events in order
+func eventIDs(events []domain.ImageEvent) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestRelay(t *testing.T) {
+	repo := repository.NewMockImageRepository()
+	publisher := NewMemoryPublisher(10)
+	relay := NewRelay(repo, publisher, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	uploaded := saveWithEvent(t, repo, domain.EventImageUploaded)
+	deleted := saveWithEvent(t, repo, domain.EventImageDeleted)
+
+	published, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uuid.UUID{uploaded.ID, deleted.ID}, eventIDs(publisher.Events()))
+
+	// The image is recorded as written
+	event := publisher.Events()[0]
+	assert.Equal(t, uploaded.Image.GUID, event.Image.GUID)
+	assert.Equal(t, int64(1), event.Image.Version)
+	assert.False(t, event.Image.UpdatedAt.IsZero())
+
+	// Published events are not published again
+	published, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Len(t, publisher.Events(), 2)
+}
+
+func TestRelay_PublishFailure(t *testing.T) {
+	repo := repository.NewMockImageRepository()
+	publisher := &flakyPublisher{failures: 1}
+	relay := NewRelay(repo, publisher, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	first := saveWithEvent(t, repo, domain.EventImageUploaded)
+	second := saveWithEvent(t, repo, domain.EventImageUploaded)
+
+	// A failure stops the relay, so later events don't overtake the failed one
+	published, err := relay.RelayPending(ctx)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Zero(t, published)
+	assert.Empty(t, publisher.published)
+
+	published, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uuid.UUID{first.ID, second.ID}, eventIDs(publisher.published))
+}
+
+func TestRelay_FailedWritesRecordNothing(t *testing.T) {
+	repo := repository.NewMockImageRepository()
+	ctx := context.Background()
+
+	current := domain.NewImage(uuid.New(), "user")
+	require.NoError(t, repo.SaveImage(ctx, current))
+
+	// The replacement lost a race, so neither it nor its event is written
+	stale := *current
+	stale.Version = 0
+	replacement := domain.NewImage(current.OwnerGUID, "user")
+	err := repo.ReplaceImage(ctx, &stale, replacement, domain.NewImageEvent(domain.EventImageReplaced, replacement))
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	err = repo.DeleteImageVersion(ctx, uuid.New(), 1, domain.NewImageEvent(domain.EventImageDeleted, current))
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	publisher := NewMemoryPublisher(10)
+	published, err := NewRelay(repo, publisher, zap.NewNop().Sugar()).RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+}
+
+func TestDeletePublishedEvents(t *testing.T) {
+	repo := repository.NewMockImageRepository()
+	ctx := context.Background()
+
+	saveWithEvent(t, repo, domain.EventImageUploaded)
+	publisher := &flakyPublisher{}
+	relay := NewRelay(repo, publisher, zap.NewNop().Sugar())
+	_, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	pending := saveWithEvent(t, repo, domain.EventImageUploaded)
+
+	// Only published events are removed
+	require.NoError(t, repo.DeletePublishedEvents(ctx, time.Now().Add(time.Minute)))
+	published, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, pending.ID, publisher.published[1].ID)
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := NewMemoryPublisher(2)
+	ctx := context.Background()
+
+	var events []domain.ImageEvent
+	for i := 0; i < 3; i++ {
+		event := domain.NewImageEvent(domain.EventImageUploaded, domain.NewImage(uuid.New(), "user"))
+		require.NoError(t, publisher.Publish(ctx, event))
+		events = append(events, event)
+	}
+
+	// The oldest events are dropped
+	assert.Equal(t, eventIDs(events[1:]), eventIDs(publisher.Events()))
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	ctx := context.Background()
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+	uploaded := domain.NewImageEvent(domain.EventImageUploaded, domain.NewImage(uuid.New(), "user"))
+	require.NoError(t, publisher.Publish(ctx, uploaded))
+	require.NoError(t, publisher.Close())
+
+	// Reopening appends
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	deleted := domain.NewImageEvent(domain.EventImageDeleted, uploaded.Image)
+	require.NoError(t, publisher.Publish(ctx, deleted))
+	require.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []domain.ImageEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event domain.ImageEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, events, 2)
+	assert.Equal(t, []uuid.UUID{uploaded.ID, deleted.ID}, eventIDs(events))
+	assert.Equal(t, domain.EventImageDeleted, events[1].Type)
+	assert.Equal(t, uploaded.Image.GUID, events[1].Image.GUID)
+}
+
+func TestMulti(t *testing.T) {
+	first := NewMemoryPublisher(10)
+	failing := &flakyPublisher{failures: 1}
+	last := NewMemoryPublisher(10)
+	publisher := Multi(first, failing, last)
+	ctx := context.Background()
+
+	event := domain.NewImageEvent(domain.EventImageUploaded, domain.NewImage(uuid.New(), "user"))
+
+	// A failure stops the event from reaching later publishers
+	require.ErrorIs(t, publisher.Publish(ctx, event), assert.AnError)
+	assert.Len(t, first.Events(), 1)
+	assert.Empty(t, last.Events())
+
+	require.NoError(t, publisher.Publish(ctx, event))
+	assert.Len(t, first.Events(), 2)
+	assert.Len(t, last.Events(), 1)
+}
@@ -0,0 +1,75 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize is the number of events relayed per repository call
+	batchSize = 100
+
+	// purgeInterval is how often published events past retention are removed
+	purgeInterval = 10 * time.Minute
+)
+
+// Relay moves recorded events from the repository outbox to a publisher
+type Relay struct {
+	outbox    repository.Outbox
+	publisher Publisher
+	logger    *zap.SugaredLogger
+}
+
+// NewRelay creates a relay publishing the events of the outbox
+func NewRelay(outbox repository.Outbox, publisher Publisher, logger *zap.SugaredLogger) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// RelayPending publishes recorded events until none are left or publishing
+// fails, and returns the number published
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := r.outbox.RelayEvents(ctx, batchSize, func(event domain.ImageEvent) error {
+			return r.publisher.Publish(ctx, event)
+		})
+		total += published
+		if err != nil || published < batchSize {
+			return total, err
+		}
+	}
+}
+
+// Run relays events every interval until ctx is cancelled. Published events
+// are kept for retention before they are removed.
+func (r *Relay) Run(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Errorw("Failed to relay outbox events", "error", err)
+			}
+
+			if now.Sub(lastPurge) >= purgeInterval {
+				if err := r.outbox.DeletePublishedEvents(ctx, now.Add(-retention)); err != nil && ctx.Err() == nil {
+					r.logger.Errorw("Failed to delete published outbox events", "error", err)
+				}
+				lastPurge = now
+			}
+		}
+	}
+}
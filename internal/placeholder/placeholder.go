@@ -0,0 +1,210 @@
+// Package placeholder draws deterministic stand-in images for owners that
+// have not uploaded one: the owner's initials on a colored background when a
+// display name is known, otherwise an identicon derived from the owner GUID.
+package placeholder
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// PathPrefix is the URL path placeholders are served under
+const PathPrefix = "/v1/placeholders"
+
+// identiconCells is the number of cells along each side of an identicon
+const identiconCells = 5
+
+// background is the backdrop identicon cells are drawn on
+var background = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+
+var (
+	fontOnce sync.Once
+	fontData *sfnt.Font
+	fontErr  error
+)
+
+// Initials returns up to two uppercase initials for a display name: the first
+// letters of its first and last words. Names without letters yield "".
+func Initials(name string) string {
+	var initials []rune
+	for _, word := range strings.Fields(name) {
+		for _, r := range word {
+			if unicode.IsLetter(r) {
+				initials = append(initials, unicode.ToUpper(r))
+				break
+			}
+		}
+	}
+	switch len(initials) {
+	case 0:
+		return ""
+	case 1:
+		return string(initials)
+	default:
+		return string([]rune{initials[0], initials[len(initials)-1]})
+	}
+}
+
+// normalizeInitials keeps at most the first two letters of s, uppercased, so
+// arbitrary query values can't draw anything but initials
+func normalizeInitials(s string) string {
+	var initials []rune
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			initials = append(initials, unicode.ToUpper(r))
+			if len(initials) == 2 {
+				break
+			}
+		}
+	}
+	return string(initials)
+}
+
+// Path returns the URL path of an owner's placeholder in the given size
+func Path(typeName string, ownerGUID uuid.UUID, size, initials string) string {
+	path := PathPrefix + "/" + typeName + "/" + ownerGUID.String() + "/" + size
+	if initials != "" {
+		path += "?" + url.Values{"initials": {initials}}.Encode()
+	}
+	return path
+}
+
+// Generate draws the placeholder for an owner. Initials are drawn when given
+// and supported by the font, otherwise the owner's identicon; both use a
+// color derived from the owner GUID, so the result is deterministic.
+func Generate(ownerGUID uuid.UUID, initials string, width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	accent := ownerColor(ownerGUID)
+
+	initials = normalizeInitials(initials)
+	if initials != "" {
+		if face, ok := initialsFace(initials, width, height); ok {
+			draw.Draw(img, img.Bounds(), image.NewUniform(accent), image.Point{}, draw.Src)
+			drawCentered(img, face, initials)
+			return img
+		}
+	}
+
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	drawIdenticon(img, ownerGUID, accent)
+	return img
+}
+
+// drawIdenticon fills a horizontally symmetric grid of cells chosen by the
+// bits of the owner GUID, centered with half a cell of padding
+func drawIdenticon(img *image.RGBA, ownerGUID uuid.UUID, accent color.Color) {
+	side := min(img.Bounds().Dx(), img.Bounds().Dy())
+	cell := side / (identiconCells + 1)
+	if cell == 0 {
+		return
+	}
+	left := (img.Bounds().Dx() - cell*identiconCells) / 2
+	top := (img.Bounds().Dy() - cell*identiconCells) / 2
+
+	// Only the left half and middle column are random; the rest mirrors them
+	bit := 0
+	for column := 0; column < (identiconCells+1)/2; column++ {
+		for row := 0; row < identiconCells; row++ {
+			on := ownerGUID[bit/8]&(1<<(bit%8)) != 0
+			bit++
+			if !on {
+				continue
+			}
+			for _, c := range []int{column, identiconCells - 1 - column} {
+				rect := image.Rect(left+c*cell, top+row*cell, left+(c+1)*cell, top+(row+1)*cell)
+				draw.Draw(img, rect, image.NewUniform(accent), image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+// initialsFace returns a face sized for the image, or false if the font can't draw the initials
+func initialsFace(initials string, width, height int) (font.Face, bool) {
+	fontOnce.Do(func() {
+		fontData, fontErr = opentype.Parse(gobold.TTF)
+	})
+	if fontErr != nil {
+		return nil, false
+	}
+
+	var buf sfnt.Buffer
+	for _, r := range initials {
+		if index, err := fontData.GlyphIndex(&buf, r); err != nil || index == 0 {
+			return nil, false
+		}
+	}
+
+	face, err := opentype.NewFace(fontData, &opentype.FaceOptions{
+		Size:    float64(min(width, height)) * 0.42,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, false
+	}
+	return face, true
+}
+
+// drawCentered draws text in white, centered on its cap height
+func drawCentered(img *image.RGBA, face font.Face, text string) {
+	drawer := &font.Drawer{Dst: img, Src: image.White, Face: face}
+
+	bounds, _ := drawer.BoundString(text)
+	textWidth := bounds.Max.X - bounds.Min.X
+	textHeight := face.Metrics().CapHeight
+
+	drawer.Dot = fixed.Point26_6{
+		X: (fixed.I(img.Bounds().Dx())-textWidth)/2 - bounds.Min.X,
+		Y: (fixed.I(img.Bounds().Dy()) + textHeight) / 2,
+	}
+	drawer.DrawString(text)
+}
+
+// ownerColor derives a saturated, mid-lightness color from the owner GUID
+func ownerColor(ownerGUID uuid.UUID) color.RGBA {
+	hue := float64(uint16(ownerGUID[14])<<8|uint16(ownerGUID[15])) / 65536 * 360
+	return hslToRGB(hue, 0.55, 0.5)
+}
+
+// hslToRGB converts a hue in degrees and saturation and lightness in [0, 1] to RGB
+func hslToRGB(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return color.RGBA{
+		R: uint8((r + m) * 255),
+		G: uint8((g + m) * 255),
+		B: uint8((b + m) * 255),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,62 @@
+package placeholder
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: ""},
+		{name: "  ", want: ""},
+		{name: "ada", want: "A"},
+		{name: "Ada Lovelace", want: "AL"},
+		{name: "Ada King Lovelace", want: "AL"},
+		{name: "  jean-luc   picard ", want: "JP"},
+		{name: "123 (Ada)", want: "A"},
+		{name: "Émile Zola", want: "ÉZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Initials(tt.name))
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	ownerGUID := uuid.MustParse("3f2504e0-4f89-11d3-9a0c-0305e82c3301")
+
+	assert.Equal(t, "/v1/placeholders/user/3f2504e0-4f89-11d3-9a0c-0305e82c3301/small", Path("user", ownerGUID, "small", ""))
+	assert.Equal(t, "/v1/placeholders/user/3f2504e0-4f89-11d3-9a0c-0305e82c3301/large?initials=AL", Path("user", ownerGUID, "large", "AL"))
+}
+
+func TestGenerate(t *testing.T) {
+	ownerGUID := uuid.New()
+
+	identicon := Generate(ownerGUID, "", 100, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 100), identicon.Bounds())
+
+	// The same owner always gets the same image
+	assert.Equal(t, identicon, Generate(ownerGUID, "", 100, 100))
+	assert.NotEqual(t, identicon, Generate(uuid.New(), "", 100, 100))
+
+	// Initials are drawn on the owner's color instead
+	initials := Generate(ownerGUID, "AL", 100, 100)
+	assert.NotEqual(t, identicon, initials)
+	assert.Equal(t, initials, Generate(ownerGUID, "al", 100, 100))
+	assert.Equal(t, ownerColor(ownerGUID), initials.At(0, 0))
+
+	// Non-letters can't be drawn, so they fall back to the identicon
+	assert.Equal(t, identicon, Generate(ownerGUID, "<>", 100, 100))
+
+	// Non-square and tiny sizes still work
+	assert.Equal(t, image.Rect(0, 0, 400, 200), Generate(ownerGUID, "AL", 400, 200).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 3, 3), Generate(ownerGUID, "", 3, 3).Bounds())
+}
@@ -0,0 +1,141 @@
+package problem
+
+// Code is a stable, machine-readable error code. Each code has a problem type
+// URI derived from it; clients should branch on the code or type, never on
+// the detail text.
+type Code string
+
+// The error catalog
+const (
+	// Requests
+	BadRequest            Code = "BadRequest"
+	InvalidRequest        Code = "InvalidRequest"
+	InvalidContentType    Code = "InvalidContentType"
+	InvalidFilter         Code = "InvalidFilter"
+	InvalidCursor         Code = "InvalidCursor"
+	InvalidOrder          Code = "InvalidOrder"
+	BatchTooLarge         Code = "BatchTooLarge"
+	ReadError             Code = "ReadError"
+	NotFound              Code = "NotFound"
+	MethodNotAllowed      Code = "MethodNotAllowed"
+	Timeout               Code = "Timeout"
+	InternalError         Code = "InternalError"
+	InvalidUserID         Code = "InvalidUserID"
+	InvalidOwnerID        Code = "InvalidOwnerID"
+	InvalidOrganizationID Code = "InvalidOrganizationID"
+	InvalidProductID      Code = "InvalidProductID"
+	InvalidImageID        Code = "InvalidImageID"
+	InvalidUploadID       Code = "InvalidUploadID"
+
+	// Authentication and authorization
+	Unauthorized Code = "Unauthorized"
+	InvalidToken Code = "InvalidToken"
+	Forbidden    Code = "Forbidden"
+
+	// Images
+	ImageNotFound        Code = "ImageNotFound"
+	EmptyImage           Code = "EmptyImage"
+	InvalidImage         Code = "InvalidImage"
+	ImageTooLarge        Code = "ImageTooLarge"
+	UnsupportedType      Code = "UnsupportedType"
+	ProcessingFailed     Code = "ProcessingFailed"
+	StorageFailed        Code = "StorageFailed"
+	ImageLimitReached    Code = "ImageLimitReached"
+	UnknownImageType     Code = "UnknownImageType"
+	UnknownImageSize     Code = "UnknownImageSize"
+	InvalidMultipart     Code = "InvalidMultipart"
+	InvalidUploadOptions Code = "InvalidUploadOptions"
+	InvalidSourceURL     Code = "InvalidSourceURL"
+	SourceUnavailable    Code = "SourceUnavailable"
+	InvalidRenderOptions Code = "InvalidRenderOptions"
+	InvalidSignature     Code = "InvalidSignature"
+
+	// Conditional and idempotent writes
+	PreconditionFailed     Code = "PreconditionFailed"
+	ConcurrentUpdate       Code = "ConcurrentUpdate"
+	InvalidIdempotencyKey  Code = "InvalidIdempotencyKey"
+	IdempotencyKeyInUse    Code = "IdempotencyKeyInUse"
+	IdempotencyKeyMismatch Code = "IdempotencyKeyMismatch"
+
+	// Resumable uploads
+	UploadNotFound        Code = "UploadNotFound"
+	UploadExpired         Code = "UploadExpired"
+	UnsupportedVersion    Code = "UnsupportedVersion"
+	InvalidUploadLength   Code = "InvalidUploadLength"
+	InvalidUploadOffset   Code = "InvalidUploadOffset"
+	InvalidUploadMetadata Code = "InvalidUploadMetadata"
+	OffsetMismatch        Code = "OffsetMismatch"
+
+	// Webhooks
+	DeliveryNotFound  Code = "DeliveryNotFound"
+	InvalidDeliveryID Code = "InvalidDeliveryID"
+
+	// Background processing
+	JobNotFound             Code = "JobNotFound"
+	InvalidJobID            Code = "InvalidJobID"
+	InvalidReprocessOptions Code = "InvalidReprocessOptions"
+)
+
+// titles holds the human-readable summary of each code
+var titles = map[Code]string{
+	BadRequest:            "Bad request",
+	InvalidRequest:        "Invalid request body",
+	InvalidContentType:    "Unsupported content type",
+	InvalidFilter:         "Invalid filter",
+	InvalidCursor:         "Invalid page cursor",
+	InvalidOrder:          "Invalid image order",
+	BatchTooLarge:         "Batch too large",
+	ReadError:             "Request body could not be read",
+	NotFound:              "Not found",
+	MethodNotAllowed:      "Method not allowed",
+	Timeout:               "Request timed out",
+	InternalError:         "Internal error",
+	InvalidUserID:         "Invalid user ID",
+	InvalidOwnerID:        "Invalid owner ID",
+	InvalidOrganizationID: "Invalid organization ID",
+	InvalidProductID:      "Invalid product ID",
+	InvalidImageID:        "Invalid image ID",
+	InvalidUploadID:       "Invalid upload ID",
+
+	Unauthorized: "Authentication required",
+	InvalidToken: "Invalid token",
+	Forbidden:    "Forbidden",
+
+	ImageNotFound:        "Image not found",
+	EmptyImage:           "Empty image",
+	InvalidImage:         "Invalid image",
+	ImageTooLarge:        "Image too large",
+	UnsupportedType:      "Unsupported image format",
+	ProcessingFailed:     "Image processing failed",
+	StorageFailed:        "Image storage failed",
+	ImageLimitReached:    "Image limit reached",
+	UnknownImageType:     "Unknown image type",
+	UnknownImageSize:     "Unknown image size",
+	InvalidMultipart:     "Invalid multipart body",
+	InvalidUploadOptions: "Invalid upload options",
+	InvalidSourceURL:     "Invalid source URL",
+	SourceUnavailable:    "Source unavailable",
+	InvalidRenderOptions: "Invalid render options",
+	InvalidSignature:     "Invalid signature",
+
+	PreconditionFailed:     "Precondition failed",
+	ConcurrentUpdate:       "Concurrent update",
+	InvalidIdempotencyKey:  "Invalid idempotency key",
+	IdempotencyKeyInUse:    "Idempotency key in use",
+	IdempotencyKeyMismatch: "Idempotency key reused",
+
+	UploadNotFound:        "Upload not found",
+	UploadExpired:         "Upload expired",
+	UnsupportedVersion:    "Unsupported tus version",
+	InvalidUploadLength:   "Invalid upload length",
+	InvalidUploadOffset:   "Invalid upload offset",
+	InvalidUploadMetadata: "Invalid upload metadata",
+	OffsetMismatch:        "Upload offset mismatch",
+
+	DeliveryNotFound:  "Webhook delivery not found",
+	InvalidDeliveryID: "Invalid delivery ID",
+
+	JobNotFound:             "Job not found",
+	InvalidJobID:            "Invalid job ID",
+	InvalidReprocessOptions: "Invalid reprocessing options",
+}
@@ -0,0 +1,106 @@
+// Package problem writes RFC 9457 problem details, the single error format of
+// every HTTP response the service produces, from handlers to panics.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ContentType is the media type of problem detail responses
+const ContentType = "application/problem+json"
+
+// TypePrefix is the path every problem type URI starts with. Type URIs are
+// relative references, resolved against the API's own origin.
+const TypePrefix = "/problems/"
+
+// FieldError describes why one request field is invalid
+type FieldError struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// Details is an RFC 9457 problem details object. Code and RequestID are
+// extension members: the catalog code the type is derived from, and the ID
+// the request was logged under.
+type Details struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// New creates problem details for a catalog code
+func New(status int, code Code, detail string) *Details {
+	return &Details{
+		Type:   code.TypeURI(),
+		Title:  code.Title(),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithFields adds field-level validation details
+func (d *Details) WithFields(fields ...FieldError) *Details {
+	d.Errors = append(d.Errors, fields...)
+	return d
+}
+
+// Write writes the problem as the response, adding the request path and the
+// request ID assigned by chi's RequestID middleware
+func Write(w http.ResponseWriter, r *http.Request, d *Details) {
+	d.Instance = r.URL.Path
+	d.RequestID = middleware.GetReqID(r.Context())
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(d.Status)
+	if err := json.NewEncoder(w).Encode(d); err != nil {
+		// At this point we've already written the status code, so we can't change it
+		_ = err
+	}
+}
+
+// Error writes a problem without field details
+func Error(w http.ResponseWriter, r *http.Request, status int, code Code, detail string) {
+	Write(w, r, New(status, code, detail))
+}
+
+// TypeURI returns the problem type URI of the code, e.g. /problems/image-not-found
+func (c Code) TypeURI() string {
+	return TypePrefix + kebab(string(c))
+}
+
+// Title returns the short, human-readable summary of the code
+func (c Code) Title() string {
+	if title, ok := titles[c]; ok {
+		return title
+	}
+	return string(c)
+}
+
+// kebab converts a CamelCase code to kebab-case, keeping acronyms together
+func kebab(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
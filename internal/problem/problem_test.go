@@ -0,0 +1,75 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeURI(t *testing.T) {
+	tests := []struct {
+		code Code
+		want string
+	}{
+		{ImageNotFound, "/problems/image-not-found"},
+		{InvalidUserID, "/problems/invalid-user-id"},
+		{InvalidSourceURL, "/problems/invalid-source-url"},
+		{IdempotencyKeyInUse, "/problems/idempotency-key-in-use"},
+		{Timeout, "/problems/timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.code.TypeURI())
+		})
+	}
+}
+
+func TestCatalogHasTitles(t *testing.T) {
+	seen := make(map[string]Code, len(titles))
+	for code, title := range titles {
+		assert.NotEmpty(t, title, code)
+
+		// Type URIs must stay unique across the catalog
+		uri := code.TypeURI()
+		other, duplicate := seen[uri]
+		assert.False(t, duplicate, "%s and %s share %s", code, other, uri)
+		seen[uri] = code
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var recorded *http.Request
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded = r
+		w.Header().Set("Content-Length", "12")
+		Write(w, r, New(http.StatusBadRequest, InvalidUploadOptions, "crop is malformed").
+			WithFields(FieldError{Field: "crop", Detail: "crop must be \"x,y,width,height\""}))
+	}))
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/me/image?x=1", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	assert.Empty(t, rr.Header().Get("Content-Length"))
+
+	var details Details
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &details))
+	assert.Equal(t, "/problems/invalid-upload-options", details.Type)
+	assert.Equal(t, "Invalid upload options", details.Title)
+	assert.Equal(t, http.StatusBadRequest, details.Status)
+	assert.Equal(t, "crop is malformed", details.Detail)
+	assert.Equal(t, "/v1/me/image", details.Instance)
+	assert.Equal(t, InvalidUploadOptions, details.Code)
+	assert.Equal(t, middleware.GetReqID(recorded.Context()), details.RequestID)
+	assert.NotEmpty(t, details.RequestID)
+	require.Len(t, details.Errors, 1)
+	assert.Equal(t, "crop", details.Errors[0].Field)
+}
@@ -6,27 +6,43 @@ import (
 	"fmt"
 	"image"
 	"image/jpeg"
-	_ "image/png" // register PNG decoder
+	"image/png"
+	"io"
 	"sync"
 
 	"github.com/antonrybalko/image-service-go/internal/domain"
 	"golang.org/x/image/draw"
 )
 
+// HeaderSize is the number of leading bytes of an image that DetectImageFormat
+// needs; callers can peek this much of a stream without consuming it
+const HeaderSize = 512
+
 // ProcessorInterface defines the operations for image processing
 type ProcessorInterface interface {
-	// ProcessImage processes an image according to the image type configuration
-	// and returns a map of size name to processed image bytes
-	ProcessImage(imgData []byte, imageType *domain.ImageType) (map[string][]byte, error)
+	// ProcessImage decodes an image from r, processes it according to the image
+	// type configuration and returns a map of size name to processed image bytes
+	ProcessImage(r io.Reader, imageType *domain.ImageType) (map[string][]byte, error)
+
+	// ProcessCroppedImage crops an image to the given rectangle before processing it
+	// like ProcessImage
+	ProcessCroppedImage(r io.Reader, imageType *domain.ImageType, crop domain.Crop) (map[string][]byte, error)
 
-	// DetectImageFormat detects the image format and returns the content type
-	DetectImageFormat(imgData []byte) (string, error)
+	// DetectImageFormat detects the image format from the first HeaderSize bytes
+	// of an image (or all of it, if shorter) and returns the content type
+	DetectImageFormat(header []byte) (string, error)
 
-	// GetImageDimensions returns the width and height of an image
-	GetImageDimensions(imgData []byte) (width int, height int, err error)
+	// GetImageDimensions returns the width and height of an image, reading only
+	// as much of r as needed to parse the image header
+	GetImageDimensions(r io.Reader) (width int, height int, err error)
 
-	// CalculateResizeDimensions calculates new dimensions preserving aspect ratio
+	// CalculateResizeDimensions calculates new dimensions preserving aspect ratio,
+	// fitting inside the target box when both target dimensions are set
 	CalculateResizeDimensions(origWidth, origHeight, targetWidth, targetHeight int) (newWidth, newHeight int)
+
+	// Render decodes an image from r, crops it to crop if set, and produces a
+	// single rendition as described by opts
+	Render(r io.Reader, crop *domain.Crop, opts domain.RenderOptions) ([]byte, error)
 }
 
 // Processor implements ProcessorInterface using Go's standard image package
@@ -41,8 +57,53 @@ func NewProcessor() ProcessorInterface {
 }
 
 // ProcessImage processes an image according to the image type configuration
-func (p *Processor) ProcessImage(imgData []byte, imageType *domain.ImageType) (map[string][]byte, error) {
-	if len(imgData) == 0 {
+func (p *Processor) ProcessImage(r io.Reader, imageType *domain.ImageType) (map[string][]byte, error) {
+	srcImg, err := p.decode(r, imageType)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.resize(srcImg, imageType)
+}
+
+// ProcessCroppedImage crops an image to the given rectangle before processing it
+func (p *Processor) ProcessCroppedImage(r io.Reader, imageType *domain.ImageType, crop domain.Crop) (map[string][]byte, error) {
+	srcImg, err := p.decode(r, imageType)
+	if err != nil {
+		return nil, err
+	}
+
+	cropped, err := cropImage(srcImg, crop)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.resize(cropped, imageType)
+}
+
+// cropImage returns the part of srcImg inside crop
+func cropImage(srcImg image.Image, crop domain.Crop) (image.Image, error) {
+	// The crop is relative to the image origin, which need not be (0, 0)
+	bounds := srcImg.Bounds()
+	rect := image.Rect(crop.X, crop.Y, crop.X+crop.Width, crop.Y+crop.Height).Add(bounds.Min)
+	if crop.Width <= 0 || crop.Height <= 0 || !rect.In(bounds) {
+		return nil, fmt.Errorf("crop %dx%d+%d+%d is outside the %dx%d image",
+			crop.Width, crop.Height, crop.X, crop.Y, bounds.Dx(), bounds.Dy())
+	}
+
+	subImager, ok := srcImg.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, errors.New("image does not support cropping")
+	}
+
+	return subImager.SubImage(rect), nil
+}
+
+// decode validates the inputs and decodes the source image
+func (p *Processor) decode(r io.Reader, imageType *domain.ImageType) (image.Image, error) {
+	if r == nil {
 		return nil, errors.New("empty image data")
 	}
 
@@ -50,29 +111,35 @@ func (p *Processor) ProcessImage(imgData []byte, imageType *domain.ImageType) (m
 		return nil, errors.New("image type configuration is required")
 	}
 
-	// Decode the source image
-	srcImg, _, err := image.Decode(bytes.NewReader(imgData))
+	// Decode the source image straight from the stream
+	srcImg, _, err := image.Decode(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
 
-	// Get original dimensions
-	bounds := srcImg.Bounds()
-	origWidth := bounds.Dx()
-	origHeight := bounds.Dy()
+	return srcImg, nil
+}
 
+// resize creates every size variant of the image type from a decoded image
+func (p *Processor) resize(srcImg image.Image, imageType *domain.ImageType) (map[string][]byte, error) {
 	result := make(map[string][]byte)
 
 	// Process each size variant
 	for sizeName, size := range imageType.Sizes {
-		// Calculate new dimensions preserving aspect ratio
-		newWidth, newHeight := p.CalculateResizeDimensions(origWidth, origHeight, size.Width, size.Height)
-
-		// Create a new image with the calculated dimensions
-		dstImg := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+		l := p.layout(srcImg.Bounds(), size)
+
+		// Create a new image with the calculated dimensions, padded if needed
+		dstImg := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+		if l.dst != dstImg.Bounds() {
+			background, err := size.BackgroundColor()
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s size: %w", sizeName, err)
+			}
+			draw.Draw(dstImg, dstImg.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+		}
 
 		// Resize the image using CatmullRom for high-quality resampling
-		draw.CatmullRom.Scale(dstImg, dstImg.Bounds(), srcImg, srcImg.Bounds(), draw.Over, nil)
+		draw.CatmullRom.Scale(dstImg, l.dst, srcImg, l.src, draw.Over, nil)
 
 		// Encode the resized image
 		var buf bytes.Buffer
@@ -90,31 +157,172 @@ func (p *Processor) ProcessImage(imgData []byte, imageType *domain.ImageType) (m
 	return result, nil
 }
 
-// DetectImageFormat detects the image format and returns the content type
-func (p *Processor) DetectImageFormat(imgData []byte) (string, error) {
-	if len(imgData) < 12 {
+// variantLayout describes how a variant is drawn from its source
+type variantLayout struct {
+	width, height int             // Size of the variant
+	src           image.Rectangle // Part of the source that is drawn
+	dst           image.Rectangle // Where the source part is scaled to; the rest is padding
+}
+
+// layout works out how a size variant is drawn from a source with the given
+// bounds. Only fill changes the aspect ratio: cover crops the source to the
+// box's ratio, keeping the part its gravity names, and contain pads the
+// scaled source out to the box, placing it by its gravity.
+func (p *Processor) layout(bounds image.Rectangle, size domain.Size) variantLayout {
+	l := variantLayout{src: bounds}
+	if !size.IsFixed() {
+		l.width, l.height = p.CalculateResizeDimensions(bounds.Dx(), bounds.Dy(), size.Width, size.Height)
+		l.width, l.height = max(l.width, 1), max(l.height, 1)
+		l.dst = image.Rect(0, 0, l.width, l.height)
+		return l
+	}
+
+	switch size.EffectiveFit() {
+	case domain.FitFill:
+		l.width, l.height = size.Width, size.Height
+		l.dst = image.Rect(0, 0, l.width, l.height)
+	case domain.FitInside:
+		l.width, l.height = p.CalculateResizeDimensions(bounds.Dx(), bounds.Dy(), size.Width, size.Height)
+		l.dst = image.Rect(0, 0, l.width, l.height)
+	case domain.FitContain:
+		l.width, l.height = size.Width, size.Height
+		width, height := p.CalculateResizeDimensions(bounds.Dx(), bounds.Dy(), size.Width, size.Height)
+		l.dst = image.Rect(0, 0, width, height).Add(anchor(size.EffectiveGravity(), l.width-width, l.height-height))
+	default:
+		l.width, l.height = size.Width, size.Height
+		l.dst = image.Rect(0, 0, l.width, l.height)
+		cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+		if cropWidth*size.Height > cropHeight*size.Width {
+			cropWidth = max(cropHeight*size.Width/size.Height, 1)
+		} else {
+			cropHeight = max(cropWidth*size.Height/size.Width, 1)
+		}
+		l.src = image.Rect(0, 0, cropWidth, cropHeight).
+			Add(bounds.Min).
+			Add(anchor(size.EffectiveGravity(), bounds.Dx()-cropWidth, bounds.Dy()-cropHeight))
+	}
+	return l
+}
+
+// anchor returns the offset placing something within spare room on each
+// axis, as the gravity names
+func anchor(gravity string, spareX, spareY int) image.Point {
+	offset := image.Pt(spareX/2, spareY/2)
+	switch gravity {
+	case domain.GravityNorth, domain.GravityNorthEast, domain.GravityNorthWest:
+		offset.Y = 0
+	case domain.GravitySouth, domain.GravitySouthEast, domain.GravitySouthWest:
+		offset.Y = spareY
+	}
+	switch gravity {
+	case domain.GravityWest, domain.GravityNorthWest, domain.GravitySouthWest:
+		offset.X = 0
+	case domain.GravityEast, domain.GravityNorthEast, domain.GravitySouthEast:
+		offset.X = spareX
+	}
+	return offset
+}
+
+// Render decodes an image from r, crops it to crop if set, and produces a single
+// rendition as described by opts. Contain never enlarges the image; cover and
+// fill always produce the requested box.
+func (p *Processor) Render(r io.Reader, crop *domain.Crop, opts domain.RenderOptions) ([]byte, error) {
+	srcImg, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if crop != nil {
+		if srcImg, err = cropImage(srcImg, *crop); err != nil {
+			return nil, err
+		}
+	}
+
+	bounds := srcImg.Bounds()
+	srcRect := bounds
+	width, height := bounds.Dx(), bounds.Dy()
+
+	switch opts.Fit {
+	case domain.FitFill:
+		width, height = opts.Width, opts.Height
+	case domain.FitCover:
+		// Crop the source centrally to the box's aspect ratio, then scale that
+		width, height = opts.Width, opts.Height
+		cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+		if cropWidth*height > cropHeight*width {
+			cropWidth = cropHeight * width / height
+		} else {
+			cropHeight = cropWidth * height / width
+		}
+		x := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+		y := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+		srcRect = image.Rect(x, y, x+cropWidth, y+cropHeight)
+	default:
+		if opts.Width > 0 && opts.Height > 0 {
+			// Fit inside the box: scale by the tighter of the two ratios
+			if bounds.Dx()*opts.Height > bounds.Dy()*opts.Width {
+				width, height = p.CalculateResizeDimensions(bounds.Dx(), bounds.Dy(), opts.Width, 0)
+			} else {
+				width, height = p.CalculateResizeDimensions(bounds.Dx(), bounds.Dy(), 0, opts.Height)
+			}
+		} else {
+			width, height = p.CalculateResizeDimensions(bounds.Dx(), bounds.Dy(), opts.Width, opts.Height)
+		}
+		if width > bounds.Dx() || height > bounds.Dy() {
+			width, height = bounds.Dx(), bounds.Dy()
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dstImg := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dstImg, dstImg.Bounds(), srcImg, srcRect, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if opts.Format == domain.FormatPNG {
+		err = png.Encode(&buf, dstImg)
+	} else {
+		quality := opts.Quality
+		if quality == 0 {
+			quality = domain.DefaultRenderQuality
+		}
+		err = jpeg.Encode(&buf, dstImg, &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rendition: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DetectImageFormat detects the image format from its leading bytes and returns the content type
+func (p *Processor) DetectImageFormat(header []byte) (string, error) {
+	if len(header) < 12 {
 		return "", errors.New("image data too small to determine format")
 	}
 
 	// Check for JPEG signature
-	if bytes.Equal(imgData[0:2], []byte{0xFF, 0xD8}) {
+	if bytes.Equal(header[0:2], []byte{0xFF, 0xD8}) {
 		return "image/jpeg", nil
 	}
 
 	// Check for PNG signature
-	if bytes.Equal(imgData[0:8], []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}) {
+	if bytes.Equal(header[0:8], []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}) {
 		return "image/png", nil
 	}
 
 	// Check for GIF signature
-	if bytes.Equal(imgData[0:6], []byte{'G', 'I', 'F', '8', '7', 'a'}) ||
-		bytes.Equal(imgData[0:6], []byte{'G', 'I', 'F', '8', '9', 'a'}) {
+	if bytes.Equal(header[0:6], []byte{'G', 'I', 'F', '8', '7', 'a'}) ||
+		bytes.Equal(header[0:6], []byte{'G', 'I', 'F', '8', '9', 'a'}) {
 		return "image/gif", nil
 	}
 
 	// Check for WebP signature
-	if bytes.Equal(imgData[0:4], []byte{'R', 'I', 'F', 'F'}) &&
-		bytes.Equal(imgData[8:12], []byte{'W', 'E', 'B', 'P'}) {
+	if bytes.Equal(header[0:4], []byte{'R', 'I', 'F', 'F'}) &&
+		bytes.Equal(header[8:12], []byte{'W', 'E', 'B', 'P'}) {
 		return "image/webp", nil
 	}
 
@@ -122,9 +330,8 @@ func (p *Processor) DetectImageFormat(imgData []byte) (string, error) {
 }
 
 // GetImageDimensions returns the width and height of an image
-func (p *Processor) GetImageDimensions(imgData []byte) (width int, height int, err error) {
-	reader := bytes.NewReader(imgData)
-	cfg, _, err := image.DecodeConfig(reader)
+func (p *Processor) GetImageDimensions(r io.Reader) (width int, height int, err error) {
+	cfg, _, err := image.DecodeConfig(r)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to decode image dimensions: %w", err)
 	}
@@ -134,9 +341,13 @@ func (p *Processor) GetImageDimensions(imgData []byte) (width int, height int, e
 
 // CalculateResizeDimensions calculates new dimensions preserving aspect ratio
 func (p *Processor) CalculateResizeDimensions(origWidth, origHeight, targetWidth, targetHeight int) (newWidth, newHeight int) {
-	// If both target dimensions are specified
+	// If both target dimensions are specified, fit inside the box by scaling to
+	// the tighter of the two
 	if targetWidth > 0 && targetHeight > 0 {
-		return targetWidth, targetHeight
+		if origWidth*targetHeight > origHeight*targetWidth {
+			return targetWidth, max(origHeight*targetWidth/origWidth, 1)
+		}
+		return max(origWidth*targetHeight/origHeight, 1), targetHeight
 	}
 
 	// If only target width is specified, calculate height to maintain aspect ratio
@@ -161,8 +372,11 @@ func (p *Processor) CalculateResizeDimensions(origWidth, origHeight, targetWidth
 type MockProcessor struct {
 	mutex                sync.RWMutex
 	processedImages      map[string]map[string][]byte
+	renderCount          int
 	detectedFormats      map[string]string
 	imageDimensions      map[string]struct{ width, height int }
+	variantDimensions    map[string]map[string]image.Point
+	crops                map[string]domain.Crop
 	shouldFailProcessing bool
 	shouldFailDetection  bool
 }
@@ -170,14 +384,21 @@ type MockProcessor struct {
 // NewMockProcessor creates a new mock processor for testing
 func NewMockProcessor() *MockProcessor {
 	return &MockProcessor{
-		processedImages: make(map[string]map[string][]byte),
-		detectedFormats: make(map[string]string),
-		imageDimensions: make(map[string]struct{ width, height int }),
+		processedImages:   make(map[string]map[string][]byte),
+		detectedFormats:   make(map[string]string),
+		imageDimensions:   make(map[string]struct{ width, height int }),
+		variantDimensions: make(map[string]map[string]image.Point),
+		crops:             make(map[string]domain.Crop),
 	}
 }
 
 // ProcessImage mocks processing an image
-func (m *MockProcessor) ProcessImage(imgData []byte, imageType *domain.ImageType) (map[string][]byte, error) {
+func (m *MockProcessor) ProcessImage(r io.Reader, imageType *domain.ImageType) (map[string][]byte, error) {
+	imgData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -186,24 +407,47 @@ func (m *MockProcessor) ProcessImage(imgData []byte, imageType *domain.ImageType
 	}
 
 	// Generate a unique key for this image data
-	key := fmt.Sprintf("%x", imgData[:16]) // Use first 16 bytes as key
+	key := mockKey(imgData)
+	width, height := 800, 600
+	if dims, exists := m.imageDimensions[key]; exists {
+		width, height = dims.width, dims.height
+	}
 
-	// Create mock processed images for each size
+	// Create mock processed images for each size, laid out like the real processor would
 	result := make(map[string][]byte)
-	for sizeName := range imageType.Sizes {
+	dimensions := make(map[string]image.Point)
+	for sizeName, size := range imageType.Sizes {
 		// Mock image data for this size
 		mockData := []byte(fmt.Sprintf("mock-%s-%s-data", key, sizeName))
 		result[sizeName] = mockData
+
+		l := (&Processor{}).layout(image.Rect(0, 0, width, height), size)
+		dimensions[sizeName] = image.Pt(l.width, l.height)
 	}
 
 	// Store the result for later verification
 	m.processedImages[key] = result
+	m.variantDimensions[key] = dimensions
 
 	return result, nil
 }
 
+// ProcessCroppedImage mocks cropping and processing an image
+func (m *MockProcessor) ProcessCroppedImage(r io.Reader, imageType *domain.ImageType, crop domain.Crop) (map[string][]byte, error) {
+	imgData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	m.mutex.Lock()
+	m.crops[mockKey(imgData)] = crop
+	m.mutex.Unlock()
+
+	return m.ProcessImage(bytes.NewReader(imgData), imageType)
+}
+
 // DetectImageFormat mocks detecting the image format
-func (m *MockProcessor) DetectImageFormat(imgData []byte) (string, error) {
+func (m *MockProcessor) DetectImageFormat(header []byte) (string, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -212,7 +456,7 @@ func (m *MockProcessor) DetectImageFormat(imgData []byte) (string, error) {
 	}
 
 	// Generate a unique key for this image data
-	key := fmt.Sprintf("%x", imgData[:16]) // Use first 16 bytes as key
+	key := mockKey(header)
 
 	// Return predefined format or default to JPEG
 	if format, exists := m.detectedFormats[key]; exists {
@@ -223,12 +467,19 @@ func (m *MockProcessor) DetectImageFormat(imgData []byte) (string, error) {
 }
 
 // GetImageDimensions mocks getting image dimensions
-func (m *MockProcessor) GetImageDimensions(imgData []byte) (width int, height int, err error) {
+func (m *MockProcessor) GetImageDimensions(r io.Reader) (width int, height int, err error) {
+	// Like a real decoder, only consume the header
+	header := make([]byte, mockKeySize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, 0, fmt.Errorf("failed to read image header: %w", err)
+	}
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	// Generate a unique key for this image data
-	key := fmt.Sprintf("%x", imgData[:16]) // Use first 16 bytes as key
+	key := mockKey(header[:n])
 
 	// Return predefined dimensions or default
 	if dims, exists := m.imageDimensions[key]; exists {
@@ -243,7 +494,10 @@ func (m *MockProcessor) GetImageDimensions(imgData []byte) (width int, height in
 func (m *MockProcessor) CalculateResizeDimensions(origWidth, origHeight, targetWidth, targetHeight int) (newWidth, newHeight int) {
 	// Use the same logic as the real processor
 	if targetWidth > 0 && targetHeight > 0 {
-		return targetWidth, targetHeight
+		if origWidth*targetHeight > origHeight*targetWidth {
+			return targetWidth, max(origHeight*targetWidth/origWidth, 1)
+		}
+		return max(origWidth*targetHeight/origHeight, 1), targetHeight
 	}
 
 	if targetWidth > 0 && targetHeight == 0 {
@@ -261,6 +515,28 @@ func (m *MockProcessor) CalculateResizeDimensions(origWidth, origHeight, targetW
 	return origWidth, origHeight
 }
 
+// Render mocks producing a rendition; the output names the source and
+// options, and the crop is recorded like ProcessCroppedImage's
+func (m *MockProcessor) Render(r io.Reader, crop *domain.Crop, opts domain.RenderOptions) ([]byte, error) {
+	imgData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.shouldFailProcessing {
+		return nil, errors.New("mock processing failure")
+	}
+	if crop != nil {
+		m.crops[mockKey(imgData)] = *crop
+	}
+
+	m.renderCount++
+	return []byte(fmt.Sprintf("mock-render-%s-%s", mockKey(imgData), opts)), nil
+}
+
 // --- Test Helper Methods ---
 
 // SetShouldFailProcessing configures the mock to fail processing
@@ -281,16 +557,38 @@ func (m *MockProcessor) SetShouldFailDetection(shouldFail bool) {
 func (m *MockProcessor) SetDetectedFormat(imgData []byte, format string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	key := fmt.Sprintf("%x", imgData[:16])
-	m.detectedFormats[key] = format
+	m.detectedFormats[mockKey(imgData)] = format
 }
 
 // SetImageDimensions sets predefined dimensions for an image
 func (m *MockProcessor) SetImageDimensions(imgData []byte, width, height int) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	key := fmt.Sprintf("%x", imgData[:16])
-	m.imageDimensions[key] = struct{ width, height int }{width, height}
+	m.imageDimensions[mockKey(imgData)] = struct{ width, height int }{width, height}
+}
+
+// GetCrop returns the crop applied when processing an image, if any
+func (m *MockProcessor) GetCrop(imgData []byte) (domain.Crop, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	crop, exists := m.crops[mockKey(imgData)]
+	return crop, exists
+}
+
+// GetVariantDimensions returns the dimensions the variant of the given size
+// had when an image was last processed
+func (m *MockProcessor) GetVariantDimensions(imgData []byte, sizeName string) (width, height int, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	dims, ok := m.variantDimensions[mockKey(imgData)][sizeName]
+	return dims.X, dims.Y, ok
+}
+
+// GetRenderCount returns the number of renditions produced
+func (m *MockProcessor) GetRenderCount() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.renderCount
 }
 
 // GetProcessedImageCount returns the number of processed images
@@ -305,4 +603,16 @@ func (m *MockProcessor) ClearProcessedImages() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	m.processedImages = make(map[string]map[string][]byte)
+	m.variantDimensions = make(map[string]map[string]image.Point)
+}
+
+// mockKeySize is the number of leading bytes that identify an image in the mock
+const mockKeySize = 16
+
+// mockKey identifies image data by its first mockKeySize bytes
+func mockKey(imgData []byte) string {
+	if len(imgData) > mockKeySize {
+		imgData = imgData[:mockKeySize]
+	}
+	return fmt.Sprintf("%x", imgData)
 }
@@ -0,0 +1,232 @@
+package processor
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red  = color.RGBA{R: 0xff, A: 0xff}
+	blue = color.RGBA{B: 0xff, A: 0xff}
+)
+
+// createTestImage encodes a PNG whose left or top half is red and the rest blue,
+// depending on which side is longer
+func createTestImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (width >= height && x < width/2) || (width < height && y < height/2) {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// processSize processes an image into a single size and decodes the variant
+func processSize(t *testing.T, data []byte, size domain.Size) image.Image {
+	t.Helper()
+
+	variants, err := NewProcessor().ProcessImage(bytes.NewReader(data), &domain.ImageType{
+		Name:  "test",
+		Sizes: domain.SizeSet{"large": size},
+	})
+	require.NoError(t, err)
+
+	variant, err := jpeg.Decode(bytes.NewReader(variants["large"]))
+	require.NoError(t, err)
+	return variant
+}
+
+// assertColor checks a pixel is close to the expected color, allowing for JPEG loss
+func assertColor(t *testing.T, expected color.RGBA, img image.Image, x, y int) {
+	t.Helper()
+
+	r, g, b, _ := img.At(x, y).RGBA()
+	actual := color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 0xff}
+	near := func(a, b uint8) bool { return int(a)-int(b) < 48 && int(b)-int(a) < 48 }
+	assert.True(t, near(expected.R, actual.R) && near(expected.G, actual.G) && near(expected.B, actual.B),
+		"pixel (%d, %d): expected %v, got %v", x, y, expected, actual)
+}
+
+// TestProcessImage_Fit tests that every fit mode but fill keeps the aspect ratio
+func TestProcessImage_Fit(t *testing.T) {
+	// A 16:9 photo uploaded for a square size
+	data := createTestImage(t, 1600, 900)
+
+	tests := []struct {
+		name           string
+		size           domain.Size
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{name: "Default is cover", size: domain.Size{Width: 400, Height: 400}, expectedWidth: 400, expectedHeight: 400},
+		{name: "Cover", size: domain.Size{Width: 400, Height: 400, Fit: domain.FitCover}, expectedWidth: 400, expectedHeight: 400},
+		{name: "Contain", size: domain.Size{Width: 400, Height: 400, Fit: domain.FitContain}, expectedWidth: 400, expectedHeight: 400},
+		{name: "Fill", size: domain.Size{Width: 400, Height: 400, Fit: domain.FitFill}, expectedWidth: 400, expectedHeight: 400},
+		{name: "Inside", size: domain.Size{Width: 400, Height: 400, Fit: domain.FitInside}, expectedWidth: 400, expectedHeight: 225},
+		{name: "Width only", size: domain.Size{Width: 800}, expectedWidth: 800, expectedHeight: 450},
+		{name: "Height only", size: domain.Size{Height: 450}, expectedWidth: 800, expectedHeight: 450},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := processSize(t, data, tt.size)
+			assert.Equal(t, tt.expectedWidth, variant.Bounds().Dx())
+			assert.Equal(t, tt.expectedHeight, variant.Bounds().Dy())
+		})
+	}
+}
+
+// TestProcessImage_Cover tests that cover crops rather than squashes, keeping
+// the part named by the gravity
+func TestProcessImage_Cover(t *testing.T) {
+	data := createTestImage(t, 1600, 900)
+
+	// Centred, the square takes the middle of the photo: half red, half blue
+	variant := processSize(t, data, domain.Size{Width: 90, Height: 90})
+	assertColor(t, red, variant, 10, 45)
+	assertColor(t, blue, variant, 80, 45)
+
+	// West keeps the red left edge only
+	variant = processSize(t, data, domain.Size{Width: 90, Height: 90, Gravity: domain.GravityWest})
+	assertColor(t, red, variant, 10, 45)
+	assertColor(t, red, variant, 70, 45)
+
+	// Southeast keeps the blue right edge only
+	variant = processSize(t, data, domain.Size{Width: 90, Height: 90, Gravity: domain.GravitySouthEast})
+	assertColor(t, blue, variant, 20, 45)
+	assertColor(t, blue, variant, 80, 45)
+}
+
+// TestProcessImage_Contain tests that contain pads the scaled image with the
+// background, placing it by the gravity
+func TestProcessImage_Contain(t *testing.T) {
+	// A portrait photo, red on top, into a square: 50x100 with 50 spare columns
+	data := createTestImage(t, 300, 600)
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	green := color.RGBA{G: 0xff, A: 0xff}
+
+	variant := processSize(t, data, domain.Size{Width: 100, Height: 100, Fit: domain.FitContain})
+	assertColor(t, white, variant, 5, 50)
+	assertColor(t, red, variant, 50, 20)
+	assertColor(t, blue, variant, 50, 80)
+	assertColor(t, white, variant, 95, 50)
+
+	variant = processSize(t, data, domain.Size{
+		Width:      100,
+		Height:     100,
+		Fit:        domain.FitContain,
+		Gravity:    domain.GravityEast,
+		Background: "#00ff00",
+	})
+	assertColor(t, green, variant, 5, 50)
+	assertColor(t, green, variant, 45, 50)
+	assertColor(t, red, variant, 75, 20)
+	assertColor(t, blue, variant, 75, 80)
+}
+
+// TestCalculateResizeDimensions tests that resizing keeps the aspect ratio
+func TestCalculateResizeDimensions(t *testing.T) {
+	p := NewProcessor()
+
+	tests := []struct {
+		name                          string
+		origWidth, origHeight         int
+		targetWidth, targetHeight     int
+		expectedWidth, expectedHeight int
+	}{
+		{"Landscape into a square", 1600, 900, 800, 800, 800, 450},
+		{"Portrait into a square", 900, 1600, 800, 800, 450, 800},
+		{"Same ratio", 1600, 900, 160, 90, 160, 90},
+		{"Enlarged", 100, 50, 400, 400, 400, 200},
+		{"Width only", 1600, 900, 800, 0, 800, 450},
+		{"Height only", 1600, 900, 0, 450, 800, 450},
+		{"Neither", 1600, 900, 0, 0, 1600, 900},
+		{"Never zero", 10000, 10, 100, 100, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := p.CalculateResizeDimensions(tt.origWidth, tt.origHeight, tt.targetWidth, tt.targetHeight)
+			assert.Equal(t, tt.expectedWidth, width)
+			assert.Equal(t, tt.expectedHeight, height)
+
+			mockWidth, mockHeight := NewMockProcessor().CalculateResizeDimensions(tt.origWidth, tt.origHeight, tt.targetWidth, tt.targetHeight)
+			assert.Equal(t, width, mockWidth)
+			assert.Equal(t, height, mockHeight)
+		})
+	}
+}
+
+// TestMockProcessor_VariantDimensions tests that the mock lays variants out
+// like the real processor
+func TestMockProcessor_VariantDimensions(t *testing.T) {
+	m := NewMockProcessor()
+	data := []byte("mock-landscape-image-data")
+	m.SetImageDimensions(data, 1600, 900)
+
+	_, err := m.ProcessImage(bytes.NewReader(data), &domain.ImageType{
+		Name: "test",
+		Sizes: domain.SizeSet{
+			"small":  {Width: 100, Height: 100},
+			"medium": {Width: 400, Height: 400, Fit: domain.FitInside},
+			"large":  {Width: 800},
+		},
+	})
+	require.NoError(t, err)
+
+	for size, expected := range map[string][2]int{
+		"small":  {100, 100},
+		"medium": {400, 225},
+		"large":  {800, 450},
+	} {
+		width, height, ok := m.GetVariantDimensions(data, size)
+		require.True(t, ok, size)
+		assert.Equal(t, expected, [2]int{width, height}, size)
+	}
+}
+
+// TestRender_Crop tests that renditions are cut from the crop and keep its
+// aspect ratio
+func TestRender_Crop(t *testing.T) {
+	// A 16:9 original whose left half is red and right half blue
+	data := createTestImage(t, 1600, 900)
+	render := func(crop *domain.Crop, opts domain.RenderOptions) image.Image {
+		t.Helper()
+		opts.Format, opts.Quality = domain.FormatPNG, domain.DefaultRenderQuality
+		rendered, err := NewProcessor().Render(bytes.NewReader(data), crop, opts)
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(rendered))
+		require.NoError(t, err)
+		return img
+	}
+
+	// The whole original is used at its own resolution
+	img := render(nil, domain.RenderOptions{Width: 1200, Height: 1200, Fit: domain.FitContain})
+	assert.Equal(t, image.Rect(0, 0, 1200, 675), img.Bounds())
+
+	// Only the blue half is used, and contain doesn't enlarge it
+	img = render(&domain.Crop{X: 800, Y: 0, Width: 800, Height: 900}, domain.RenderOptions{Width: 1000, Fit: domain.FitContain})
+	assert.Equal(t, image.Rect(0, 0, 800, 900), img.Bounds())
+	assertColor(t, blue, img, 10, 450)
+
+	_, err := NewProcessor().Render(bytes.NewReader(data), &domain.Crop{X: 1000, Y: 0, Width: 800, Height: 900}, domain.RenderOptions{Width: 100, Fit: domain.FitContain})
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package queue
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryQueue implements Queue in memory, for development and tests
+type MemoryQueue struct {
+	mutex sync.Mutex
+	jobs  map[uuid.UUID]*Job
+}
+
+// NewMemoryQueue creates a new, empty MemoryQueue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		jobs: make(map[uuid.UUID]*Job),
+	}
+}
+
+// Enqueue stores a new pending job, unless its ID is already stored
+func (m *MemoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.jobs[job.ID]; !exists {
+		m.jobs[job.ID] = copyJob(job)
+	}
+	return nil
+}
+
+// Claim returns the due job with the highest priority and postpones it by
+// visibility
+func (m *MemoryQueue) Claim(ctx context.Context, kinds []string, now time.Time, visibility time.Duration) (*Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var next *Job
+	for _, job := range m.jobs {
+		if job.Status != StatusPending || job.RunAt.After(now) || !slices.Contains(kinds, job.Kind) {
+			continue
+		}
+		if next == nil || claimsBefore(job, next) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Attempts++
+	next.Lease = uuid.New()
+	next.RunAt = now.Add(visibility)
+	next.UpdatedAt = now
+	return copyJob(next), nil
+}
+
+// Complete marks a claimed job done
+func (m *MemoryQueue) Complete(ctx context.Context, job *Job, now time.Time) error {
+	return m.settle(job, func(stored *Job) {
+		stored.Status = StatusDone
+		stored.LastError = ""
+		stored.UpdatedAt = now
+	})
+}
+
+// Retry makes a claimed job due again at runAt
+func (m *MemoryQueue) Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error {
+	return m.settle(job, func(stored *Job) {
+		stored.RunAt = runAt
+		stored.LastError = lastError
+		stored.UpdatedAt = time.Now().UTC()
+	})
+}
+
+// Bury marks a claimed job dead
+func (m *MemoryQueue) Bury(ctx context.Context, job *Job, now time.Time, lastError string) error {
+	return m.settle(job, func(stored *Job) {
+		stored.Status = StatusDead
+		stored.LastError = lastError
+		stored.UpdatedAt = now
+	})
+}
+
+// Get returns a job by ID
+func (m *MemoryQueue) Get(ctx context.Context, id uuid.UUID) (*Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, exists := m.jobs[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyJob(job), nil
+}
+
+// DeleteFinished removes done and dead jobs last updated before the given time
+func (m *MemoryQueue) DeleteFinished(ctx context.Context, before time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for id, job := range m.jobs {
+		if job.Status != StatusPending && job.UpdatedAt.Before(before) {
+			delete(m.jobs, id)
+		}
+	}
+	return nil
+}
+
+// settle applies an update to a claimed job, provided the caller's claim is
+// still the current one
+func (m *MemoryQueue) settle(job *Job, update func(stored *Job)) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.jobs[job.ID]
+	if !exists {
+		return ErrNotFound
+	}
+	if stored.Status != StatusPending || stored.Lease != job.Lease {
+		return ErrLeaseLost
+	}
+	update(stored)
+	return nil
+}
+
+// claimsBefore reports whether job a is claimed before job b
+func claimsBefore(a, b *Job) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if !a.RunAt.Equal(b.RunAt) {
+		return a.RunAt.Before(b.RunAt)
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// copyJob returns a deep copy so callers can't modify stored jobs
+func copyJob(job *Job) *Job {
+	jobCopy := *job
+	jobCopy.Payload = append([]byte(nil), job.Payload...)
+	return &jobCopy
+}
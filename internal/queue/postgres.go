@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// jobColumns lists the jobs columns in scanJob order
+const jobColumns = `id, kind, payload, priority, status, attempts, max_attempts, run_at, lease,
+	last_error, created_at, updated_at`
+
+// PostgresQueue implements Queue using the jobs table
+type PostgresQueue struct {
+	db *sql.DB
+}
+
+// NewPostgresQueue creates a new PostgresQueue
+func NewPostgresQueue(db *sql.DB) *PostgresQueue {
+	return &PostgresQueue{
+		db: db,
+	}
+}
+
+// Enqueue stores a new pending job, unless its ID is already stored
+func (p *PostgresQueue) Enqueue(ctx context.Context, job *Job) error {
+	// The payload is passed as text, as lib/pq sends []byte as bytea
+	_, err := p.db.ExecContext(ctx, `
+		INSERT INTO jobs (`+jobColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO NOTHING`,
+		job.ID, job.Kind, string(job.Payload), job.Priority, job.Status, job.Attempts, job.MaxAttempts,
+		job.RunAt, job.Lease, job.LastError, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return nil
+}
+
+// Claim returns the due job with the highest priority and postpones it by
+// visibility. Rows claimed by a concurrent worker are skipped rather than
+// waited for.
+func (p *PostgresQueue) Claim(ctx context.Context, kinds []string, now time.Time, visibility time.Duration) (*Job, error) {
+	row := p.db.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET attempts = attempts + 1, lease = $3, run_at = $4, updated_at = $1
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'pending' AND run_at <= $1 AND kind = ANY($2)
+			ORDER BY priority DESC, run_at, created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns,
+		now, pq.Array(kinds), uuid.New(), now.Add(visibility))
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// Complete marks a claimed job done
+func (p *PostgresQueue) Complete(ctx context.Context, job *Job, now time.Time) error {
+	return p.settle(ctx, job, `status = 'done', last_error = '', updated_at = $3`, now)
+}
+
+// Retry makes a claimed job due again at runAt
+func (p *PostgresQueue) Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error {
+	return p.settle(ctx, job, `run_at = $3, last_error = $4, updated_at = NOW()`, runAt, lastError)
+}
+
+// Bury marks a claimed job dead
+func (p *PostgresQueue) Bury(ctx context.Context, job *Job, now time.Time, lastError string) error {
+	return p.settle(ctx, job, `status = 'dead', updated_at = $3, last_error = $4`, now, lastError)
+}
+
+// Get returns a job by ID
+func (p *PostgresQueue) Get(ctx context.Context, id uuid.UUID) (*Job, error) {
+	row := p.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id)
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// DeleteFinished removes done and dead jobs last updated before the given time
+func (p *PostgresQueue) DeleteFinished(ctx context.Context, before time.Time) error {
+	_, err := p.db.ExecContext(ctx, `
+		DELETE FROM jobs
+		WHERE status IN ('done', 'dead') AND updated_at < $1`,
+		before)
+	if err != nil {
+		return fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return nil
+}
+
+// settle applies an update to a claimed job, provided the caller's claim is
+// still the current one. The assignments may use $3 onwards for args.
+func (p *PostgresQueue) settle(ctx context.Context, job *Job, assignments string, args ...any) error {
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET `+assignments+`
+		WHERE id = $1 AND lease = $2 AND status = 'pending'`,
+		append([]any{job.ID, job.Lease}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanJob reads a row selected with jobColumns
+func scanJob(row rowScanner) (*Job, error) {
+	var job Job
+	err := row.Scan(&job.ID, &job.Kind, &job.Payload, &job.Priority, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.RunAt, &job.Lease, &job.LastError, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,119 @@
+// Package queue is a durable queue for background work that survives
+// restarts and is shared by every replica without a broker. A Worker claims
+// due jobs for a visibility timeout, during which no other worker sees them,
+// runs the handler registered for their kind and then completes them, retries
+// them with exponential backoff or, once their attempts are used up, buries
+// them. Jobs whose worker dies become visible again when the timeout passes.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses
+const (
+	StatusPending = "pending" // Waiting to run, or claimed until RunAt
+	StatusDone    = "done"    // Handled successfully
+	StatusDead    = "dead"    // Gave up after a permanent failure or the last attempt
+)
+
+// Job priorities; jobs with a higher priority are claimed first
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+// DefaultMaxAttempts is the number of attempts given to jobs that don't set
+// their own
+const DefaultMaxAttempts = 5
+
+var (
+	// ErrNotFound is returned for unknown jobs
+	ErrNotFound = errors.New("job not found")
+
+	// ErrLeaseLost is returned when settling a job whose claim has expired,
+	// as it may have been claimed again by another worker
+	ErrLeaseLost = errors.New("job lease lost")
+)
+
+// Job is a unit of background work
+type Job struct {
+	ID          uuid.UUID
+	Kind        string // Selects the handler
+	Payload     []byte // JSON-encoded handler input
+	Priority    int
+	Status      string
+	Attempts    int // Claims so far, including the current one
+	MaxAttempts int
+	RunAt       time.Time // When the job is next due; pushed back while it is claimed
+	Lease       uuid.UUID // Identifies the current claim
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewJob creates a pending job of the given kind, due now, with the payload
+// encoded as JSON
+func NewJob(kind string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	return &Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     data,
+		Priority:    PriorityNormal,
+		Status:      StatusPending,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// Decode decodes the job payload into v
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s job payload: %w", j.Kind, err)
+	}
+	return nil
+}
+
+// Queue persists jobs
+type Queue interface {
+	// Enqueue stores a new pending job. A job whose ID is already stored is
+	// left as it is, so enqueueing can be repeated safely.
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Claim returns the pending job of one of the given kinds that is due at
+	// now and has the highest priority, oldest first, or nil if there is none.
+	// The job's attempts are counted, it gets a new lease and it is postponed
+	// by visibility so that no other worker claims it while it runs.
+	Claim(ctx context.Context, kinds []string, now time.Time, visibility time.Duration) (*Job, error)
+
+	// Complete marks a claimed job done
+	Complete(ctx context.Context, job *Job, now time.Time) error
+
+	// Retry makes a claimed job due again at runAt, recording why it failed
+	Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error
+
+	// Bury marks a claimed job dead, recording why it failed
+	Bury(ctx context.Context, job *Job, now time.Time, lastError string) error
+
+	// Get returns a job by ID
+	Get(ctx context.Context, id uuid.UUID) (*Job, error)
+
+	// DeleteFinished removes done and dead jobs last updated before the given
+	// time
+	DeleteFinished(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,267 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestJob(t *testing.T, kind string, priority int, runAt time.Time) *Job {
+	job, err := NewJob(kind, map[string]string{"kind": kind})
+	require.NoError(t, err)
+	job.Priority = priority
+	job.RunAt = runAt
+	return job
+}
+
+func TestMemoryQueue_Claim(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	low := newTestJob(t, "resize", PriorityLow, now.Add(-2*time.Minute))
+	older := newTestJob(t, "resize", PriorityNormal, now.Add(-time.Minute))
+	newer := newTestJob(t, "resize", PriorityNormal, now)
+	later := newTestJob(t, "resize", PriorityHigh, now.Add(time.Minute))
+	other := newTestJob(t, "other", PriorityHigh, now)
+	for _, job := range []*Job{low, older, newer, later, other} {
+		require.NoError(t, q.Enqueue(ctx, job))
+	}
+
+	// Enqueueing a stored job again leaves it as it is
+	repeated := *low
+	repeated.Priority = PriorityHigh
+	require.NoError(t, q.Enqueue(ctx, &repeated))
+	stored, err := q.Get(ctx, low.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PriorityLow, stored.Priority)
+
+	// Due jobs of the given kinds come by priority, then oldest first
+	var claimed []*Job
+	for {
+		job, err := q.Claim(ctx, []string{"resize"}, now, time.Minute)
+		require.NoError(t, err)
+		if job == nil {
+			break
+		}
+		claimed = append(claimed, job)
+	}
+	require.Len(t, claimed, 3)
+	assert.Equal(t, older.ID, claimed[0].ID)
+	assert.Equal(t, newer.ID, claimed[1].ID)
+	assert.Equal(t, low.ID, claimed[2].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.Equal(t, now.Add(time.Minute), claimed[0].RunAt)
+
+	// Claimed jobs are visible again once their visibility timeout passes,
+	// under a new lease
+	again, err := q.Claim(ctx, []string{"resize"}, now.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Equal(t, later.ID, again.ID)
+
+	again, err = q.Claim(ctx, []string{"resize"}, now.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Equal(t, older.ID, again.ID)
+	assert.Equal(t, 2, again.Attempts)
+	assert.NotEqual(t, claimed[0].Lease, again.Lease)
+
+	// The expired claim can no longer settle the job
+	assert.ErrorIs(t, q.Complete(ctx, claimed[0], now), ErrLeaseLost)
+	require.NoError(t, q.Complete(ctx, again, now))
+	assert.ErrorIs(t, q.Complete(ctx, again, now), ErrLeaseLost)
+
+	stored, err = q.Get(ctx, older.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDone, stored.Status)
+
+	// Retried jobs are due at the given time; buried ones never again
+	require.NoError(t, q.Retry(ctx, claimed[1], now.Add(time.Hour), "boom"))
+	require.NoError(t, q.Bury(ctx, claimed[2], now, "fatal"))
+	stored, err = q.Get(ctx, newer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Equal(t, "boom", stored.LastError)
+	assert.Equal(t, now.Add(time.Hour), stored.RunAt)
+
+	// Only done and dead jobs are deleted
+	require.NoError(t, q.DeleteFinished(ctx, now.Add(time.Second)))
+	_, err = q.Get(ctx, older.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = q.Get(ctx, low.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = q.Get(ctx, newer.ID)
+	assert.NoError(t, err)
+}
+
+func TestWorker(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	config := DefaultConfig()
+	config.BaseBackoff = time.Nanosecond
+	config.MaxBackoff = time.Nanosecond
+	worker := NewWorker(q, config, zap.NewNop().Sugar())
+
+	calls := map[string]int{}
+	worker.Handle("ok", func(ctx context.Context, job *Job) error {
+		calls["ok"]++
+		var payload map[string]string
+		require.NoError(t, job.Decode(&payload))
+		assert.Equal(t, "ok", payload["kind"])
+		return nil
+	})
+	worker.Handle("flaky", func(ctx context.Context, job *Job) error {
+		calls["flaky"]++
+		if job.Attempts < 3 {
+			return errors.New("try again")
+		}
+		return nil
+	})
+	worker.Handle("failing", func(ctx context.Context, job *Job) error {
+		calls["failing"]++
+		return errors.New("always")
+	})
+	worker.Handle("permanent", func(ctx context.Context, job *Job) error {
+		calls["permanent"]++
+		return Permanent(errors.New("bad input"))
+	})
+	worker.Handle("panicking", func(ctx context.Context, job *Job) error {
+		calls["panicking"]++
+		panic("boom")
+	})
+
+	jobs := map[string]*Job{}
+	for _, kind := range []string{"ok", "flaky", "failing", "permanent", "panicking"} {
+		jobs[kind] = newTestJob(t, kind, PriorityNormal, time.Now().UTC())
+		if kind == "failing" {
+			jobs[kind].MaxAttempts = 2
+		}
+		require.NoError(t, q.Enqueue(ctx, jobs[kind]))
+	}
+
+	processed, err := worker.Drain(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1+3+2+1+DefaultMaxAttempts, processed)
+	assert.Equal(t, map[string]int{"ok": 1, "flaky": 3, "failing": 2, "permanent": 1, "panicking": DefaultMaxAttempts}, calls)
+
+	status := func(kind string) *Job {
+		job, err := q.Get(ctx, jobs[kind].ID)
+		require.NoError(t, err)
+		return job
+	}
+	assert.Equal(t, StatusDone, status("ok").Status)
+	assert.Equal(t, StatusDone, status("flaky").Status)
+	assert.Equal(t, StatusDead, status("failing").Status)
+	assert.Equal(t, "always", status("failing").LastError)
+	assert.Equal(t, StatusDead, status("permanent").Status)
+	assert.Equal(t, 1, status("permanent").Attempts)
+	assert.Equal(t, StatusDead, status("panicking").Status)
+	assert.Contains(t, status("panicking").LastError, "panicked")
+
+	// Jobs of kinds without a handler are left for other workers
+	unhandled := newTestJob(t, "unhandled", PriorityNormal, time.Now().UTC())
+	require.NoError(t, q.Enqueue(ctx, unhandled))
+	processed, err = worker.Drain(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+}
+
+func TestWorker_Backoff(t *testing.T) {
+	config := DefaultConfig()
+	config.BaseBackoff = 10 * time.Second
+	config.MaxBackoff = time.Minute
+	worker := NewWorker(NewMemoryQueue(), config, zap.NewNop().Sugar())
+
+	assert.Equal(t, 10*time.Second, worker.backoff(1))
+	assert.Equal(t, 20*time.Second, worker.backoff(2))
+	assert.Equal(t, 40*time.Second, worker.backoff(3))
+	assert.Equal(t, time.Minute, worker.backoff(4))
+	assert.Equal(t, time.Minute, worker.backoff(20))
+}
+
+func TestWorker_RetryAt(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	runAt := now.Add(time.Hour)
+
+	worker := NewWorker(q, DefaultConfig(), zap.NewNop().Sugar())
+	worker.Handle("scheduled", func(ctx context.Context, job *Job) error {
+		return RetryAt(errors.New("not yet"), runAt)
+	})
+
+	job := newTestJob(t, "scheduled", PriorityNormal, now)
+	require.NoError(t, q.Enqueue(ctx, job))
+	processed, err := worker.Drain(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	// The job is due when the handler asked, not after the worker's backoff
+	stored, err := q.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Equal(t, "not yet", stored.LastError)
+	assert.Equal(t, runAt, stored.RunAt)
+}
+
+func TestWorker_Run(t *testing.T) {
+	q := NewMemoryQueue()
+	config := DefaultConfig()
+	config.Workers = 2
+	config.PollInterval = 10 * time.Millisecond
+	worker := NewWorker(q, config, zap.NewNop().Sugar())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Int32
+	worker.Handle("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-release
+		finished.Add(1)
+		return ctx.Err()
+	})
+
+	job := newTestJob(t, "slow", PriorityNormal, time.Now().UTC())
+	require.NoError(t, q.Enqueue(context.Background(), job))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not picked up")
+	}
+
+	// Stopping waits for the job in progress, which isn't cancelled
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("worker stopped before the job finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+	assert.Equal(t, int32(1), finished.Load())
+
+	stored, err := q.Get(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDone, stored.Status)
+}
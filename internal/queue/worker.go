@@ -0,0 +1,247 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// purgeInterval is how often finished jobs past retention are removed
+const purgeInterval = 10 * time.Minute
+
+// Handler processes a job. A returned error fails the attempt; the job is
+// retried unless the error is Permanent or the job is out of attempts.
+type Handler func(ctx context.Context, job *Job) error
+
+// Config holds the polling and retry settings of a Worker
+type Config struct {
+	Workers      int           // Jobs processed concurrently
+	PollInterval time.Duration // Wait before polling again when no job is due
+	Visibility   time.Duration // How long a claimed job is hidden from other workers; handlers are cancelled after it
+	BaseBackoff  time.Duration // Delay after the first failed attempt, doubled after each further one
+	MaxBackoff   time.Duration // Upper bound for the delay between attempts
+	Retention    time.Duration // How long done and dead jobs are kept
+}
+
+// DefaultConfig returns the default worker settings
+func DefaultConfig() Config {
+	return Config{
+		Workers:      4,
+		PollInterval: time.Second,
+		Visibility:   10 * time.Minute,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		Retention:    24 * time.Hour,
+	}
+}
+
+// permanentError marks a failure that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps a handler error so the job is buried rather than retried
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// retryError marks a failure to retry at a time chosen by the handler
+type retryError struct {
+	err   error
+	runAt time.Time
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+func (e *retryError) Unwrap() error { return e.err }
+
+// RetryAt wraps a handler error so the job is retried at runAt rather than
+// after the worker's backoff. It is still buried once its attempts are used up.
+func RetryAt(err error, runAt time.Time) error {
+	return &retryError{err: err, runAt: runAt}
+}
+
+// Worker runs the handlers registered for each job kind
+type Worker struct {
+	queue    Queue
+	config   Config
+	logger   *zap.SugaredLogger
+	handlers map[string]Handler
+	now      func() time.Time
+}
+
+// NewWorker creates a worker for the jobs of the queue. Handlers must be
+// registered before it is run.
+func NewWorker(queue Queue, config Config, logger *zap.SugaredLogger) *Worker {
+	return &Worker{
+		queue:    queue,
+		config:   config,
+		logger:   logger,
+		handlers: make(map[string]Handler),
+		now:      time.Now,
+	}
+}
+
+// Handle registers the handler for a job kind. Only kinds with a handler are
+// claimed, so replicas may handle different kinds.
+func (w *Worker) Handle(kind string, handler Handler) {
+	w.handlers[kind] = handler
+}
+
+// Run processes jobs with Config.Workers goroutines until ctx is done. Jobs
+// in progress when ctx is done are finished before it returns; their
+// handlers are only bounded by the visibility timeout.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.poll(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case now := <-ticker.C:
+			if err := w.queue.DeleteFinished(ctx, now.Add(-w.config.Retention)); err != nil && ctx.Err() == nil {
+				w.logger.Errorw("Failed to delete finished jobs", "error", err)
+			}
+		}
+	}
+}
+
+// Drain processes due jobs in the calling goroutine until none are left and
+// returns how many it processed
+func (w *Worker) Drain(ctx context.Context) (int, error) {
+	processed := 0
+	for {
+		found, err := w.processNext(ctx)
+		if err != nil || !found {
+			return processed, err
+		}
+		processed++
+	}
+}
+
+// poll processes jobs back to back while any are due, and waits for the poll
+// interval when none are
+func (w *Worker) poll(ctx context.Context) {
+	for {
+		found, err := w.processNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Errorw("Failed to claim job", "error", err)
+		}
+		if found && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.config.PollInterval):
+		}
+	}
+}
+
+// processNext claims and processes one due job, reporting whether there was one
+func (w *Worker) processNext(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	kinds := make([]string, 0, len(w.handlers))
+	for kind := range w.handlers {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+
+	job, err := w.queue.Claim(ctx, kinds, w.now().UTC(), w.config.Visibility)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	// A job is seen through once claimed, even if ctx is done meanwhile
+	w.process(context.WithoutCancel(ctx), job)
+	return true, nil
+}
+
+// process runs a claimed job's handler and settles the job with the outcome
+func (w *Worker) process(ctx context.Context, job *Job) {
+	err := w.run(ctx, job)
+	now := w.now().UTC()
+
+	var settleErr error
+	switch {
+	case err == nil:
+		settleErr = w.queue.Complete(ctx, job, now)
+	case errors.As(err, new(*permanentError)) || job.Attempts >= job.MaxAttempts:
+		w.logger.Warnw("Job failed permanently",
+			"error", err,
+			"jobID", job.ID,
+			"kind", job.Kind,
+			"attempts", job.Attempts)
+		settleErr = w.queue.Bury(ctx, job, now, err.Error())
+	default:
+		w.logger.Infow("Job failed, retrying",
+			"error", err,
+			"jobID", job.ID,
+			"kind", job.Kind,
+			"attempts", job.Attempts)
+		runAt := now.Add(w.backoff(job.Attempts))
+		var retry *retryError
+		if errors.As(err, &retry) {
+			runAt = retry.runAt
+		}
+		settleErr = w.queue.Retry(ctx, job, runAt, err.Error())
+	}
+
+	if settleErr != nil {
+		// The job becomes visible again once its claim expires
+		w.logger.Errorw("Failed to settle job",
+			"error", settleErr,
+			"jobID", job.ID,
+			"kind", job.Kind)
+	}
+}
+
+// run calls the handler for a job within its visibility timeout. Panics fail
+// the attempt rather than the worker.
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.config.Visibility)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.config.BaseBackoff
+	for i := 1; i < attempts && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, w.config.MaxBackoff)
+}
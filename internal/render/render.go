@@ -0,0 +1,141 @@
+// Package render parses and signs the options of on-the-fly image renditions.
+//
+// A rendition URL has the form /v1/render/{signature}/{options}/{imageGuid},
+// where options is a comma-separated list of key:value pairs, for example
+// "w:300,h:200,fit:cover,f:png". The signature is the unpadded base64url
+// HMAC-SHA256 of "{options}/{imageGuid}", so only holders of the signing key
+// can request new renditions.
+package render
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// PathPrefix is the route prefix rendition URLs are served under
+const PathPrefix = "/v1/render"
+
+// ErrInvalidOptions is returned for malformed or out-of-range render options
+var ErrInvalidOptions = errors.New("invalid render options")
+
+// ParseOptions parses a rendition options string. Keys may appear in any order;
+// omitted keys take their defaults (fit contain, format jpeg, default quality).
+//
+//	w    width in pixels
+//	h    height in pixels
+//	fit  contain, cover or fill
+//	f    jpeg or png
+//	q    JPEG quality, 1-100
+func ParseOptions(value string) (domain.RenderOptions, error) {
+	opts := domain.RenderOptions{
+		Fit:     domain.FitContain,
+		Format:  domain.FormatJPEG,
+		Quality: domain.DefaultRenderQuality,
+	}
+
+	if value == "" {
+		return opts, fmt.Errorf("%w: options are empty", ErrInvalidOptions)
+	}
+
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(pair, ":")
+		if !found || val == "" {
+			return opts, fmt.Errorf("%w: %q is not key:value", ErrInvalidOptions, pair)
+		}
+		if seen[key] {
+			return opts, fmt.Errorf("%w: %q given twice", ErrInvalidOptions, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "w":
+			opts.Width, err = parseInt(key, val, 1, domain.MaxRenderDimension)
+		case "h":
+			opts.Height, err = parseInt(key, val, 1, domain.MaxRenderDimension)
+		case "q":
+			opts.Quality, err = parseInt(key, val, 1, 100)
+		case "fit":
+			switch val {
+			case domain.FitContain, domain.FitCover, domain.FitFill:
+				opts.Fit = val
+			default:
+				err = fmt.Errorf("%w: unknown fit %q", ErrInvalidOptions, val)
+			}
+		case "f":
+			switch val {
+			case domain.FormatJPEG, domain.FormatPNG:
+				opts.Format = val
+			default:
+				err = fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, val)
+			}
+		default:
+			err = fmt.Errorf("%w: unknown option %q", ErrInvalidOptions, key)
+		}
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	// Cover and fill need a box to cover or fill
+	if opts.Fit != domain.FitContain && (opts.Width == 0 || opts.Height == 0) {
+		return opts, fmt.Errorf("%w: fit %s needs both w and h", ErrInvalidOptions, opts.Fit)
+	}
+
+	return opts, nil
+}
+
+// Signer signs and verifies rendition URLs with an HMAC key
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a new Signer
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Sign returns the signature of an options string for an image
+func (s *Signer) Sign(options string, imageGUID uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(s.mac(options, imageGUID))
+}
+
+// Verify reports whether signature is valid for the options string and image
+func (s *Signer) Verify(signature, options string, imageGUID uuid.UUID) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(decoded, s.mac(options, imageGUID))
+}
+
+// Path returns the signed rendition path for an image
+func (s *Signer) Path(opts domain.RenderOptions, imageGUID uuid.UUID) string {
+	options := opts.String()
+	return fmt.Sprintf("%s/%s/%s/%s", PathPrefix, s.Sign(options, imageGUID), options, imageGUID)
+}
+
+// mac computes the HMAC of the signed part of a rendition path
+func (s *Signer) mac(options string, imageGUID uuid.UUID) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(options + "/" + imageGUID.String()))
+	return mac.Sum(nil)
+}
+
+// parseInt parses an integer option within [lo, hi]
+func parseInt(key, value string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidOptions, key, lo, hi)
+	}
+	return n, nil
+}
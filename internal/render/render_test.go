@@ -0,0 +1,74 @@
+package render
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    domain.RenderOptions
+		wantErr bool
+	}{
+		{
+			value: "w:300",
+			want:  domain.RenderOptions{Width: 300, Fit: domain.FitContain, Format: domain.FormatJPEG, Quality: domain.DefaultRenderQuality},
+		},
+		{
+			value: "f:png,fit:cover,h:200,w:300,q:70",
+			want:  domain.RenderOptions{Width: 300, Height: 200, Fit: domain.FitCover, Format: domain.FormatPNG, Quality: 70},
+		},
+		{value: "", wantErr: true},
+		{value: "w", wantErr: true},
+		{value: "w:0", wantErr: true},
+		{value: "w:5000", wantErr: true},
+		{value: "w:300,w:400", wantErr: true},
+		{value: "q:101", wantErr: true},
+		{value: "fit:stretch", wantErr: true},
+		{value: "f:gif", wantErr: true},
+		{value: "blur:5", wantErr: true},
+		{value: "w:300,fit:cover", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			opts, err := ParseOptions(tt.value)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidOptions))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, opts)
+
+			// The canonical form parses back to the same options
+			again, err := ParseOptions(opts.String())
+			require.NoError(t, err)
+			assert.Equal(t, opts, again)
+		})
+	}
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner([]byte("render-key"))
+	imageGUID := uuid.New()
+
+	signature := signer.Sign("w:300", imageGUID)
+	assert.True(t, signer.Verify(signature, "w:300", imageGUID))
+
+	// The signature covers both the options and the image
+	assert.False(t, signer.Verify(signature, "w:3000", imageGUID))
+	assert.False(t, signer.Verify(signature, "w:300", uuid.New()))
+	assert.False(t, signer.Verify("not-base64!", "w:300", imageGUID))
+	assert.False(t, NewSigner([]byte("other-key")).Verify(signature, "w:300", imageGUID))
+
+	opts := domain.RenderOptions{Width: 120, Fit: domain.FitContain, Format: domain.FormatPNG, Quality: 90}
+	assert.Equal(t,
+		PathPrefix+"/"+signer.Sign(opts.String(), imageGUID)+"/"+opts.String()+"/"+imageGUID.String(),
+		signer.Path(opts, imageGUID))
+}
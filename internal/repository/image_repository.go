@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,46 +17,151 @@ var (
 	ErrNotFound      = errors.New("image not found")
 	ErrAlreadyExists = errors.New("image already exists")
 	ErrDatabase      = errors.New("database error")
+	ErrConflict      = errors.New("image was modified concurrently")
+	ErrLimitReached  = errors.New("image limit reached")
 )
 
 // ImageRepository defines the operations for image metadata storage
 type ImageRepository interface {
-	// SaveImage saves a new image or updates an existing one
-	SaveImage(ctx context.Context, image *domain.Image) error
+	// SaveImage saves a new image or updates an existing one, recording events
+	// in the outbox in the same transaction
+	SaveImage(ctx context.Context, image *domain.Image, events ...domain.ImageEvent) error
+
+	// AppendImage adds a new image to the end of the owner's collection of its
+	// type, making it primary if it is the first. The position and primary
+	// flag are set on the image. If the owner already has maxImages images
+	// (zero for no limit) ErrLimitReached is returned; if another write to
+	// the collection gets in the way, ErrConflict. Events are recorded in the
+	// outbox in the same transaction.
+	AppendImage(ctx context.Context, image *domain.Image, maxImages int, events ...domain.ImageEvent) error
 
 	// GetImageByID retrieves an image by its GUID
 	GetImageByID(ctx context.Context, imageGUID uuid.UUID) (*domain.Image, error)
 
-	// GetImageByOwner retrieves an image by owner GUID and type
+	// GetImageByOwner retrieves an image by owner GUID and type.
+	// For collection types the primary image is returned.
 	GetImageByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) (*domain.Image, error)
 
+	// GetImagesByOwners retrieves the image of a type for each of several owners,
+	// keyed by owner GUID; owners without an image are absent from the map.
+	// For collection types the primary image is returned.
+	GetImagesByOwners(ctx context.Context, ownerGUIDs []uuid.UUID, typeName string) (map[uuid.UUID]*domain.Image, error)
+
+	// ListImagesByOwner lists all images of a type for an owner, ordered by position
+	ListImagesByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) ([]*domain.Image, error)
+
+	// UpdateImagePositions sets each image's position to its index in imageGUIDs
+	UpdateImagePositions(ctx context.Context, ownerGUID uuid.UUID, typeName string, imageGUIDs []uuid.UUID) error
+
+	// SetPrimaryImage marks one image as primary and clears the flag on the owner's other images of the type
+	SetPrimaryImage(ctx context.Context, ownerGUID uuid.UUID, typeName string, imageGUID uuid.UUID) error
+
+	// ReplaceImage atomically replaces the owner's current image, as read by the
+	// caller, with a new one; current is nil if the owner had no image. If the
+	// owner's image has changed since (including being added or deleted),
+	// ErrConflict is returned and nothing is written. Events are recorded in
+	// the outbox in the same transaction.
+	ReplaceImage(ctx context.Context, current, replacement *domain.Image, events ...domain.ImageEvent) error
+
 	// DeleteImage deletes an image by its GUID
 	DeleteImage(ctx context.Context, imageGUID uuid.UUID) error
 
-	// DeleteImageByOwner deletes an image by owner GUID and type
+	// DeleteImageVersion deletes an image by its GUID provided its version is
+	// unchanged, returning ErrConflict if it has changed. Events are recorded
+	// in the outbox in the same transaction.
+	DeleteImageVersion(ctx context.Context, imageGUID uuid.UUID, version int64, events ...domain.ImageEvent) error
+
+	// DeleteImageByOwner deletes all images of a type for an owner
 	DeleteImageByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) error
 
 	// ListImagesByType lists all images of a specific type
 	ListImagesByType(ctx context.Context, typeName string, limit, offset int) ([]*domain.Image, error)
+
+	// ListExpiredOriginals lists up to limit images of a type created before
+	// the given time that still reference an original, oldest first
+	ListExpiredOriginals(ctx context.Context, typeName string, before time.Time, limit int) ([]*domain.Image, error)
+
+	// ClearOriginal removes an image's reference to its original, provided it
+	// still references originalKey, incrementing its version
+	ClearOriginal(ctx context.Context, imageGUID uuid.UUID, originalKey string) error
+
+	// ListImages lists up to limit images matching the filter, most recently
+	// updated first, starting after the filter's cursor
+	ListImages(ctx context.Context, filter ImageFilter, limit int) ([]*domain.Image, error)
+}
+
+// ImageFilter selects images for ListImages. Zero-valued fields match every image.
+type ImageFilter struct {
+	TypeName    string
+	OwnerGUID   uuid.UUID
+	ContentType string
+
+	// Date ranges; the lower bounds are inclusive and the upper bounds exclusive
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+
+	// After continues a listing after the last image of the previous page
+	After *ImageCursor
+}
+
+// ImageCursor is a position in the listing order of ListImages
+type ImageCursor struct {
+	UpdatedAt time.Time
+	GUID      uuid.UUID
+}
+
+// CursorAfter returns the cursor positioned just after image
+func CursorAfter(image *domain.Image) *ImageCursor {
+	return &ImageCursor{UpdatedAt: image.UpdatedAt, GUID: image.GUID}
+}
+
+// Matches reports whether image passes every condition of the filter
+func (f ImageFilter) Matches(image *domain.Image) bool {
+	switch {
+	case f.TypeName != "" && image.TypeName != f.TypeName,
+		f.OwnerGUID != uuid.Nil && image.OwnerGUID != f.OwnerGUID,
+		f.ContentType != "" && image.ContentType != f.ContentType,
+		!f.CreatedFrom.IsZero() && image.CreatedAt.Before(f.CreatedFrom),
+		!f.CreatedTo.IsZero() && !image.CreatedAt.Before(f.CreatedTo),
+		!f.UpdatedFrom.IsZero() && image.UpdatedAt.Before(f.UpdatedFrom),
+		!f.UpdatedTo.IsZero() && !image.UpdatedAt.Before(f.UpdatedTo):
+		return false
+	case f.After != nil:
+		return listedBefore(f.After, image)
+	default:
+		return true
+	}
+}
+
+// listedBefore reports whether the cursor position sorts before image in the
+// ListImages order: updated_at descending, then GUID descending
+func listedBefore(cursor *ImageCursor, image *domain.Image) bool {
+	if !image.UpdatedAt.Equal(cursor.UpdatedAt) {
+		return image.UpdatedAt.Before(cursor.UpdatedAt)
+	}
+	return bytes.Compare(image.GUID[:], cursor.GUID[:]) < 0
 }
 
 // MockImageRepository implements ImageRepository for testing
 type MockImageRepository struct {
 	mutex  sync.RWMutex
 	images map[uuid.UUID]*domain.Image
-	byOwner map[string]*domain.Image // key is ownerGUID + typeName
+	outbox []*outboxEntry
+
+	relayMutex sync.Mutex
 }
 
 // NewMockImageRepository creates a new MockImageRepository
 func NewMockImageRepository() *MockImageRepository {
 	return &MockImageRepository{
 		images: make(map[uuid.UUID]*domain.Image),
-		byOwner: make(map[string]*domain.Image),
 	}
 }
 
 // SaveImage saves a new image or updates an existing one
-func (m *MockImageRepository) SaveImage(ctx context.Context, image *domain.Image) error {
+func (m *MockImageRepository) SaveImage(ctx context.Context, image *domain.Image, events ...domain.ImageEvent) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -69,19 +176,22 @@ func (m *MockImageRepository) SaveImage(ctx context.Context, image *domain.Image
 		return errors.New("type name is required")
 	}
 
-	// Update timestamps
+	// Update timestamps and version
 	now := time.Now().UTC()
 	if image.CreatedAt.IsZero() {
 		image.CreatedAt = now
 	}
 	image.UpdatedAt = now
+	if stored, exists := m.images[image.GUID]; exists {
+		image.Version = stored.Version + 1
+	} else {
+		image.Version = 1
+	}
 
-	// Store by ID
-	m.images[image.GUID] = image
-
-	// Store by owner + type
-	ownerKey := ownerTypeKey(image.OwnerGUID, image.TypeName)
-	m.byOwner[ownerKey] = image
+	// Store a copy by ID so later changes by the caller don't leak in
+	imageCopy := *image
+	m.images[image.GUID] = &imageCopy
+	m.recordEvents(events)
 
 	return nil
 }
@@ -101,24 +211,170 @@ func (m *MockImageRepository) GetImageByID(ctx context.Context, imageGUID uuid.U
 	return &imageCopy, nil
 }
 
-// GetImageByOwner retrieves an image by owner GUID and type
+// GetImageByOwner retrieves an image by owner GUID and type.
+// For collection types the primary image is returned.
 func (m *MockImageRepository) GetImageByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) (*domain.Image, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	ownerKey := ownerTypeKey(ownerGUID, typeName)
-	image, exists := m.byOwner[ownerKey]
-	if !exists {
+	owned := m.ownedImages(ownerGUID, typeName)
+	if len(owned) == 0 {
 		return nil, ErrNotFound
 	}
 
+	// Prefer the primary image, then the lowest position
+	sort.SliceStable(owned, func(i, j int) bool {
+		if owned[i].IsPrimary != owned[j].IsPrimary {
+			return owned[i].IsPrimary
+		}
+		return owned[i].Position < owned[j].Position
+	})
+
 	// Return a copy to prevent modification of the stored image
-	imageCopy := *image
+	imageCopy := *owned[0]
 	return &imageCopy, nil
 }
 
-// DeleteImage deletes an image by its GUID
-func (m *MockImageRepository) DeleteImage(ctx context.Context, imageGUID uuid.UUID) error {
+// GetImagesByOwners retrieves the image of a type for each of several owners
+func (m *MockImageRepository) GetImagesByOwners(ctx context.Context, ownerGUIDs []uuid.UUID, typeName string) (map[uuid.UUID]*domain.Image, error) {
+	result := make(map[uuid.UUID]*domain.Image, len(ownerGUIDs))
+	for _, ownerGUID := range ownerGUIDs {
+		image, err := m.GetImageByOwner(ctx, ownerGUID, typeName)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[ownerGUID] = image
+	}
+
+	return result, nil
+}
+
+// ListImagesByOwner lists all images of a type for an owner, ordered by position
+func (m *MockImageRepository) ListImagesByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) ([]*domain.Image, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	owned := m.ownedImages(ownerGUID, typeName)
+	sort.SliceStable(owned, func(i, j int) bool {
+		if owned[i].Position != owned[j].Position {
+			return owned[i].Position < owned[j].Position
+		}
+		return owned[i].CreatedAt.Before(owned[j].CreatedAt)
+	})
+
+	result := make([]*domain.Image, 0, len(owned))
+	for _, image := range owned {
+		imageCopy := *image
+		result = append(result, &imageCopy)
+	}
+
+	return result, nil
+}
+
+// UpdateImagePositions sets each image's position to its index in imageGUIDs
+func (m *MockImageRepository) UpdateImagePositions(ctx context.Context, ownerGUID uuid.UUID, typeName string, imageGUIDs []uuid.UUID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Validate all images before changing anything
+	for _, imageGUID := range imageGUIDs {
+		image, exists := m.images[imageGUID]
+		if !exists || image.OwnerGUID != ownerGUID || image.TypeName != typeName {
+			return ErrNotFound
+		}
+	}
+
+	now := time.Now().UTC()
+	for position, imageGUID := range imageGUIDs {
+		m.images[imageGUID].Position = position
+		m.images[imageGUID].UpdatedAt = now
+		m.images[imageGUID].Version++
+	}
+
+	return nil
+}
+
+// SetPrimaryImage marks one image as primary and clears the flag on the owner's other images of the type
+func (m *MockImageRepository) SetPrimaryImage(ctx context.Context, ownerGUID uuid.UUID, typeName string, imageGUID uuid.UUID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	target, exists := m.images[imageGUID]
+	if !exists || target.OwnerGUID != ownerGUID || target.TypeName != typeName {
+		return ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	for _, image := range m.ownedImages(ownerGUID, typeName) {
+		isPrimary := image.GUID == imageGUID
+		if image.IsPrimary != isPrimary {
+			image.IsPrimary = isPrimary
+			image.UpdatedAt = now
+			image.Version++
+		}
+	}
+
+	return nil
+}
+
+// AppendImage atomically adds a new image to the end of the owner's collection
+func (m *MockImageRepository) AppendImage(ctx context.Context, image *domain.Image, maxImages int, events ...domain.ImageEvent) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	count := len(m.ownedImages(image.OwnerGUID, image.TypeName))
+	if maxImages > 0 && count >= maxImages {
+		return ErrLimitReached
+	}
+
+	now := time.Now().UTC()
+	if image.CreatedAt.IsZero() {
+		image.CreatedAt = now
+	}
+	image.UpdatedAt = now
+	image.Version = 1
+	image.Position = count
+	image.IsPrimary = count == 0
+	imageCopy := *image
+	m.images[image.GUID] = &imageCopy
+	m.recordEvents(events)
+
+	return nil
+}
+
+// ReplaceImage atomically replaces the owner's current image with a new one
+func (m *MockImageRepository) ReplaceImage(ctx context.Context, current, replacement *domain.Image, events ...domain.ImageEvent) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	version := int64(1)
+	if current == nil {
+		if len(m.ownedImages(replacement.OwnerGUID, replacement.TypeName)) > 0 {
+			return ErrConflict
+		}
+	} else {
+		stored, exists := m.images[current.GUID]
+		if !exists || stored.Version != current.Version {
+			return ErrConflict
+		}
+		delete(m.images, current.GUID)
+		version = stored.Version + 1
+	}
+
+	replacement.UpdatedAt = time.Now().UTC()
+	replacement.Version = version
+	imageCopy := *replacement
+	m.images[replacement.GUID] = &imageCopy
+	m.recordEvents(events)
+
+	return nil
+}
+
+// DeleteImageVersion deletes an image provided its version is unchanged
+func (m *MockImageRepository) DeleteImageVersion(ctx context.Context, imageGUID uuid.UUID, version int64, events ...domain.ImageEvent) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -126,34 +382,48 @@ func (m *MockImageRepository) DeleteImage(ctx context.Context, imageGUID uuid.UU
 	if !exists {
 		return ErrNotFound
 	}
+	if image.Version != version {
+		return ErrConflict
+	}
 
-	// Remove from both maps
 	delete(m.images, imageGUID)
-	ownerKey := ownerTypeKey(image.OwnerGUID, image.TypeName)
-	delete(m.byOwner, ownerKey)
+	m.recordEvents(events)
 
 	return nil
 }
 
-// DeleteImageByOwner deletes an image by owner GUID and type
+// DeleteImage deletes an image by its GUID
+func (m *MockImageRepository) DeleteImage(ctx context.Context, imageGUID uuid.UUID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.images[imageGUID]; !exists {
+		return ErrNotFound
+	}
+
+	delete(m.images, imageGUID)
+
+	return nil
+}
+
+// DeleteImageByOwner deletes all images of a type for an owner
 func (m *MockImageRepository) DeleteImageByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	ownerKey := ownerTypeKey(ownerGUID, typeName)
-	image, exists := m.byOwner[ownerKey]
-	if !exists {
+	owned := m.ownedImages(ownerGUID, typeName)
+	if len(owned) == 0 {
 		return ErrNotFound
 	}
 
-	// Remove from both maps
-	delete(m.images, image.GUID)
-	delete(m.byOwner, ownerKey)
+	for _, image := range owned {
+		delete(m.images, image.GUID)
+	}
 
 	return nil
 }
 
-// ListImagesByType lists all images of a specific type
+// ListImagesByType lists all images of a specific type, newest first
 func (m *MockImageRepository) ListImagesByType(ctx context.Context, typeName string, limit, offset int) ([]*domain.Image, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -167,6 +437,14 @@ func (m *MockImageRepository) ListImagesByType(ctx context.Context, typeName str
 		}
 	}
 
+	// Match the database ordering so pagination is stable
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].GUID.String() < result[j].GUID.String()
+	})
+
 	// Apply pagination
 	if offset >= len(result) {
 		return []*domain.Image{}, nil
@@ -180,6 +458,74 @@ func (m *MockImageRepository) ListImagesByType(ctx context.Context, typeName str
 	return result[offset:end], nil
 }
 
+// ListExpiredOriginals lists up to limit images of a type created before the
+// given time that still reference an original, oldest first
+func (m *MockImageRepository) ListExpiredOriginals(ctx context.Context, typeName string, before time.Time, limit int) ([]*domain.Image, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var result []*domain.Image
+	for _, image := range m.images {
+		if image.TypeName == typeName && image.OriginalKey != "" && image.CreatedAt.Before(before) {
+			imageCopy := *image
+			result = append(result, &imageCopy)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].GUID.String() < result[j].GUID.String()
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// ClearOriginal removes an image's reference to its original, provided it still references originalKey
+func (m *MockImageRepository) ClearOriginal(ctx context.Context, imageGUID uuid.UUID, originalKey string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if image, exists := m.images[imageGUID]; exists && image.OriginalKey == originalKey {
+		image.OriginalKey = ""
+		image.OriginalChecksum = ""
+		image.OriginalSize = 0
+		image.OriginalCrop = nil
+		image.Version++
+	}
+
+	return nil
+}
+
+// ListImages lists up to limit images matching the filter, most recently updated first
+func (m *MockImageRepository) ListImages(ctx context.Context, filter ImageFilter, limit int) ([]*domain.Image, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result := []*domain.Image{}
+	for _, image := range m.images {
+		if filter.Matches(image) {
+			imageCopy := *image
+			result = append(result, &imageCopy)
+		}
+	}
+
+	// Match the database ordering so cursors are stable
+	sort.Slice(result, func(i, j int) bool {
+		return listedBefore(CursorAfter(result[i]), result[j])
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
 // --- Test Helper Methods ---
 
 // GetImageCount returns the number of images in the mock repository
@@ -194,10 +540,15 @@ func (m *MockImageRepository) ClearImages() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	m.images = make(map[uuid.UUID]*domain.Image)
-	m.byOwner = make(map[string]*domain.Image)
 }
 
-// Helper function to create a key for owner + type lookups
-func ownerTypeKey(ownerGUID uuid.UUID, typeName string) string {
-	return ownerGUID.String() + ":" + typeName
+// ownedImages returns the stored images of a type for an owner; callers must hold the mutex
+func (m *MockImageRepository) ownedImages(ownerGUID uuid.UUID, typeName string) []*domain.Image {
+	var owned []*domain.Image
+	for _, image := range m.images {
+		if image.OwnerGUID == ownerGUID && image.TypeName == typeName {
+			owned = append(owned, image)
+		}
+	}
+	return owned
 }
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+)
+
+// Outbox gives access to the domain events recorded by image writes.
+//
+// SaveImage, ReplaceImage and DeleteImageVersion take the events caused by
+// the change they make and record them in the same transaction, so an event
+// is relayed if and only if its change was committed.
+type Outbox interface {
+	// RelayEvents passes up to limit unpublished events to publish, oldest
+	// first, and marks the ones it accepts as published. It stops at the first
+	// event publish fails and returns that error, so the event is retried
+	// before any later one. Events being relayed by a concurrent call are
+	// skipped. It returns the number of events published.
+	RelayEvents(ctx context.Context, limit int, publish func(domain.ImageEvent) error) (int, error)
+
+	// DeletePublishedEvents removes events published before the given time
+	DeletePublishedEvents(ctx context.Context, before time.Time) error
+}
+
+// outboxEntry is an event recorded by MockImageRepository
+type outboxEntry struct {
+	event       domain.ImageEvent
+	publishedAt *time.Time
+}
+
+// copyEvent copies an event along with its image
+func copyEvent(event domain.ImageEvent) domain.ImageEvent {
+	if event.Image != nil {
+		imageCopy := *event.Image
+		event.Image = &imageCopy
+	}
+	return event
+}
+
+// recordEvents appends events to the outbox; the caller must hold the write lock
+func (m *MockImageRepository) recordEvents(events []domain.ImageEvent) {
+	for _, event := range events {
+		m.outbox = append(m.outbox, &outboxEntry{event: copyEvent(event)})
+	}
+}
+
+// RelayEvents passes unpublished events to publish, oldest first
+func (m *MockImageRepository) RelayEvents(ctx context.Context, limit int, publish func(domain.ImageEvent) error) (int, error) {
+	// Relays take turns, as the outbox isn't locked while publishing
+	m.relayMutex.Lock()
+	defer m.relayMutex.Unlock()
+
+	m.mutex.RLock()
+	var pending []*outboxEntry
+	for _, entry := range m.outbox {
+		if len(pending) == limit {
+			break
+		}
+		if entry.publishedAt == nil {
+			pending = append(pending, entry)
+		}
+	}
+	m.mutex.RUnlock()
+
+	var published []*outboxEntry
+	var publishErr error
+	for _, entry := range pending {
+		if publishErr = publish(copyEvent(entry.event)); publishErr != nil {
+			break
+		}
+		published = append(published, entry)
+	}
+
+	m.mutex.Lock()
+	now := time.Now().UTC()
+	for _, entry := range published {
+		entry.publishedAt = &now
+	}
+	m.mutex.Unlock()
+
+	return len(published), publishErr
+}
+
+// DeletePublishedEvents removes events published before the given time
+func (m *MockImageRepository) DeletePublishedEvents(ctx context.Context, before time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	kept := m.outbox[:0]
+	for _, entry := range m.outbox {
+		if entry.publishedAt == nil || !entry.publishedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+	clear(m.outbox[len(kept):])
+	m.outbox = kept
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/lib/pq"
+)
+
+// insertEvents records events in the outbox as part of tx
+func insertEvents(ctx context.Context, tx *sql.Tx, events []domain.ImageEvent) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+		}
+
+		// The payload is passed as text, as lib/pq sends []byte as bytea
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO outbox (id, event_type, image_guid, payload, occurred_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			event.ID, event.Type, event.Image.GUID, string(payload), event.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+	}
+	return nil
+}
+
+// RelayEvents passes unpublished events to publish in the order they were
+// recorded. The events are locked until the published ones are marked, and
+// rows locked by a concurrent relay are skipped rather than waited for.
+func (r *PostgresImageRepository) RelayEvents(ctx context.Context, limit int, publish func(domain.ImageEvent) error) (int, error) {
+	var published []string
+	var publishErr error
+	err := r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT payload
+			FROM outbox
+			WHERE published_at IS NULL
+			ORDER BY seq
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED`,
+			limit)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		defer rows.Close()
+
+		var events []domain.ImageEvent
+		for rows.Next() {
+			var payload []byte
+			if err := rows.Scan(&payload); err != nil {
+				return fmt.Errorf("%w: %v", ErrDatabase, err)
+			}
+			var event domain.ImageEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return fmt.Errorf("failed to decode outbox event: %w", err)
+			}
+			events = append(events, event)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		for _, event := range events {
+			if publishErr = publish(event); publishErr != nil {
+				_, err := tx.ExecContext(ctx, `
+					UPDATE outbox
+					SET attempts = attempts + 1, last_error = $2
+					WHERE id = $1`,
+					event.ID, publishErr.Error())
+				if err != nil {
+					return fmt.Errorf("%w: %v", ErrDatabase, err)
+				}
+				break
+			}
+			published = append(published, event.ID.String())
+		}
+		if len(published) == 0 {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE outbox
+			SET published_at = $2
+			WHERE id = ANY($1::uuid[])`,
+			pq.Array(published), time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		return nil
+	})
+	if err != nil {
+		// Nothing was marked, so the events will be published again
+		return 0, err
+	}
+
+	return len(published), publishErr
+}
+
+// DeletePublishedEvents removes events published before the given time
+func (r *PostgresImageRepository) DeletePublishedEvents(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM outbox
+		WHERE published_at < $1`,
+		before)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
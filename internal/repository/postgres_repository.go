@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/antonrybalko/image-service-go/internal/domain"
@@ -12,6 +14,66 @@ import (
 	"github.com/lib/pq"
 )
 
+// imageColumns is the column list used by every image SELECT, in scanImage order
+const imageColumns = `guid, owner_guid, type_name, small_url, medium_url, large_url,
+	created_at, updated_at, content_type, original_width, original_height,
+	position, is_primary, alt_text, version, sizes_hash,
+	original_key, original_checksum, original_size, original_crop`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanImage scans a row selected with imageColumns into an Image
+func scanImage(row rowScanner) (*domain.Image, error) {
+	var image domain.Image
+	var crop []byte
+	err := row.Scan(
+		&image.GUID,
+		&image.OwnerGUID,
+		&image.TypeName,
+		&image.SmallURL,
+		&image.MediumURL,
+		&image.LargeURL,
+		&image.CreatedAt,
+		&image.UpdatedAt,
+		&image.ContentType,
+		&image.OriginalWidth,
+		&image.OriginalHeight,
+		&image.Position,
+		&image.IsPrimary,
+		&image.AltText,
+		&image.Version,
+		&image.SizesHash,
+		&image.OriginalKey,
+		&image.OriginalChecksum,
+		&image.OriginalSize,
+		&crop)
+	if err != nil {
+		return nil, err
+	}
+	if crop != nil {
+		if err := json.Unmarshal(crop, &image.OriginalCrop); err != nil {
+			return nil, fmt.Errorf("invalid original crop: %w", err)
+		}
+	}
+	return &image, nil
+}
+
+// encodeCrop returns the original_crop value for a crop, NULL for none. The
+// JSON is passed as text, as lib/pq sends []byte as bytea.
+func encodeCrop(crop *domain.Crop) (interface{}, error) {
+	if crop == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(crop)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return string(encoded), nil
+}
+
 // PostgresImageRepository implements ImageRepository using PostgreSQL
 type PostgresImageRepository struct {
 	db *sql.DB
@@ -24,8 +86,14 @@ func NewPostgresImageRepository(db *sql.DB) *PostgresImageRepository {
 	}
 }
 
-// SaveImage saves a new image or updates an existing one
-func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.Image) error {
+// SaveImage saves a new image or updates an existing one, recording events
+// in the outbox in the same transaction
+func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.Image, events ...domain.ImageEvent) error {
+	crop, err := encodeCrop(image.OriginalCrop)
+	if err != nil {
+		return err
+	}
+
 	// Use a transaction for atomicity
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -61,8 +129,17 @@ func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.I
 				updated_at = $6,
 				content_type = $7,
 				original_width = $8,
-				original_height = $9
-			WHERE guid = $10`,
+				original_height = $9,
+				position = $10,
+				is_primary = $11,
+				alt_text = $12,
+				sizes_hash = $13,
+				original_key = $14,
+				original_checksum = $15,
+				original_size = $16,
+				original_crop = $17,
+				version = version + 1
+			WHERE guid = $18`,
 			image.OwnerGUID,
 			image.TypeName,
 			image.SmallURL,
@@ -72,14 +149,24 @@ func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.I
 			image.ContentType,
 			image.OriginalWidth,
 			image.OriginalHeight,
+			image.Position,
+			image.IsPrimary,
+			image.AltText,
+			image.SizesHash,
+			image.OriginalKey,
+			image.OriginalChecksum,
+			image.OriginalSize,
+			crop,
 			image.GUID)
 	} else {
 		// Insert new image
 		_, err = tx.ExecContext(ctx, `
 			INSERT INTO images (
 				guid, owner_guid, type_name, small_url, medium_url, large_url, 
-				created_at, updated_at, content_type, original_width, original_height
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+				created_at, updated_at, content_type, original_width, original_height,
+				position, is_primary, alt_text, sizes_hash,
+				original_key, original_checksum, original_size, original_crop, version
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, 1)`,
 			image.GUID,
 			image.OwnerGUID,
 			image.TypeName,
@@ -90,7 +177,15 @@ func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.I
 			now,
 			image.ContentType,
 			image.OriginalWidth,
-			image.OriginalHeight)
+			image.OriginalHeight,
+			image.Position,
+			image.IsPrimary,
+			image.AltText,
+			image.SizesHash,
+			image.OriginalKey,
+			image.OriginalChecksum,
+			image.OriginalSize,
+			crop)
 	}
 
 	if err != nil {
@@ -101,8 +196,17 @@ func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.I
 		return fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
 
-	// Update the image's updated_at timestamp
+	// Update the image's updated_at timestamp and version
 	image.UpdatedAt = now
+	if exists {
+		image.Version++
+	} else {
+		image.Version = 1
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
 
 	// Commit the transaction
 	if err = tx.Commit(); err != nil {
@@ -112,27 +216,89 @@ func (r *PostgresImageRepository) SaveImage(ctx context.Context, image *domain.I
 	return nil
 }
 
+// AppendImage adds a new image to the end of the owner's collection in one
+// transaction. Appends to the same collection are serialized by a transaction
+// advisory lock on the owner and type, so concurrent uploads neither share a
+// position nor both pass the limit. Events are recorded in the outbox in the
+// same transaction.
+func (r *PostgresImageRepository) AppendImage(ctx context.Context, image *domain.Image, maxImages int, events ...domain.ImageEvent) error {
+	crop, err := encodeCrop(image.OriginalCrop)
+	if err != nil {
+		return err
+	}
+
+	return r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`SELECT pg_advisory_xact_lock(hashtextextended($1::text || '/' || $2, 0))`,
+			image.OwnerGUID, image.TypeName)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		var count int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*)
+			FROM images
+			WHERE owner_guid = $1 AND type_name = $2`,
+			image.OwnerGUID, image.TypeName).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		if maxImages > 0 && count >= maxImages {
+			return ErrLimitReached
+		}
+
+		now := time.Now().UTC()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO images (
+				guid, owner_guid, type_name, small_url, medium_url, large_url,
+				created_at, updated_at, content_type, original_width, original_height,
+				position, is_primary, alt_text, sizes_hash,
+				original_key, original_checksum, original_size, original_crop, version
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, 1)`,
+			image.GUID,
+			image.OwnerGUID,
+			image.TypeName,
+			image.SmallURL,
+			image.MediumURL,
+			image.LargeURL,
+			image.CreatedAt,
+			now,
+			image.ContentType,
+			image.OriginalWidth,
+			image.OriginalHeight,
+			count,
+			count == 0,
+			image.AltText,
+			image.SizesHash,
+			image.OriginalKey,
+			image.OriginalChecksum,
+			image.OriginalSize,
+			crop)
+		if err != nil {
+			// A write outside the lock, such as a primary change, got in the way
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return ErrConflict
+			}
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		image.Position = count
+		image.IsPrimary = count == 0
+		image.UpdatedAt = now
+		image.Version = 1
+
+		return insertEvents(ctx, tx, events)
+	})
+}
+
 // GetImageByID retrieves an image by its GUID
 func (r *PostgresImageRepository) GetImageByID(ctx context.Context, imageGUID uuid.UUID) (*domain.Image, error) {
-	var image domain.Image
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT guid, owner_guid, type_name, small_url, medium_url, large_url, 
-			   created_at, updated_at, content_type, original_width, original_height
+	image, err := scanImage(r.db.QueryRowContext(ctx, `
+		SELECT `+imageColumns+`
 		FROM images
 		WHERE guid = $1`,
-		imageGUID).Scan(
-		&image.GUID,
-		&image.OwnerGUID,
-		&image.TypeName,
-		&image.SmallURL,
-		&image.MediumURL,
-		&image.LargeURL,
-		&image.CreatedAt,
-		&image.UpdatedAt,
-		&image.ContentType,
-		&image.OriginalWidth,
-		&image.OriginalHeight)
+		imageGUID))
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -141,30 +307,19 @@ func (r *PostgresImageRepository) GetImageByID(ctx context.Context, imageGUID uu
 		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
 
-	return &image, nil
+	return image, nil
 }
 
-// GetImageByOwner retrieves an image by owner GUID and type
+// GetImageByOwner retrieves an image by owner GUID and type.
+// For collection types the primary image is returned.
 func (r *PostgresImageRepository) GetImageByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) (*domain.Image, error) {
-	var image domain.Image
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT guid, owner_guid, type_name, small_url, medium_url, large_url, 
-			   created_at, updated_at, content_type, original_width, original_height
+	image, err := scanImage(r.db.QueryRowContext(ctx, `
+		SELECT `+imageColumns+`
 		FROM images
-		WHERE owner_guid = $1 AND type_name = $2`,
-		ownerGUID, typeName).Scan(
-		&image.GUID,
-		&image.OwnerGUID,
-		&image.TypeName,
-		&image.SmallURL,
-		&image.MediumURL,
-		&image.LargeURL,
-		&image.CreatedAt,
-		&image.UpdatedAt,
-		&image.ContentType,
-		&image.OriginalWidth,
-		&image.OriginalHeight)
+		WHERE owner_guid = $1 AND type_name = $2
+		ORDER BY is_primary DESC, position ASC, created_at ASC
+		LIMIT 1`,
+		ownerGUID, typeName))
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -173,7 +328,274 @@ func (r *PostgresImageRepository) GetImageByOwner(ctx context.Context, ownerGUID
 		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
 
-	return &image, nil
+	return image, nil
+}
+
+// GetImagesByOwners retrieves the image of a type for each of several owners in
+// a single query. For collection types the primary image is returned.
+func (r *PostgresImageRepository) GetImagesByOwners(ctx context.Context, ownerGUIDs []uuid.UUID, typeName string) (map[uuid.UUID]*domain.Image, error) {
+	owners := make([]string, len(ownerGUIDs))
+	for i, ownerGUID := range ownerGUIDs {
+		owners[i] = ownerGUID.String()
+	}
+
+	// DISTINCT ON keeps the first row per owner, ordered as in GetImageByOwner
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (owner_guid) `+imageColumns+`
+		FROM images
+		WHERE owner_guid = ANY($1::uuid[]) AND type_name = $2
+		ORDER BY owner_guid, is_primary DESC, position ASC, created_at ASC`,
+		pq.Array(owners), typeName)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	images, err := scanImages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]*domain.Image, len(images))
+	for _, image := range images {
+		result[image.OwnerGUID] = image
+	}
+
+	return result, nil
+}
+
+// ListImagesByOwner lists all images of a type for an owner, ordered by position
+func (r *PostgresImageRepository) ListImagesByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) ([]*domain.Image, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+imageColumns+`
+		FROM images
+		WHERE owner_guid = $1 AND type_name = $2
+		ORDER BY position ASC, created_at ASC`,
+		ownerGUID, typeName)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	return scanImages(rows)
+}
+
+// UpdateImagePositions sets each image's position to its index in imageGUIDs
+func (r *PostgresImageRepository) UpdateImagePositions(ctx context.Context, ownerGUID uuid.UUID, typeName string, imageGUIDs []uuid.UUID) error {
+	return r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		for position, imageGUID := range imageGUIDs {
+			result, err := tx.ExecContext(ctx, `
+				UPDATE images
+				SET position = $1, updated_at = $2, version = version + 1
+				WHERE guid = $3 AND owner_guid = $4 AND type_name = $5`,
+				position, now, imageGUID, ownerGUID, typeName)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrDatabase, err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrDatabase, err)
+			}
+			if rowsAffected == 0 {
+				return ErrNotFound
+			}
+		}
+		return nil
+	})
+}
+
+// SetPrimaryImage marks one image as primary and clears the flag on the owner's other images of the type
+func (r *PostgresImageRepository) SetPrimaryImage(ctx context.Context, ownerGUID uuid.UUID, typeName string, imageGUID uuid.UUID) error {
+	return r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+
+		// Clear the current primary first so the partial unique index is never violated
+		_, err := tx.ExecContext(ctx, `
+			UPDATE images
+			SET is_primary = FALSE, updated_at = $1, version = version + 1
+			WHERE owner_guid = $2 AND type_name = $3 AND is_primary AND guid <> $4`,
+			now, ownerGUID, typeName, imageGUID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE images
+			SET is_primary = TRUE, updated_at = $1, version = version + 1
+			WHERE guid = $2 AND owner_guid = $3 AND type_name = $4`,
+			now, imageGUID, ownerGUID, typeName)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// ReplaceImage swaps the owner's current image for replacement in one
+// transaction. A current image is overwritten by a conditional update that
+// only matches while its version is unchanged; the row takes the
+// replacement's GUID, as the replacement's variants are stored under it, and
+// keeps its single flag. A first image is inserted only while the owner has
+// none; the rows of single-image types are flagged and covered by a unique
+// index, so of two concurrent first uploads one fails. Either way a
+// concurrent change makes it fail with ErrConflict instead of being
+// overwritten. Events are recorded in the outbox in the same transaction.
+func (r *PostgresImageRepository) ReplaceImage(ctx context.Context, current, replacement *domain.Image, events ...domain.ImageEvent) error {
+	crop, err := encodeCrop(replacement.OriginalCrop)
+	if err != nil {
+		return err
+	}
+
+	return r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		version := int64(1)
+
+		var (
+			result sql.Result
+			err    error
+		)
+		if current != nil {
+			version = current.Version + 1
+			result, err = tx.ExecContext(ctx, `
+				UPDATE images
+				SET guid = $1,
+					owner_guid = $2,
+					type_name = $3,
+					small_url = $4,
+					medium_url = $5,
+					large_url = $6,
+					created_at = $7,
+					updated_at = $8,
+					content_type = $9,
+					original_width = $10,
+					original_height = $11,
+					position = $12,
+					is_primary = $13,
+					alt_text = $14,
+					sizes_hash = $15,
+					original_key = $16,
+					original_checksum = $17,
+					original_size = $18,
+					original_crop = $19,
+					version = version + 1
+				WHERE guid = $20 AND version = $21`,
+				replacement.GUID,
+				replacement.OwnerGUID,
+				replacement.TypeName,
+				replacement.SmallURL,
+				replacement.MediumURL,
+				replacement.LargeURL,
+				replacement.CreatedAt,
+				now,
+				replacement.ContentType,
+				replacement.OriginalWidth,
+				replacement.OriginalHeight,
+				replacement.Position,
+				replacement.IsPrimary,
+				replacement.AltText,
+				replacement.SizesHash,
+				replacement.OriginalKey,
+				replacement.OriginalChecksum,
+				replacement.OriginalSize,
+				crop,
+				current.GUID,
+				current.Version)
+		} else {
+			result, err = tx.ExecContext(ctx, `
+				INSERT INTO images (
+					guid, owner_guid, type_name, small_url, medium_url, large_url,
+					created_at, updated_at, content_type, original_width, original_height,
+					position, is_primary, alt_text, sizes_hash,
+					original_key, original_checksum, original_size, original_crop, single, version
+				)
+				SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, TRUE, 1
+				WHERE NOT EXISTS (SELECT 1 FROM images WHERE owner_guid = $2 AND type_name = $3)`,
+				replacement.GUID,
+				replacement.OwnerGUID,
+				replacement.TypeName,
+				replacement.SmallURL,
+				replacement.MediumURL,
+				replacement.LargeURL,
+				replacement.CreatedAt,
+				now,
+				replacement.ContentType,
+				replacement.OriginalWidth,
+				replacement.OriginalHeight,
+				replacement.Position,
+				replacement.IsPrimary,
+				replacement.AltText,
+				replacement.SizesHash,
+				replacement.OriginalKey,
+				replacement.OriginalChecksum,
+				replacement.OriginalSize,
+				crop)
+		}
+		if err != nil {
+			// Another first upload for the owner committed meanwhile
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return ErrConflict
+			}
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		if rowsAffected == 0 {
+			return ErrConflict
+		}
+
+		replacement.UpdatedAt = now
+		replacement.Version = version
+
+		return insertEvents(ctx, tx, events)
+	})
+}
+
+// DeleteImageVersion deletes an image provided its version is unchanged,
+// recording events in the outbox in the same transaction
+func (r *PostgresImageRepository) DeleteImageVersion(ctx context.Context, imageGUID uuid.UUID, version int64, events ...domain.ImageEvent) error {
+	return r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM images
+			WHERE guid = $1 AND version = $2`,
+			imageGUID, version)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		if rowsAffected == 0 {
+			// Tell a concurrent change apart from a concurrent delete
+			var exists bool
+			err = tx.QueryRowContext(ctx,
+				`SELECT EXISTS(SELECT 1 FROM images WHERE guid = $1)`,
+				imageGUID).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrDatabase, err)
+			}
+			if exists {
+				return ErrConflict
+			}
+			return ErrNotFound
+		}
+
+		return insertEvents(ctx, tx, events)
+	})
 }
 
 // DeleteImage deletes an image by its GUID
@@ -199,7 +621,7 @@ func (r *PostgresImageRepository) DeleteImage(ctx context.Context, imageGUID uui
 	return nil
 }
 
-// DeleteImageByOwner deletes an image by owner GUID and type
+// DeleteImageByOwner deletes all images of a type for an owner
 func (r *PostgresImageRepository) DeleteImageByOwner(ctx context.Context, ownerGUID uuid.UUID, typeName string) error {
 	result, err := r.db.ExecContext(ctx, `
 		DELETE FROM images
@@ -225,17 +647,122 @@ func (r *PostgresImageRepository) DeleteImageByOwner(ctx context.Context, ownerG
 // ListImagesByType lists all images of a specific type
 func (r *PostgresImageRepository) ListImagesByType(ctx context.Context, typeName string, limit, offset int) ([]*domain.Image, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT guid, owner_guid, type_name, small_url, medium_url, large_url, 
-			   created_at, updated_at, content_type, original_width, original_height
+		SELECT `+imageColumns+`
 		FROM images
 		WHERE type_name = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, guid ASC
 		LIMIT $2 OFFSET $3`,
 		typeName, limit, offset)
 
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
+
+	return scanImages(rows)
+}
+
+// ListExpiredOriginals lists up to limit images of a type created before the
+// given time that still reference an original, oldest first
+func (r *PostgresImageRepository) ListExpiredOriginals(ctx context.Context, typeName string, before time.Time, limit int) ([]*domain.Image, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+imageColumns+`
+		FROM images
+		WHERE type_name = $1 AND original_key <> '' AND created_at < $2
+		ORDER BY created_at ASC, guid ASC
+		LIMIT $3`,
+		typeName, before, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	return scanImages(rows)
+}
+
+// ClearOriginal removes an image's reference to its original, provided it
+// still references the given key. The version is incremented, so a
+// version-checked write of the image as read before, which would bring the
+// reference back, fails instead. updated_at is left alone, as the original
+// isn't part of what clients see.
+func (r *PostgresImageRepository) ClearOriginal(ctx context.Context, imageGUID uuid.UUID, originalKey string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE images
+		SET original_key = '', original_checksum = '', original_size = 0, original_crop = NULL,
+			version = version + 1
+		WHERE guid = $1 AND original_key = $2`,
+		imageGUID, originalKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// ListImages lists up to limit images matching the filter, most recently
+// updated first. Pages continue from a keyset cursor rather than an offset,
+// so deep pages cost the same as the first.
+func (r *PostgresImageRepository) ListImages(ctx context.Context, filter ImageFilter, limit int) ([]*domain.Image, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = len(args)
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.TypeName != "" {
+		where("type_name = $%d", filter.TypeName)
+	}
+	if filter.OwnerGUID != uuid.Nil {
+		where("owner_guid = $%d", filter.OwnerGUID)
+	}
+	if filter.ContentType != "" {
+		where("content_type = $%d", filter.ContentType)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where("created_at >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		where("created_at < $%d", filter.CreatedTo)
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		where("updated_at >= $%d", filter.UpdatedFrom)
+	}
+	if !filter.UpdatedTo.IsZero() {
+		where("updated_at < $%d", filter.UpdatedTo)
+	}
+	if filter.After != nil {
+		where("(updated_at, guid) < ($%d, $%d)", filter.After.UpdatedAt, filter.After.GUID)
+	}
+
+	query := `SELECT ` + imageColumns + ` FROM images`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY updated_at DESC, guid DESC LIMIT $%d`, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	images, err := scanImages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if images == nil {
+		images = []*domain.Image{}
+	}
+
+	return images, nil
+}
+
+// scanImages scans all rows selected with imageColumns and closes them
+func scanImages(rows *sql.Rows) ([]*domain.Image, error) {
 	defer func() {
 		if err := rows.Close(); err != nil {
 			// Log the close error in a real application
@@ -246,28 +773,15 @@ func (r *PostgresImageRepository) ListImagesByType(ctx context.Context, typeName
 	var images []*domain.Image
 
 	for rows.Next() {
-		var image domain.Image
-		err := rows.Scan(
-			&image.GUID,
-			&image.OwnerGUID,
-			&image.TypeName,
-			&image.SmallURL,
-			&image.MediumURL,
-			&image.LargeURL,
-			&image.CreatedAt,
-			&image.UpdatedAt,
-			&image.ContentType,
-			&image.OriginalWidth,
-			&image.OriginalHeight)
-
+		image, err := scanImage(rows)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
 		}
 
-		images = append(images, &image)
+		images = append(images, image)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
 
@@ -288,11 +802,29 @@ func (r *PostgresImageRepository) CreateImagesTable(ctx context.Context) error {
 			updated_at TIMESTAMPTZ NOT NULL,
 			content_type TEXT,
 			original_width INTEGER,
-			original_height INTEGER
+			original_height INTEGER,
+			position INTEGER NOT NULL DEFAULT 0,
+			is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+			alt_text TEXT NOT NULL DEFAULT '',
+			version BIGINT NOT NULL DEFAULT 1,
+			sizes_hash TEXT NOT NULL DEFAULT '',
+			original_key TEXT NOT NULL DEFAULT '',
+			original_checksum TEXT NOT NULL DEFAULT '',
+			original_size BIGINT NOT NULL DEFAULT 0,
+			original_crop JSONB,
+			single BOOLEAN NOT NULL DEFAULT FALSE
 		);
 		
 		CREATE INDEX IF NOT EXISTS idx_images_owner_type ON images (owner_guid, type_name);
 		CREATE INDEX IF NOT EXISTS idx_images_type ON images (type_name);
+		CREATE INDEX IF NOT EXISTS idx_images_updated ON images (updated_at DESC, guid DESC);
+		CREATE INDEX IF NOT EXISTS idx_images_type_updated ON images (type_name, updated_at DESC, guid DESC);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_images_owner_type_primary
+			ON images (owner_guid, type_name) WHERE is_primary;
+		CREATE INDEX IF NOT EXISTS idx_images_type_originals
+			ON images (type_name, created_at) WHERE original_key <> '';
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_images_owner_type_single
+			ON images (owner_guid, type_name) WHERE single;
 	`)
 
 	if err != nil {
@@ -302,8 +834,9 @@ func (r *PostgresImageRepository) CreateImagesTable(ctx context.Context) error {
 	return nil
 }
 
-// WithTransaction executes a function within a transaction
-func (r *PostgresImageRepository) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
+// WithTransaction executes a function within a transaction, committing it if
+// the function succeeds and rolling it back otherwise
+func (r *PostgresImageRepository) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) (err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDatabase, err)
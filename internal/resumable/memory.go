@@ -0,0 +1,96 @@
+package resumable
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// MemoryStore implements Store in memory, for development and tests
+type MemoryStore struct {
+	mutex   sync.Mutex
+	uploads map[uuid.UUID]*domain.ResumableUpload
+}
+
+// NewMemoryStore creates a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		uploads: make(map[uuid.UUID]*domain.ResumableUpload),
+	}
+}
+
+// Create stores a new upload at version 1
+func (m *MemoryStore) Create(ctx context.Context, upload *domain.ResumableUpload) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	upload.Version = 1
+	m.uploads[upload.GUID] = copyUpload(upload)
+	return nil
+}
+
+// Get returns an upload by GUID
+func (m *MemoryStore) Get(ctx context.Context, uploadGUID uuid.UUID) (*domain.ResumableUpload, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	upload, exists := m.uploads[uploadGUID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyUpload(upload), nil
+}
+
+// Update stores the upload if it is unchanged since it was read
+func (m *MemoryStore) Update(ctx context.Context, upload *domain.ResumableUpload) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.uploads[upload.GUID]
+	if !exists {
+		return ErrNotFound
+	}
+	if stored.Version != upload.Version {
+		return ErrConflict
+	}
+	upload.Version++
+	m.uploads[upload.GUID] = copyUpload(upload)
+	return nil
+}
+
+// Delete removes an upload
+func (m *MemoryStore) Delete(ctx context.Context, uploadGUID uuid.UUID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.uploads, uploadGUID)
+	return nil
+}
+
+// ListExpired returns the GUIDs of uploads that expired before the given time
+func (m *MemoryStore) ListExpired(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var expired []uuid.UUID
+	for uploadGUID, upload := range m.uploads {
+		if upload.ExpiresAt.Before(before) {
+			expired = append(expired, uploadGUID)
+		}
+	}
+	return expired, nil
+}
+
+// copyUpload returns a deep copy so callers can't modify stored uploads
+func copyUpload(upload *domain.ResumableUpload) *domain.ResumableUpload {
+	uploadCopy := *upload
+	uploadCopy.Chunks = append([]string{}, upload.Chunks...)
+	if upload.ImageGUID != nil {
+		imageGUID := *upload.ImageGUID
+		uploadCopy.ImageGUID = &imageGUID
+	}
+	return &uploadCopy
+}
@@ -0,0 +1,72 @@
+package resumable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUpload(expiresAt time.Time) *domain.ResumableUpload {
+	return &domain.ResumableUpload{
+		GUID:      uuid.New(),
+		UserID:    "user-1",
+		TypeName:  "user",
+		OwnerGUID: uuid.New(),
+		Length:    10,
+		Chunks:    []string{},
+		CreatedAt: expiresAt.Add(-24 * time.Hour),
+		ExpiresAt: expiresAt,
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	_, err := store.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	upload := newTestUpload(now.Add(time.Hour))
+	require.NoError(t, store.Create(ctx, upload))
+	assert.Equal(t, int64(1), upload.Version)
+
+	// Two appends read the same state; only the first is written
+	first, err := store.Get(ctx, upload.GUID)
+	require.NoError(t, err)
+	second, err := store.Get(ctx, upload.GUID)
+	require.NoError(t, err)
+
+	first.Chunks = append(first.Chunks, "chunk-a")
+	first.Offset = 5
+	require.NoError(t, store.Update(ctx, first))
+	assert.Equal(t, int64(2), first.Version)
+
+	second.Chunks = append(second.Chunks, "chunk-b")
+	second.Offset = 3
+	assert.ErrorIs(t, store.Update(ctx, second), ErrConflict)
+
+	// Stored uploads can't be modified through the caller's copy
+	first.Chunks[0] = "changed"
+	stored, err := store.Get(ctx, upload.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stored.Offset)
+	assert.Equal(t, []string{"chunk-a"}, stored.Chunks)
+
+	expired := newTestUpload(now.Add(-time.Minute))
+	require.NoError(t, store.Create(ctx, expired))
+	expiredGUIDs, err := store.ListExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{expired.GUID}, expiredGUIDs)
+
+	require.NoError(t, store.Delete(ctx, expired.GUID))
+	require.NoError(t, store.Delete(ctx, expired.GUID))
+	_, err = store.Get(ctx, expired.GUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Update(ctx, expired), ErrNotFound)
+}
@@ -0,0 +1,145 @@
+package resumable
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// uploadColumns lists the resumable_uploads columns in scanUpload order
+const uploadColumns = `guid, user_id, type_name, owner_guid, length, upload_offset, chunks, alt_text, image_guid, version, created_at, expires_at`
+
+// PostgresStore implements Store using the resumable_uploads table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new PostgresStore
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// Create stores a new upload at version 1
+func (p *PostgresStore) Create(ctx context.Context, upload *domain.ResumableUpload) error {
+	chunks, err := json.Marshal(upload.Chunks)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload chunks: %w", err)
+	}
+
+	// The chunks are passed as text, as lib/pq sends []byte as bytea
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO resumable_uploads (`+uploadColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 1, $10, $11)`,
+		upload.GUID, upload.UserID, upload.TypeName, upload.OwnerGUID, upload.Length, upload.Offset,
+		string(chunks), upload.AltText, upload.ImageGUID, upload.CreatedAt, upload.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create resumable upload: %w", err)
+	}
+	upload.Version = 1
+	return nil
+}
+
+// Get returns an upload by GUID
+func (p *PostgresStore) Get(ctx context.Context, uploadGUID uuid.UUID) (*domain.ResumableUpload, error) {
+	row := p.db.QueryRowContext(ctx, `SELECT `+uploadColumns+` FROM resumable_uploads WHERE guid = $1`, uploadGUID)
+	upload, err := scanUpload(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resumable upload: %w", err)
+	}
+	return upload, nil
+}
+
+// Update stores the upload with a conditional update on its version, so of
+// two concurrent appends only the first is written
+func (p *PostgresStore) Update(ctx context.Context, upload *domain.ResumableUpload) error {
+	chunks, err := json.Marshal(upload.Chunks)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload chunks: %w", err)
+	}
+
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE resumable_uploads
+		SET upload_offset = $2, chunks = $3, image_guid = $4, version = version + 1
+		WHERE guid = $1 AND version = $5`,
+		upload.GUID, upload.Offset, string(chunks), upload.ImageGUID, upload.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update resumable upload: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update resumable upload: %w", err)
+	}
+	if rowsAffected == 0 {
+		if _, err := p.Get(ctx, upload.GUID); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	upload.Version++
+	return nil
+}
+
+// Delete removes an upload
+func (p *PostgresStore) Delete(ctx context.Context, uploadGUID uuid.UUID) error {
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM resumable_uploads WHERE guid = $1`, uploadGUID); err != nil {
+		return fmt.Errorf("failed to delete resumable upload: %w", err)
+	}
+	return nil
+}
+
+// ListExpired returns the GUIDs of uploads that expired before the given time
+func (p *PostgresStore) ListExpired(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT guid FROM resumable_uploads WHERE expires_at < $1`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired resumable uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []uuid.UUID
+	for rows.Next() {
+		var uploadGUID uuid.UUID
+		if err := rows.Scan(&uploadGUID); err != nil {
+			return nil, fmt.Errorf("failed to scan expired resumable upload: %w", err)
+		}
+		expired = append(expired, uploadGUID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list expired resumable uploads: %w", err)
+	}
+	return expired, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUpload reads a row selected with uploadColumns
+func scanUpload(row rowScanner) (*domain.ResumableUpload, error) {
+	var upload domain.ResumableUpload
+	var chunks []byte
+	var imageGUID uuid.NullUUID
+	err := row.Scan(&upload.GUID, &upload.UserID, &upload.TypeName, &upload.OwnerGUID, &upload.Length, &upload.Offset,
+		&chunks, &upload.AltText, &imageGUID, &upload.Version, &upload.CreatedAt, &upload.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(chunks, &upload.Chunks); err != nil {
+		return nil, fmt.Errorf("failed to decode upload chunks: %w", err)
+	}
+	if imageGUID.Valid {
+		upload.ImageGUID = &imageGUID.UUID
+	}
+	return &upload, nil
+}
@@ -0,0 +1,41 @@
+// Package resumable persists the state of resumable uploads, so any instance
+// can continue an upload and concurrent appends to one are detected. The
+// uploaded chunks themselves are kept in storage.
+package resumable
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned for unknown uploads
+	ErrNotFound = errors.New("resumable upload not found")
+
+	// ErrConflict is returned when an upload changed since it was read
+	ErrConflict = errors.New("resumable upload was modified concurrently")
+)
+
+// Store persists resumable upload state
+type Store interface {
+	// Create stores a new upload at version 1
+	Create(ctx context.Context, upload *domain.ResumableUpload) error
+
+	// Get returns an upload by GUID
+	Get(ctx context.Context, uploadGUID uuid.UUID) (*domain.ResumableUpload, error)
+
+	// Update stores the upload if its stored version is still upload.Version
+	// and increments upload.Version. Otherwise ErrConflict is returned and
+	// nothing is written.
+	Update(ctx context.Context, upload *domain.ResumableUpload) error
+
+	// Delete removes an upload; deleting an unknown upload is not an error
+	Delete(ctx context.Context, uploadGUID uuid.UUID) error
+
+	// ListExpired returns the GUIDs of uploads that expired before the given time
+	ListExpired(ctx context.Context, before time.Time) ([]uuid.UUID, error)
+}
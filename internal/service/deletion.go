@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+)
+
+// jobKindDeleteFiles is the queue job kind that deletes files no longer
+// referenced by any image
+const jobKindDeleteFiles = "files.delete"
+
+// deleteFilesPayload is the queue job payload of jobKindDeleteFiles
+type deleteFilesPayload struct {
+	Keys        []string  `json:"keys,omitempty"`        // Keys in storage
+	OriginalKey string    `json:"originalKey,omitempty"` // Key in original storage
+	ImageGUID   uuid.UUID `json:"imageGuid,omitempty"`   // Image whose cached renditions go too; zero for none
+}
+
+// deleteImageFiles queues the deletion of an image's variants, original and
+// cached renditions from storage
+func (s *ImageService) deleteImageFiles(ctx context.Context, image *domain.Image) {
+	payload := deleteFilesPayload{
+		OriginalKey: image.OriginalKey,
+		ImageGUID:   image.GUID,
+	}
+	for _, size := range domain.VariantSizes {
+		payload.Keys = append(payload.Keys, s.imageKey(image.TypeName, image.OwnerGUID, image.GUID, size))
+	}
+	s.queueFileDeletion(ctx, payload)
+}
+
+// queueFileDeletion queues the deletion of files, so failures are retried by
+// the queue. If queueing fails they are deleted right away instead, best
+// effort, as the metadata referencing them is already gone.
+func (s *ImageService) queueFileDeletion(ctx context.Context, payload deleteFilesPayload) {
+	queued, err := queue.NewJob(jobKindDeleteFiles, payload)
+	if err == nil {
+		err = s.jobQueue.Enqueue(ctx, queued)
+	}
+	if err == nil {
+		return
+	}
+
+	s.logger.Warnw("Failed to queue file deletion, deleting right away",
+		"error", err,
+		"imageGUID", payload.ImageGUID)
+	if err := s.deleteFiles(ctx, payload); err != nil {
+		s.logger.Warnw("Failed to delete files",
+			"error", err,
+			"imageGUID", payload.ImageGUID)
+	}
+}
+
+// handleDeleteFiles deletes the files named by a queue job
+func (s *ImageService) handleDeleteFiles(ctx context.Context, queued *queue.Job) error {
+	var payload deleteFilesPayload
+	if err := queued.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+	return s.deleteFiles(ctx, payload)
+}
+
+// deleteFiles deletes every file of the payload, going on after failures so
+// a retry has less left to do. Files already gone count as deleted.
+func (s *ImageService) deleteFiles(ctx context.Context, payload deleteFilesPayload) error {
+	var errs []error
+	for _, key := range payload.Keys {
+		if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", key, err))
+		}
+	}
+
+	if payload.OriginalKey != "" {
+		if err := s.originals.Delete(ctx, payload.OriginalKey); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			errs = append(errs, fmt.Errorf("failed to delete original %s: %w", payload.OriginalKey, err))
+		}
+	}
+
+	// Cached renditions are derived from the variants and go with them
+	if payload.ImageGUID != uuid.Nil {
+		if err := s.deleteRenditions(ctx, payload.ImageGUID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
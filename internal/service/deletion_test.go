@@ -0,0 +1,34 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeleteImage_FilesRetried tests that files that fail to delete are
+// deleted by a later attempt of the queued deletion
+func TestDeleteImage_FilesRetried(t *testing.T) {
+	service, _, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+	image, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	smallKey := mockStorage.GenerateImageKey("user", ownerGUID, image.GUID, "small")
+
+	// The metadata goes right away, the files once the queue gets to them
+	require.NoError(t, service.DeleteImage(ctx, "user", ownerGUID))
+	assert.True(t, mockStorage.HasObject(smallKey))
+
+	service.storage = &flakyStorage{MockS3: mockStorage, failDeletes: 1}
+	assert.Equal(t, 2, drainJobs(t, service))
+	assert.False(t, mockStorage.HasObject(smallKey))
+	assert.False(t, mockStorage.HasObject(image.OriginalKey))
+	assert.Equal(t, 0, mockStorage.GetObjectCount())
+}
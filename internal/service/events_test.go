@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedEvents relays the events recorded in the repository outbox
+func recordedEvents(t *testing.T, repo repository.Outbox) []domain.ImageEvent {
+	var events []domain.ImageEvent
+	_, err := repo.RelayEvents(context.Background(), 100, func(event domain.ImageEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	require.NoError(t, err)
+	return events
+}
+
+// TestImageEvents tests that writes record lifecycle events in the outbox
+func TestImageEvents(t *testing.T) {
+	service, mockRepo, _, mockProcessor, _ := setupTestService(t)
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	first, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	second, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteImage(ctx, "user", ownerGUID))
+
+	// Each image of a collection is uploaded and deleted on its own
+	productGUID := uuid.New()
+	addTestProductImages(t, service, productGUID, 2)
+	require.NoError(t, service.DeleteImage(ctx, "product", productGUID))
+
+	events := recordedEvents(t, mockRepo)
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{
+		domain.EventImageUploaded,
+		domain.EventImageReplaced,
+		domain.EventImageDeleted,
+		domain.EventImageUploaded,
+		domain.EventImageUploaded,
+		domain.EventImageDeleted,
+		domain.EventImageDeleted,
+	}, types)
+	assert.Equal(t, first.GUID, events[0].Image.GUID)
+	assert.Equal(t, second.GUID, events[1].Image.GUID)
+	assert.Equal(t, int64(2), events[1].Image.Version)
+	assert.Equal(t, second.GUID, events[2].Image.GUID)
+	assert.NotEqual(t, events[0].ID, events[1].ID)
+
+	// Failed writes record nothing
+	mockProcessor.SetShouldFailProcessing(true)
+	_, err = service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.Error(t, err)
+	assert.Empty(t, recordedEvents(t, mockRepo))
+}
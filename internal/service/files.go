@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/antonrybalko/image-service-go/internal/storage"
+)
+
+// imageKeyPrefix is the storage prefix of published image variants; nothing
+// outside it (staging, resumable uploads, renditions) is served as a file
+const imageKeyPrefix = "images/"
+
+// File is a published image variant opened for serving
+type File struct {
+	Info         *storage.ObjectInfo
+	CacheControl string
+	Content      *storage.ObjectReader // Opened lazily; the caller closes it
+}
+
+// OpenFile opens the image variant stored under key for serving. Keys outside
+// the published images, or of unknown image types, are reported as ErrNotFound
+// so the endpoint reveals nothing about the rest of the bucket.
+func (s *ImageService) OpenFile(ctx context.Context, key string) (*File, error) {
+	// Keys look like images/{type}/{ownerGuid}/{imageGuid}/{size}.jpg
+	if path.Clean("/"+key) != "/"+key || !strings.HasPrefix(key, imageKeyPrefix) {
+		return nil, ErrNotFound
+	}
+	parts := strings.Split(strings.TrimPrefix(key, imageKeyPrefix), "/")
+	if len(parts) != 4 {
+		return nil, ErrNotFound
+	}
+	imageType, err := s.ImageType(parts[0])
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	info, err := s.storage.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrNotFound
+		}
+		s.logger.Errorw("Failed to stat file",
+			"error", err,
+			"key", key)
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	return &File{
+		Info:         info,
+		CacheControl: imageType.CacheControlHeader(),
+		Content:      storage.NewObjectReader(ctx, s.storage, info),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Page sizes of the image listing
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// ImageList is one page of the image listing
+type ImageList struct {
+	Images     []*domain.Image
+	NextCursor string // Empty on the last page
+}
+
+// ListImages returns a page of images matching the filter, most recently
+// updated first. cursor is empty for the first page and otherwise the
+// NextCursor of the previous page; limit is capped at MaxListLimit.
+func (s *ImageService) ListImages(ctx context.Context, filter repository.ImageFilter, cursor string, limit int) (*ImageList, error) {
+	if filter.TypeName != "" {
+		if _, err := s.ImageType(filter.TypeName); err != nil {
+			return nil, err
+		}
+	}
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+
+	// Fetch one extra image to learn whether another page follows
+	images, err := s.repo.ListImages(ctx, filter, limit+1)
+	if err != nil {
+		s.logger.Errorw("Failed to list images",
+			"error", err,
+			"typeName", filter.TypeName)
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	list := &ImageList{Images: images}
+	if len(images) > limit {
+		list.Images = images[:limit]
+		list.NextCursor = encodeCursor(repository.CursorAfter(list.Images[limit-1]))
+	}
+
+	return list, nil
+}
+
+// encodeCursor serializes a listing position into an opaque URL-safe token
+func encodeCursor(cursor *repository.ImageCursor) string {
+	raw := cursor.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.GUID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a token produced by encodeCursor
+func decodeCursor(token string) (*repository.ImageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	updatedAt, guid, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor repository.ImageCursor
+	if cursor.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.GUID, err = uuid.Parse(guid); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
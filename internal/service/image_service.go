@@ -1,14 +1,24 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"time"
+	"unicode/utf8"
 
 	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/fetcher"
+	"github.com/antonrybalko/image-service-go/internal/jobs"
+	"github.com/antonrybalko/image-service-go/internal/placeholder"
 	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/queue"
 	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/resumable"
 	"github.com/antonrybalko/image-service-go/internal/storage"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -16,23 +26,77 @@ import (
 
 // Common service errors
 var (
-	ErrInvalidImage     = errors.New("invalid image data")
-	ErrImageTooLarge    = errors.New("image too large")
-	ErrUnsupportedType  = errors.New("unsupported image type")
-	ErrProcessingFailed = errors.New("image processing failed")
-	ErrStorageFailed    = errors.New("image storage failed")
-	ErrNotFound         = errors.New("image not found")
-	ErrUnauthorized     = errors.New("unauthorized access to image")
+	ErrInvalidImage       = errors.New("invalid image data")
+	ErrImageTooLarge      = errors.New("image too large")
+	ErrUnsupportedType    = errors.New("unsupported image type")
+	ErrProcessingFailed   = errors.New("image processing failed")
+	ErrStorageFailed      = errors.New("image storage failed")
+	ErrNotFound           = errors.New("image not found")
+	ErrUnauthorized       = errors.New("unauthorized access to image")
+	ErrImageLimit         = errors.New("image limit reached")
+	ErrInvalidOrder       = errors.New("invalid image order")
+	ErrUnknownType        = errors.New("unknown image type")
+	ErrUnknownSize        = errors.New("unknown image size")
+	ErrInvalidOptions     = errors.New("invalid upload options")
+	ErrInvalidSource      = errors.New("invalid image source URL")
+	ErrSourceFailed       = errors.New("failed to fetch image from source URL")
+	ErrOffsetMismatch     = errors.New("upload offset mismatch")
+	ErrUploadExpired      = errors.New("upload expired")
+	ErrBatchTooLarge      = errors.New("too many owners in batch")
+	ErrInvalidCursor      = errors.New("invalid page cursor")
+	ErrPreconditionFailed = errors.New("image precondition failed")
+	ErrConcurrentUpdate   = errors.New("image was modified concurrently")
 )
 
+// FieldError is an ErrInvalidOptions that names the offending option, so
+// callers can point clients at the field to fix
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// invalidField reports an invalid upload option
+func invalidField(field, format string, args ...any) error {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	return ErrInvalidOptions.Error() + ": " + e.Message
+}
+
+// Unwrap makes a FieldError match ErrInvalidOptions
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidOptions
+}
+
+// MaxBatchOwners is the largest number of owners GetImagesByOwners looks up at once
+const MaxBatchOwners = 100
+
+// maxWriteAttempts bounds how often a replace or delete is retried after
+// losing a race with a concurrent write to the same image
+const maxWriteAttempts = 3
+
+// RemoteFetcher downloads images from user-supplied URLs
+type RemoteFetcher interface {
+	Fetch(ctx context.Context, rawURL string) ([]byte, error)
+}
+
 // ImageService handles image processing, storage, and metadata management
 type ImageService struct {
 	repo      repository.ImageRepository
 	storage   storage.S3Interface
 	processor processor.ProcessorInterface
+	fetcher   RemoteFetcher
+	jobs      jobs.Store
+	resumable resumable.Store
+	jobQueue  queue.Queue
+	originals storage.S3Interface // Where uploaded originals are kept, by default storage
 	config    *domain.ImageConfig
 	logger    *zap.SugaredLogger
 	maxSize   int64 // Maximum image size in bytes
+
+	reprocessInterval time.Duration // Pause after each image reprocessed by queued runs
 }
 
 // NewImageService creates a new image service
@@ -46,36 +110,188 @@ func NewImageService(
 	return &ImageService{
 		repo:      repo,
 		storage:   storage,
+		originals: storage,
 		processor: processor,
 		config:    config,
+		fetcher:   fetcher.New(fetcher.DefaultConfig()),
+		jobs:      jobs.NewMemoryStore(),
+		resumable: resumable.NewMemoryStore(),
+		jobQueue:  queue.NewMemoryQueue(),
 		logger:    logger,
 		maxSize:   15 * 1024 * 1024, // Default 15MB max size
+
+		reprocessInterval: DefaultReprocessInterval,
 	}
 }
 
+// SetRemoteFetcher sets the fetcher used to import images from URLs
+func (s *ImageService) SetRemoteFetcher(f RemoteFetcher) {
+	s.fetcher = f
+}
+
+// SetOriginalStorage sets where uploaded originals are kept, e.g. a private
+// bucket; by default they are kept next to the variants
+func (s *ImageService) SetOriginalStorage(originals storage.S3Interface) {
+	s.originals = originals
+}
+
 // SetMaxImageSize sets the maximum allowed image size in bytes
 func (s *ImageService) SetMaxImageSize(maxBytes int64) {
 	s.maxSize = maxBytes
 }
 
+// MaxImageSize returns the maximum allowed image size in bytes
+func (s *ImageService) MaxImageSize() int64 {
+	return s.maxSize
+}
+
+// MaxImageSizeFor returns the maximum allowed size in bytes for images of the
+// named type: the type's own limit if it sets one, otherwise the service default
+func (s *ImageService) MaxImageSizeFor(typeName string) int64 {
+	if imageType, found := domain.GetImageTypeByName(s.config, typeName); found && imageType.MaxBytes > 0 {
+		return imageType.MaxBytes
+	}
+	return s.maxSize
+}
+
+// MaxUploadSize returns the largest size in bytes that any configured image type accepts
+func (s *ImageService) MaxUploadSize() int64 {
+	largest := s.maxSize
+	for _, imageType := range s.config.Types {
+		if imageType.MaxBytes > largest {
+			largest = imageType.MaxBytes
+		}
+	}
+	return largest
+}
+
+// Image type names handled by the service
+const (
+	UserImageType         = "user"
+	OrganizationImageType = "organization"
+	ProductImageType      = "product"
+)
+
 // UploadUserImage processes and stores a user image
-func (s *ImageService) UploadUserImage(ctx context.Context, userGUID uuid.UUID, imageData []byte) (*domain.UserImage, error) {
-	// Validate image data
-	if len(imageData) == 0 {
-		return nil, ErrInvalidImage
+func (s *ImageService) UploadUserImage(ctx context.Context, userGUID uuid.UUID, imageData io.Reader, opts domain.UploadOptions) (*domain.UserImage, error) {
+	image, err := s.UploadImage(ctx, UserImageType, userGUID, imageData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return user image view
+	return image.ToUserImage(), nil
+}
+
+// UploadOrganizationImage processes and stores an organization image
+func (s *ImageService) UploadOrganizationImage(ctx context.Context, orgGUID uuid.UUID, imageData io.Reader, opts domain.UploadOptions) (*domain.OrganizationImage, error) {
+	image, err := s.UploadImage(ctx, OrganizationImageType, orgGUID, imageData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return organization image view
+	return image.ToOrganizationImage(), nil
+}
+
+// AddProductImage processes and stores an image, appending it to the product's gallery
+func (s *ImageService) AddProductImage(ctx context.Context, productGUID uuid.UUID, imageData io.Reader, opts domain.UploadOptions) (*domain.ProductImage, error) {
+	image, err := s.UploadImage(ctx, ProductImageType, productGUID, imageData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return product image view
+	return image.ToProductImage(), nil
+}
+
+// FetchRemoteImage downloads image data from a user-supplied URL so it can be
+// passed to the regular upload pipeline
+func (s *ImageService) FetchRemoteImage(ctx context.Context, sourceURL string) ([]byte, error) {
+	imageData, err := s.fetcher.Fetch(ctx, sourceURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, fetcher.ErrInvalidURL),
+			errors.Is(err, fetcher.ErrBlockedAddress),
+			errors.Is(err, fetcher.ErrTooManyHops):
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+		case errors.Is(err, fetcher.ErrTooLarge):
+			return nil, ErrImageTooLarge
+		default:
+			s.logger.Warnw("Failed to fetch remote image",
+				"error", err,
+				"sourceURL", sourceURL)
+			return nil, fmt.Errorf("%w: %v", ErrSourceFailed, err)
+		}
+	}
+
+	return imageData, nil
+}
+
+// ImageTypes returns the configured image types
+func (s *ImageService) ImageTypes() []domain.ImageType {
+	return s.config.Types
+}
+
+// ImageType returns the configuration for the named image type
+func (s *ImageService) ImageType(typeName string) (*domain.ImageType, error) {
+	imageType, found := domain.GetImageTypeByName(s.config, typeName)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, typeName)
+	}
+	return imageType, nil
+}
+
+// UploadImage validates, processes and stores an image of any configured type.
+// For single-image types it replaces any image the owner already has; for
+// collection types it appends the image to the owner's collection.
+func (s *ImageService) UploadImage(ctx context.Context, typeName string, ownerGUID uuid.UUID, imageData io.Reader, opts domain.UploadOptions) (*domain.Image, error) {
+	// Get image type configuration
+	imageType, err := s.ImageType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate alt text before doing any image work
+	if utf8.RuneCountInString(opts.AltText) > domain.MaxAltTextLength {
+		return nil, invalidField("altText", "alt text exceeds %d characters", domain.MaxAltTextLength)
+	}
+
+	// Check preconditions before any image work; single-image types check again when replacing
+	current, err := s.currentImage(ctx, typeName, ownerGUID)
+	if err != nil {
+		return nil, err
 	}
+	if !opts.Precondition.Allows(current) {
+		return nil, ErrPreconditionFailed
+	}
+
+	// The size limit is enforced while reading, so the body is never buffered
+	// whole unless the type keeps originals
+	source := newUploadReader(imageData, s.MaxImageSizeFor(typeName))
+	var original *bytes.Buffer
+	var body io.Reader = source
+	if keep, _ := imageType.OriginalRetentionPeriod(); keep {
+		original = new(bytes.Buffer)
+		body = io.TeeReader(source, original)
+	}
+	buffered := bufio.NewReaderSize(body, processor.HeaderSize)
 
-	// Check size limit
-	if int64(len(imageData)) > s.maxSize {
-		return nil, ErrImageTooLarge
+	// Detect image format from a peek at the header
+	header, _ := buffered.Peek(processor.HeaderSize)
+	if err := source.Err(); err != nil {
+		return nil, err
+	}
+	if len(header) == 0 {
+		return nil, ErrInvalidImage
 	}
 
-	// Detect image format
-	contentType, err := s.processor.DetectImageFormat(imageData)
+	contentType, err := s.processor.DetectImageFormat(header)
 	if err != nil {
 		s.logger.Errorw("Failed to detect image format",
 			"error", err,
-			"userGUID", userGUID)
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
 		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
 	}
 
@@ -84,104 +300,207 @@ func (s *ImageService) UploadUserImage(ctx context.Context, userGUID uuid.UUID,
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
 	}
 
-	// Get image dimensions
-	width, height, err := s.processor.GetImageDimensions(imageData)
+	// Get image dimensions, keeping the bytes read so they can be replayed for decoding
+	var consumed bytes.Buffer
+	width, height, err := s.processor.GetImageDimensions(io.TeeReader(buffered, &consumed))
+	if sourceErr := source.Err(); sourceErr != nil {
+		return nil, sourceErr
+	}
 	if err != nil {
 		s.logger.Errorw("Failed to get image dimensions",
 			"error", err,
-			"userGUID", userGUID)
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
 		return nil, fmt.Errorf("%w: %v", ErrProcessingFailed, err)
 	}
-
-	// Get user image type configuration
-	imageType, found := domain.GetImageTypeByName(s.config, "user")
-	if !found {
-		s.logger.Errorw("Failed to get image type configuration",
-			"userGUID", userGUID)
-		return nil, fmt.Errorf("image type configuration not found")
+	stream := io.MultiReader(&consumed, buffered)
+
+	// Process image to create variants, cropping first if requested
+	var variants map[string][]byte
+	if opts.Crop != nil {
+		crop := *opts.Crop
+		if crop.X < 0 || crop.Y < 0 || crop.Width <= 0 || crop.Height <= 0 ||
+			crop.X+crop.Width > width || crop.Y+crop.Height > height {
+			return nil, invalidField("crop", "crop must lie within the %dx%d image", width, height)
+		}
+		variants, err = s.processor.ProcessCroppedImage(stream, imageType, crop)
+	} else {
+		variants, err = s.processor.ProcessImage(stream, imageType)
 	}
 
-	// Process image to create variants
-	variants, err := s.processor.ProcessImage(imageData, imageType)
+	// Decoders may stop before the end of the data; read the rest so trailing
+	// bytes still count against the size limit
+	if err == nil {
+		_, err = io.Copy(io.Discard, stream)
+	}
+	if sourceErr := source.Err(); sourceErr != nil {
+		return nil, sourceErr
+	}
 	if err != nil {
 		s.logger.Errorw("Failed to process image",
 			"error", err,
-			"userGUID", userGUID)
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
 		return nil, fmt.Errorf("%w: %v", ErrProcessingFailed, err)
 	}
 
 	// Generate a new image GUID
 	imageGUID := uuid.New()
 
-	// Delete any existing image for this user
-	err = s.DeleteUserImage(ctx, userGUID)
-	if err != nil && !errors.Is(err, ErrNotFound) {
-		s.logger.Warnw("Failed to delete existing user image",
-			"error", err,
-			"userGUID", userGUID)
-		// Continue with upload even if deletion fails
-	}
-
 	// Create a new image record
-	image := domain.NewImage(userGUID, "user")
+	image := domain.NewImage(ownerGUID, typeName)
 	image.GUID = imageGUID
 	image.OriginalWidth = width
 	image.OriginalHeight = height
 	image.ContentType = contentType
+	image.AltText = opts.AltText
+	image.SizesHash = imageType.SizesHash()
 
 	// Upload each variant to storage
 	for size, variantData := range variants {
 		// Generate S3 key for this variant
-		key := s.storage.GenerateUserImageKey(userGUID, imageGUID, size)
+		key := s.imageKey(typeName, ownerGUID, imageGUID, size)
 
 		// Upload to S3
 		url, err := s.storage.Put(ctx, key, variantData, "image/jpeg")
 		if err != nil {
 			s.logger.Errorw("Failed to upload image variant",
 				"error", err,
-				"userGUID", userGUID,
+				"typeName", typeName,
+				"ownerGUID", ownerGUID,
 				"imageGUID", imageGUID,
 				"size", size)
 			return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
 		}
 
 		// Set URL in image record
-		switch size {
-		case "small":
-			image.SmallURL = url
-		case "medium":
-			image.MediumURL = url
-		case "large":
-			image.LargeURL = url
+		setVariantURL(image, size, url)
+	}
+
+	if original != nil {
+		if err := s.storeOriginal(ctx, image, original.Bytes(), opts.Crop); err != nil {
+			s.deleteImageFiles(ctx, image)
+			return nil, err
 		}
 	}
 
-	// Save image metadata to repository
-	err = s.repo.SaveImage(ctx, image)
+	// Save image metadata to repository; single-image types replace the current image
+	if imageType.IsCollection() {
+		err = s.appendImage(ctx, imageType, image)
+	} else {
+		err = s.replaceImage(ctx, current, image, opts.Precondition)
+	}
 	if err != nil {
+		// The new variants are unreferenced now
+		s.deleteImageFiles(ctx, image)
+		if errors.Is(err, ErrPreconditionFailed) || errors.Is(err, ErrConcurrentUpdate) || errors.Is(err, ErrImageLimit) {
+			return nil, err
+		}
 		s.logger.Errorw("Failed to save image metadata",
 			"error", err,
-			"userGUID", userGUID,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID,
 			"imageGUID", imageGUID)
 		return nil, fmt.Errorf("failed to save image metadata: %w", err)
 	}
 
-	// Return user image view
-	return image.ToUserImage(), nil
+	return image, nil
 }
 
-// GetUserImage retrieves a user's image by user GUID
-func (s *ImageService) GetUserImage(ctx context.Context, userGUID uuid.UUID) (*domain.UserImage, error) {
+// GetImage retrieves the owner's image of any configured type.
+// For collection types the primary image is returned.
+func (s *ImageService) GetImage(ctx context.Context, typeName string, ownerGUID uuid.UUID) (*domain.Image, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return nil, err
+	}
+
 	// Get image from repository
-	image, err := s.repo.GetImageByOwner(ctx, userGUID, "user")
+	image, err := s.repo.GetImageByOwner(ctx, ownerGUID, typeName)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, ErrNotFound
 		}
-		s.logger.Errorw("Failed to get user image",
+		s.logger.Errorw("Failed to get image",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	return image, nil
+}
+
+// ImageSizeURL returns the URL of the owner's current variant of the given size.
+// If the owner has no image, the type's default image or placeholder URL is
+// returned instead, or ErrNotFound when the type has neither. The display name
+// is only used for placeholder initials.
+func (s *ImageService) ImageSizeURL(ctx context.Context, typeName string, ownerGUID uuid.UUID, size, displayName string) (string, error) {
+	imageType, err := s.ImageType(typeName)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := imageType.Sizes[size]; !exists {
+		return "", fmt.Errorf("%w: %s has no size %q", ErrUnknownSize, typeName, size)
+	}
+
+	image, err := s.GetImage(ctx, typeName, ownerGUID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) && imageType.DefaultImage != "" {
+			return imageType.DefaultImageURL(size), nil
+		}
+		if errors.Is(err, ErrNotFound) && imageType.Placeholder {
+			return placeholder.Path(typeName, ownerGUID, size, placeholder.Initials(displayName)), nil
+		}
+		return "", err
+	}
+
+	return s.storage.GetURL(s.imageKey(typeName, ownerGUID, image.GUID, size)), nil
+}
+
+// GetImagesByOwners retrieves the image of a type for each of several owners
+// with a single repository query. Every requested owner is a key of the result;
+// owners without an image map to nil.
+func (s *ImageService) GetImagesByOwners(ctx context.Context, typeName string, ownerGUIDs []uuid.UUID) (map[uuid.UUID]*domain.Image, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return nil, err
+	}
+
+	// Look each owner up once, however often it is listed
+	result := make(map[uuid.UUID]*domain.Image, len(ownerGUIDs))
+	owners := make([]uuid.UUID, 0, len(ownerGUIDs))
+	for _, ownerGUID := range ownerGUIDs {
+		if _, seen := result[ownerGUID]; !seen {
+			result[ownerGUID] = nil
+			owners = append(owners, ownerGUID)
+		}
+	}
+	if len(owners) > MaxBatchOwners {
+		return nil, fmt.Errorf("%w: %d owners, at most %d allowed", ErrBatchTooLarge, len(owners), MaxBatchOwners)
+	}
+	if len(owners) == 0 {
+		return result, nil
+	}
+
+	images, err := s.repo.GetImagesByOwners(ctx, owners, typeName)
+	if err != nil {
+		s.logger.Errorw("Failed to get images by owners",
 			"error", err,
-			"userGUID", userGUID)
-		return nil, fmt.Errorf("failed to get user image: %w", err)
+			"typeName", typeName,
+			"owners", len(owners))
+		return nil, fmt.Errorf("failed to get images: %w", err)
+	}
+	for ownerGUID, image := range images {
+		result[ownerGUID] = image
+	}
+
+	return result, nil
+}
+
+// GetUserImage retrieves a user's image by user GUID
+func (s *ImageService) GetUserImage(ctx context.Context, userGUID uuid.UUID) (*domain.UserImage, error) {
+	image, err := s.GetImage(ctx, UserImageType, userGUID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Return user image view
@@ -203,7 +522,7 @@ func (s *ImageService) GetUserImageByID(ctx context.Context, imageGUID uuid.UUID
 	}
 
 	// Verify it's a user image
-	if image.TypeName != "user" {
+	if image.TypeName != UserImageType {
 		return nil, fmt.Errorf("%w: not a user image", ErrUnauthorized)
 	}
 
@@ -211,48 +530,361 @@ func (s *ImageService) GetUserImageByID(ctx context.Context, imageGUID uuid.UUID
 	return image.ToUserImage(), nil
 }
 
+// GetOrganizationImage retrieves an organization's image by organization GUID
+func (s *ImageService) GetOrganizationImage(ctx context.Context, orgGUID uuid.UUID) (*domain.OrganizationImage, error) {
+	image, err := s.GetImage(ctx, OrganizationImageType, orgGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return organization image view
+	return image.ToOrganizationImage(), nil
+}
+
+// DeleteImage deletes the owner's images of any configured type.
+// For collection types every image in the collection is removed.
+func (s *ImageService) DeleteImage(ctx context.Context, typeName string, ownerGUID uuid.UUID) error {
+	return s.DeleteImageIf(ctx, typeName, ownerGUID, domain.Precondition{})
+}
+
+// DeleteImageIf deletes the owner's images like DeleteImage, provided the
+// precondition holds for the owner's current image. For collection types the
+// precondition is checked against the primary image.
+func (s *ImageService) DeleteImageIf(ctx context.Context, typeName string, ownerGUID uuid.UUID, precondition domain.Precondition) error {
+	imageType, err := s.ImageType(typeName)
+	if err != nil {
+		return err
+	}
+
+	if !imageType.IsCollection() {
+		return s.deleteImage(ctx, typeName, ownerGUID, precondition)
+	}
+
+	if !precondition.IsZero() {
+		current, err := s.currentImage(ctx, typeName, ownerGUID)
+		if err != nil {
+			return err
+		}
+		if !precondition.Allows(current) {
+			return ErrPreconditionFailed
+		}
+	}
+
+	images, err := s.repo.ListImagesByOwner(ctx, ownerGUID, typeName)
+	if err != nil {
+		s.logger.Errorw("Failed to list images for deletion",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
+		return fmt.Errorf("failed to list images for deletion: %w", err)
+	}
+	if len(images) == 0 {
+		return ErrNotFound
+	}
+
+	for _, image := range images {
+		if err := s.removeImage(ctx, image); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DeleteUserImage deletes a user's image
 func (s *ImageService) DeleteUserImage(ctx context.Context, userGUID uuid.UUID) error {
-	// Get the image first to get its GUID
-	image, err := s.repo.GetImageByOwner(ctx, userGUID, "user")
+	return s.DeleteImage(ctx, UserImageType, userGUID)
+}
+
+// DeleteOrganizationImage deletes an organization's image
+func (s *ImageService) DeleteOrganizationImage(ctx context.Context, orgGUID uuid.UUID) error {
+	return s.DeleteImage(ctx, OrganizationImageType, orgGUID)
+}
+
+// currentImage returns the owner's image of the given type, or nil if it has none.
+// For collection types the primary image is returned.
+func (s *ImageService) currentImage(ctx context.Context, typeName string, ownerGUID uuid.UUID) (*domain.Image, error) {
+	image, err := s.repo.GetImageByOwner(ctx, ownerGUID, typeName)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return ErrNotFound
+			return nil, nil
 		}
-		s.logger.Errorw("Failed to get user image for deletion",
+		s.logger.Errorw("Failed to get current image",
 			"error", err,
-			"userGUID", userGUID)
-		return fmt.Errorf("failed to get user image for deletion: %w", err)
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
+		return nil, fmt.Errorf("failed to get current image: %w", err)
+	}
+	return image, nil
+}
+
+// appendImage adds a new image to the end of the owner's collection; the
+// first image becomes primary. The position and limit are checked by the
+// repository in the same transaction as the insert, so concurrent uploads
+// cannot both take the last slot.
+func (s *ImageService) appendImage(ctx context.Context, imageType *domain.ImageType, image *domain.Image) error {
+	err := s.repo.AppendImage(ctx, image, imageType.MaxImages, domain.NewImageEvent(domain.EventImageUploaded, image))
+	switch {
+	case errors.Is(err, repository.ErrLimitReached):
+		return fmt.Errorf("%w: %s allows at most %d images", ErrImageLimit, imageType.Name, imageType.MaxImages)
+	case errors.Is(err, repository.ErrConflict):
+		return ErrConcurrentUpdate
+	default:
+		return err
+	}
+}
+
+// replaceImage swaps the owner's current image of a single-image type for
+// image, recording an uploaded or replaced event with it. If another write
+// got there first, the current image is read again and, as long as the
+// precondition still holds, the swap is retried.
+func (s *ImageService) replaceImage(ctx context.Context, current, image *domain.Image, precondition domain.Precondition) error {
+	for attempt := 1; ; attempt++ {
+		eventType := domain.EventImageUploaded
+		if current != nil {
+			eventType = domain.EventImageReplaced
+		}
+
+		err := s.repo.ReplaceImage(ctx, current, image, domain.NewImageEvent(eventType, image))
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			return err
+		}
+		if attempt == maxWriteAttempts {
+			return ErrConcurrentUpdate
+		}
+
+		if current, err = s.currentImage(ctx, image.TypeName, image.OwnerGUID); err != nil {
+			return err
+		}
+		if !precondition.Allows(current) {
+			return ErrPreconditionFailed
+		}
 	}
 
-	// Delete image variants from storage
-	sizes := []string{"small", "medium", "large"}
-	for _, size := range sizes {
-		key := s.storage.GenerateUserImageKey(userGUID, image.GUID, size)
-		err := s.storage.Delete(ctx, key)
+	// The replaced image's variants are unreferenced now
+	if current != nil {
+		s.deleteImageFiles(ctx, current)
+	}
+
+	return nil
+}
+
+// deleteImage removes the owner's image of a single-image type, provided the
+// precondition holds, retrying if the image changes concurrently
+func (s *ImageService) deleteImage(ctx context.Context, typeName string, ownerGUID uuid.UUID, precondition domain.Precondition) error {
+	for attempt := 1; ; attempt++ {
+		image, err := s.currentImage(ctx, typeName, ownerGUID)
 		if err != nil {
-			s.logger.Warnw("Failed to delete image variant from storage",
-				"error", err,
-				"userGUID", userGUID,
-				"imageGUID", image.GUID,
-				"size", size)
-			// Continue with deletion even if one variant fails
+			return err
+		}
+		if !precondition.Allows(image) {
+			return ErrPreconditionFailed
+		}
+		if image == nil {
+			return ErrNotFound
+		}
+
+		err = s.removeImage(ctx, image)
+		if !errors.Is(err, ErrConcurrentUpdate) || attempt == maxWriteAttempts {
+			return err
 		}
 	}
+}
 
-	// Delete image metadata from repository
-	err = s.repo.DeleteImage(ctx, image.GUID)
+// removeImage deletes an image's metadata from the repository, provided it
+// is unchanged since it was read, recording a deleted event with it, and then
+// its variants from storage
+func (s *ImageService) removeImage(ctx context.Context, image *domain.Image) error {
+	err := s.repo.DeleteImageVersion(ctx, image.GUID, image.Version, domain.NewImageEvent(domain.EventImageDeleted, image))
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return ErrConcurrentUpdate
+		}
 		s.logger.Errorw("Failed to delete image metadata",
 			"error", err,
-			"userGUID", userGUID,
+			"typeName", image.TypeName,
+			"ownerGUID", image.OwnerGUID,
 			"imageGUID", image.GUID)
 		return fmt.Errorf("failed to delete image metadata: %w", err)
 	}
 
+	s.deleteImageFiles(ctx, image)
+
+	return nil
+}
+
+// ListProductImages returns a product's gallery ordered by position
+func (s *ImageService) ListProductImages(ctx context.Context, productGUID uuid.UUID) ([]*domain.ProductImage, error) {
+	images, err := s.repo.ListImagesByOwner(ctx, productGUID, ProductImageType)
+	if err != nil {
+		s.logger.Errorw("Failed to list product images",
+			"error", err,
+			"productGUID", productGUID)
+		return nil, fmt.Errorf("failed to list product images: %w", err)
+	}
+
+	return toProductImages(images), nil
+}
+
+// ReorderProductImages sets the gallery order; imageGUIDs must list every image of the product exactly once
+func (s *ImageService) ReorderProductImages(ctx context.Context, productGUID uuid.UUID, imageGUIDs []uuid.UUID) ([]*domain.ProductImage, error) {
+	images, err := s.repo.ListImagesByOwner(ctx, productGUID, ProductImageType)
+	if err != nil {
+		s.logger.Errorw("Failed to list product images for reordering",
+			"error", err,
+			"productGUID", productGUID)
+		return nil, fmt.Errorf("failed to list product images: %w", err)
+	}
+	if len(images) == 0 {
+		return nil, ErrNotFound
+	}
+
+	// The new order must be a permutation of the current gallery
+	if len(imageGUIDs) != len(images) {
+		return nil, fmt.Errorf("%w: expected %d images, got %d", ErrInvalidOrder, len(images), len(imageGUIDs))
+	}
+	current := make(map[uuid.UUID]bool, len(images))
+	for _, image := range images {
+		current[image.GUID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(imageGUIDs))
+	for _, imageGUID := range imageGUIDs {
+		if !current[imageGUID] {
+			return nil, fmt.Errorf("%w: image %s does not belong to product", ErrInvalidOrder, imageGUID)
+		}
+		if seen[imageGUID] {
+			return nil, fmt.Errorf("%w: image %s listed more than once", ErrInvalidOrder, imageGUID)
+		}
+		seen[imageGUID] = true
+	}
+
+	if err := s.repo.UpdateImagePositions(ctx, productGUID, ProductImageType, imageGUIDs); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		s.logger.Errorw("Failed to update product image positions",
+			"error", err,
+			"productGUID", productGUID)
+		return nil, fmt.Errorf("failed to update image positions: %w", err)
+	}
+
+	return s.ListProductImages(ctx, productGUID)
+}
+
+// SetPrimaryProductImage makes the given image the primary image of the product's gallery
+func (s *ImageService) SetPrimaryProductImage(ctx context.Context, productGUID, imageGUID uuid.UUID) (*domain.ProductImage, error) {
+	if err := s.repo.SetPrimaryImage(ctx, productGUID, ProductImageType, imageGUID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		s.logger.Errorw("Failed to set primary product image",
+			"error", err,
+			"productGUID", productGUID,
+			"imageGUID", imageGUID)
+		return nil, fmt.Errorf("failed to set primary image: %w", err)
+	}
+
+	image, err := s.repo.GetImageByID(ctx, imageGUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	return image.ToProductImage(), nil
+}
+
+// DeleteProductImage removes a single image from a product's gallery, closing the
+// gap in positions and promoting the next image if the primary was removed
+func (s *ImageService) DeleteProductImage(ctx context.Context, productGUID, imageGUID uuid.UUID) error {
+	image, err := s.repo.GetImageByID(ctx, imageGUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to get image: %w", err)
+	}
+	if image.OwnerGUID != productGUID || image.TypeName != ProductImageType {
+		return ErrNotFound
+	}
+
+	if err := s.removeImage(ctx, image); err != nil {
+		return err
+	}
+
+	remaining, err := s.repo.ListImagesByOwner(ctx, productGUID, ProductImageType)
+	if err != nil {
+		return fmt.Errorf("failed to list remaining images: %w", err)
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	order := make([]uuid.UUID, len(remaining))
+	for i, img := range remaining {
+		order[i] = img.GUID
+	}
+	if err := s.repo.UpdateImagePositions(ctx, productGUID, ProductImageType, order); err != nil {
+		s.logger.Warnw("Failed to compact product image positions",
+			"error", err,
+			"productGUID", productGUID)
+	}
+
+	if image.IsPrimary {
+		if err := s.repo.SetPrimaryImage(ctx, productGUID, ProductImageType, remaining[0].GUID); err != nil {
+			s.logger.Warnw("Failed to promote new primary product image",
+				"error", err,
+				"productGUID", productGUID,
+				"imageGUID", remaining[0].GUID)
+		}
+	}
+
 	return nil
 }
 
+// toProductImages converts images to product image views
+func toProductImages(images []*domain.Image) []*domain.ProductImage {
+	result := make([]*domain.ProductImage, 0, len(images))
+	for _, image := range images {
+		result = append(result, image.ToProductImage())
+	}
+	return result
+}
+
+// imageKey returns the storage key for an image variant of the given type
+func (s *ImageService) imageKey(typeName string, ownerGUID, imageGUID uuid.UUID, size string) string {
+	return s.storage.GenerateImageKey(typeName, ownerGUID, imageGUID, size)
+}
+
+// variantURL returns the image's URL for a size, or "" if it has none
+func variantURL(image *domain.Image, size string) string {
+	switch size {
+	case "small":
+		return image.SmallURL
+	case "medium":
+		return image.MediumURL
+	case "large":
+		return image.LargeURL
+	}
+	return ""
+}
+
+// setVariantURL sets the image's URL for a size; sizes without a URL field are ignored
+func setVariantURL(image *domain.Image, size, url string) {
+	switch size {
+	case "small":
+		image.SmallURL = url
+	case "medium":
+		image.MediumURL = url
+	case "large":
+		image.LargeURL = url
+	}
+}
+
 // ValidateImageAccess checks if a user has access to an image
 func (s *ImageService) ValidateImageAccess(ctx context.Context, userGUID uuid.UUID, imageGUID uuid.UUID) error {
 	// Get the image
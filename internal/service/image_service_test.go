@@ -1,8 +1,11 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"io"
 	"testing"
 	"time"
 
@@ -48,6 +51,24 @@ func setupTestService(t *testing.T) (
 					"large":  {Width: 800, Height: 800},
 				},
 			},
+			{
+				Name: "organization",
+				Sizes: domain.SizeSet{
+					"small":  {Width: 400, Height: 0},
+					"medium": {Width: 800, Height: 0},
+					"large":  {Width: 1000, Height: 0},
+				},
+			},
+			{
+				Name:        "product",
+				Cardinality: domain.CardinalityMultiple,
+				MaxImages:   3,
+				Sizes: domain.SizeSet{
+					"small":  {Width: 200, Height: 0},
+					"medium": {Width: 600, Height: 0},
+					"large":  {Width: 1200, Height: 0},
+				},
+			},
 		},
 	}
 
@@ -97,7 +118,7 @@ func TestUploadUserImage(t *testing.T) {
 	mockProcessor.SetImageDimensions(imageData, 1200, 800)
 
 	// Test uploading an image
-	userImage, err := service.UploadUserImage(ctx, userGUID, imageData)
+	userImage, err := service.UploadUserImage(ctx, userGUID, bytes.NewReader(imageData), domain.UploadOptions{})
 
 	// Verify results
 	require.NoError(t, err)
@@ -213,7 +234,7 @@ func TestUploadUserImage_InvalidImage(t *testing.T) {
 	emptyData := []byte{}
 
 	// Test uploading an empty image
-	_, err := service.UploadUserImage(ctx, userGUID, emptyData)
+	_, err := service.UploadUserImage(ctx, userGUID, bytes.NewReader(emptyData), domain.UploadOptions{})
 
 	// Verify error
 	assert.Error(t, err)
@@ -234,7 +255,7 @@ func TestUploadUserImage_ImageTooLarge(t *testing.T) {
 	largeData := make([]byte, 100) // 100 bytes, exceeds the 10 byte limit
 
 	// Test uploading a large image
-	_, err := service.UploadUserImage(ctx, userGUID, largeData)
+	_, err := service.UploadUserImage(ctx, userGUID, bytes.NewReader(largeData), domain.UploadOptions{})
 
 	// Verify error
 	assert.Error(t, err)
@@ -255,7 +276,7 @@ func TestUploadUserImage_UnsupportedType(t *testing.T) {
 	mockProcessor.SetDetectedFormat(imageData, "image/tiff") // Not supported
 
 	// Test uploading an unsupported image format
-	_, err := service.UploadUserImage(ctx, userGUID, imageData)
+	_, err := service.UploadUserImage(ctx, userGUID, bytes.NewReader(imageData), domain.UploadOptions{})
 
 	// Verify error
 	assert.Error(t, err)
@@ -277,7 +298,7 @@ func TestUploadUserImage_ProcessingFailed(t *testing.T) {
 	mockProcessor.SetShouldFailProcessing(true)
 
 	// Test uploading with processing failure
-	_, err := service.UploadUserImage(ctx, userGUID, imageData)
+	_, err := service.UploadUserImage(ctx, userGUID, bytes.NewReader(imageData), domain.UploadOptions{})
 
 	// Verify error
 	assert.Error(t, err)
@@ -352,3 +373,564 @@ func TestValidateImageAccess(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, ErrUnauthorized))
 }
+
+// TestUploadOrganizationImage tests uploading an organization image
+func TestUploadOrganizationImage(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, mockStorage, mockProcessor, _ := setupTestService(t)
+
+	// Create test data
+	ctx := context.Background()
+	orgGUID := uuid.New()
+	imageData := createTestImageData()
+
+	// Configure mock processor
+	mockProcessor.SetDetectedFormat(imageData, "image/png")
+	mockProcessor.SetImageDimensions(imageData, 1600, 900)
+
+	// Test uploading an image
+	orgImage, err := service.UploadOrganizationImage(ctx, orgGUID, bytes.NewReader(imageData), domain.UploadOptions{})
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, orgGUID, orgImage.OrganizationGUID)
+	assert.NotEqual(t, uuid.Nil, orgImage.ImageGUID)
+	assert.Contains(t, orgImage.LargeURL, "images/organization/"+orgGUID.String())
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+
+	// Verify variants were stored under organization keys
+	for _, size := range []string{"small", "medium", "large"} {
+		key := mockStorage.GenerateOrganizationImageKey(orgGUID, orgImage.ImageGUID, size)
+		assert.True(t, mockStorage.HasObject(key), "missing variant %s", size)
+	}
+
+	// Uploading again replaces the previous image
+	replaced, err := service.UploadOrganizationImage(ctx, orgGUID, bytes.NewReader(imageData), domain.UploadOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, orgImage.ImageGUID, replaced.ImageGUID)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+	assert.True(t, mockStorage.HasObject(mockStorage.GenerateOrganizationImageKey(orgGUID, orgImage.ImageGUID, "large")))
+
+	// The replaced image's files are deleted by the queue
+	assert.Equal(t, 1, drainJobs(t, service))
+	assert.False(t, mockStorage.HasObject(mockStorage.GenerateOrganizationImageKey(orgGUID, orgImage.ImageGUID, "large")))
+}
+
+// TestGetOrganizationImage tests retrieving an organization image
+func TestGetOrganizationImage(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, _, _, _ := setupTestService(t)
+
+	// Create test data
+	ctx := context.Background()
+	orgGUID := uuid.New()
+	testImage := createTestImage(orgGUID)
+	testImage.TypeName = "organization"
+
+	// Save the test image to the mock repository
+	err := mockRepo.SaveImage(ctx, testImage)
+	require.NoError(t, err)
+
+	// Test getting the organization image
+	orgImage, err := service.GetOrganizationImage(ctx, orgGUID)
+	require.NoError(t, err)
+	assert.Equal(t, orgGUID, orgImage.OrganizationGUID)
+	assert.Equal(t, testImage.GUID, orgImage.ImageGUID)
+
+	// A user image for the same GUID is not an organization image
+	_, err = service.GetUserImage(ctx, orgGUID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestDeleteOrganizationImage tests deleting an organization image
+func TestDeleteOrganizationImage(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, _, _, _ := setupTestService(t)
+
+	// Create test data
+	ctx := context.Background()
+	orgGUID := uuid.New()
+	testImage := createTestImage(orgGUID)
+	testImage.TypeName = "organization"
+
+	// Save the test image to the mock repository
+	err := mockRepo.SaveImage(ctx, testImage)
+	require.NoError(t, err)
+
+	// Test deleting the organization image
+	err = service.DeleteOrganizationImage(ctx, orgGUID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+
+	// Deleting again reports not found
+	err = service.DeleteOrganizationImage(ctx, orgGUID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// addTestProductImages adds count images to a product gallery and returns their GUIDs in order
+func addTestProductImages(t *testing.T, service *ImageService, productGUID uuid.UUID, count int) []uuid.UUID {
+	var guids []uuid.UUID
+	for i := 0; i < count; i++ {
+		productImage, err := service.AddProductImage(context.Background(), productGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+		require.NoError(t, err)
+		guids = append(guids, productImage.ImageGUID)
+	}
+	return guids
+}
+
+// TestAddProductImage tests appending images to a product gallery
+func TestAddProductImage(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	productGUID := uuid.New()
+	guids := addTestProductImages(t, service, productGUID, 3)
+
+	// Images accumulate instead of replacing each other
+	assert.Equal(t, 3, mockRepo.GetImageCount())
+	assert.True(t, mockStorage.HasObject(mockStorage.GenerateProductImageKey(productGUID, guids[0], "large")))
+
+	images, err := service.ListProductImages(ctx, productGUID)
+	require.NoError(t, err)
+	require.Len(t, images, 3)
+	for i, image := range images {
+		assert.Equal(t, guids[i], image.ImageGUID)
+		assert.Equal(t, i, image.Position)
+		assert.Equal(t, i == 0, image.IsPrimary, "only the first image should be primary")
+	}
+
+	// The configured limit is enforced
+	_, err = service.AddProductImage(ctx, productGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrImageLimit))
+	assert.Equal(t, 3, mockRepo.GetImageCount())
+}
+
+// TestReorderProductImages tests reordering a product gallery
+func TestReorderProductImages(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	productGUID := uuid.New()
+	guids := addTestProductImages(t, service, productGUID, 3)
+
+	// Reverse the order
+	reordered, err := service.ReorderProductImages(ctx, productGUID, []uuid.UUID{guids[2], guids[1], guids[0]})
+	require.NoError(t, err)
+	require.Len(t, reordered, 3)
+	assert.Equal(t, guids[2], reordered[0].ImageGUID)
+	assert.Equal(t, guids[0], reordered[2].ImageGUID)
+
+	// Incomplete, duplicated and foreign orders are rejected
+	_, err = service.ReorderProductImages(ctx, productGUID, []uuid.UUID{guids[0], guids[1]})
+	assert.True(t, errors.Is(err, ErrInvalidOrder))
+	_, err = service.ReorderProductImages(ctx, productGUID, []uuid.UUID{guids[0], guids[0], guids[1]})
+	assert.True(t, errors.Is(err, ErrInvalidOrder))
+	_, err = service.ReorderProductImages(ctx, productGUID, []uuid.UUID{guids[0], guids[1], uuid.New()})
+	assert.True(t, errors.Is(err, ErrInvalidOrder))
+}
+
+// TestSetPrimaryProductImage tests changing the primary gallery image
+func TestSetPrimaryProductImage(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	productGUID := uuid.New()
+	guids := addTestProductImages(t, service, productGUID, 2)
+
+	primary, err := service.SetPrimaryProductImage(ctx, productGUID, guids[1])
+	require.NoError(t, err)
+	assert.True(t, primary.IsPrimary)
+
+	images, err := service.ListProductImages(ctx, productGUID)
+	require.NoError(t, err)
+	assert.False(t, images[0].IsPrimary)
+	assert.True(t, images[1].IsPrimary)
+
+	// An image of another product cannot be made primary
+	otherGUIDs := addTestProductImages(t, service, uuid.New(), 1)
+	_, err = service.SetPrimaryProductImage(ctx, productGUID, otherGUIDs[0])
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestDeleteProductImage tests removing one image from a gallery
+func TestDeleteProductImage(t *testing.T) {
+	// Set up test service and mocks
+	service, _, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	productGUID := uuid.New()
+	guids := addTestProductImages(t, service, productGUID, 3)
+
+	// Deleting the primary image promotes the next one and closes the gap
+	err := service.DeleteProductImage(ctx, productGUID, guids[0])
+	require.NoError(t, err)
+	drainJobs(t, service)
+	assert.False(t, mockStorage.HasObject(mockStorage.GenerateProductImageKey(productGUID, guids[0], "large")))
+
+	images, err := service.ListProductImages(ctx, productGUID)
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, guids[1], images[0].ImageGUID)
+	assert.Equal(t, 0, images[0].Position)
+	assert.True(t, images[0].IsPrimary)
+	assert.Equal(t, 1, images[1].Position)
+
+	// Deleting an image through the wrong product is not found
+	err = service.DeleteProductImage(ctx, uuid.New(), guids[1])
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestGenericImageLifecycle tests upload, get and delete through the type-agnostic methods
+func TestGenericImageLifecycle(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	// Uploading twice to a single-image type replaces the first image
+	first, err := service.UploadImage(ctx, "organization", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	second, err := service.UploadImage(ctx, "organization", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+	drainJobs(t, service)
+	assert.False(t, mockStorage.HasObject(mockStorage.GenerateImageKey("organization", ownerGUID, first.GUID, "small")))
+	assert.True(t, mockStorage.HasObject(mockStorage.GenerateImageKey("organization", ownerGUID, second.GUID, "small")))
+
+	image, err := service.GetImage(ctx, "organization", ownerGUID)
+	require.NoError(t, err)
+	assert.Equal(t, second.GUID, image.GUID)
+
+	// Deleting a collection type removes every image in it
+	addTestProductImages(t, service, ownerGUID, 2)
+	err = service.DeleteImage(ctx, "product", ownerGUID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+
+	err = service.DeleteImage(ctx, "organization", ownerGUID)
+	require.NoError(t, err)
+	_, err = service.GetImage(ctx, "organization", ownerGUID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestGenericImage_UnknownType tests that unconfigured type names are rejected
+func TestGenericImage_UnknownType(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	_, err := service.UploadImage(ctx, "banner", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrUnknownType))
+
+	_, err = service.GetImage(ctx, "banner", ownerGUID)
+	assert.True(t, errors.Is(err, ErrUnknownType))
+
+	err = service.DeleteImage(ctx, "banner", ownerGUID)
+	assert.True(t, errors.Is(err, ErrUnknownType))
+}
+
+// TestUploadImage_Options tests alt text and crop handling
+func TestUploadImage_Options(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, mockProcessor, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+	imageData := createTestImageData()
+	mockProcessor.SetImageDimensions(imageData, 800, 600)
+
+	// Alt text is stored and the crop is passed to the processor
+	crop := domain.Crop{X: 100, Y: 50, Width: 400, Height: 400}
+	image, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(imageData), domain.UploadOptions{
+		AltText: "Profile photo",
+		Crop:    &crop,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Profile photo", image.AltText)
+	applied, ok := mockProcessor.GetCrop(imageData)
+	require.True(t, ok)
+	assert.Equal(t, crop, applied)
+
+	// A crop reaching past the image edge is rejected
+	_, err = service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(imageData), domain.UploadOptions{
+		Crop: &domain.Crop{X: 500, Y: 0, Width: 400, Height: 400},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidOptions))
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "crop", fieldErr.Field)
+
+	// Over-long alt text is rejected
+	longAltText := make([]rune, domain.MaxAltTextLength+1)
+	for i := range longAltText {
+		longAltText[i] = 'é'
+	}
+	_, err = service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(imageData), domain.UploadOptions{
+		AltText: string(longAltText),
+	})
+	assert.True(t, errors.Is(err, ErrInvalidOptions))
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "altText", fieldErr.Field)
+}
+
+// TestUploadImage_SizeLimits tests that the size limit is enforced while reading and can be set per type
+func TestUploadImage_SizeLimits(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, _, _, imageConfig := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+	imageData := createTestImageData()
+
+	// Give the user type a limit just below the test image size
+	imageConfig.Types[0].MaxBytes = int64(len(imageData) - 1)
+	assert.Equal(t, int64(len(imageData)-1), service.MaxImageSizeFor("user"))
+	assert.Equal(t, service.MaxImageSize(), service.MaxImageSizeFor("organization"))
+
+	_, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(imageData), domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+
+	// Other types keep the service default
+	_, err = service.UploadImage(ctx, "organization", ownerGUID, bytes.NewReader(imageData), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	// A stream that never ends is cut off at the limit rather than buffered
+	service.SetMaxImageSize(1024)
+	endless := io.MultiReader(bytes.NewReader(imageData), neverEndingReader{})
+	_, err = service.UploadImage(ctx, "organization", ownerGUID, endless, domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+}
+
+// neverEndingReader yields zero bytes forever
+type neverEndingReader struct{}
+
+func (neverEndingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// TestImageSizeURL tests resolving size URLs, including the default image fallback
+func TestImageSizeURL(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, imageConfig := setupTestService(t)
+	imageConfig.Types[1].DefaultImage = "/static/organization-{size}.png"
+
+	ctx := context.Background()
+	userGUID := uuid.New()
+
+	image, err := service.UploadImage(ctx, "user", userGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	url, err := service.ImageSizeURL(ctx, "user", userGUID, "small", "")
+	require.NoError(t, err)
+	assert.Equal(t, image.SmallURL, url)
+
+	_, err = service.ImageSizeURL(ctx, "user", userGUID, "huge", "")
+	assert.True(t, errors.Is(err, ErrUnknownSize))
+
+	_, err = service.ImageSizeURL(ctx, "user", uuid.New(), "small", "")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	url, err = service.ImageSizeURL(ctx, "organization", uuid.New(), "large", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/static/organization-large.png", url)
+}
+
+// TestPlaceholders tests placeholder metadata and rendering for types that enable them
+func TestPlaceholders(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, imageConfig := setupTestService(t)
+	imageConfig.Types[0].Placeholder = true
+
+	ctx := context.Background()
+	userGUID := uuid.New()
+
+	placeholderImage, err := service.GetImageOrPlaceholder(ctx, "user", userGUID, "Ada Lovelace")
+	require.NoError(t, err)
+	assert.True(t, placeholderImage.IsPlaceholder)
+	assert.Equal(t, "/v1/placeholders/user/"+userGUID.String()+"/small?initials=AL", placeholderImage.SmallURL)
+
+	url, err := service.ImageSizeURL(ctx, "user", userGUID, "large", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/placeholders/user/"+userGUID.String()+"/large", url)
+
+	data, err := service.RenderPlaceholder("user", userGUID, "medium", "AL")
+	require.NoError(t, err)
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 100, config.Width)
+	assert.Equal(t, 100, config.Height)
+
+	_, err = service.RenderPlaceholder("user", userGUID, "huge", "")
+	assert.True(t, errors.Is(err, ErrUnknownSize))
+
+	// Types without placeholders have none
+	_, err = service.RenderPlaceholder("organization", userGUID, "small", "")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = service.GetImageOrPlaceholder(ctx, "organization", userGUID, "")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// Real images win over placeholders
+	_, err = service.UploadImage(ctx, "user", userGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	uploaded, err := service.GetImageOrPlaceholder(ctx, "user", userGUID, "Ada Lovelace")
+	require.NoError(t, err)
+	assert.False(t, uploaded.IsPlaceholder)
+}
+
+// TestGetImagesByOwners tests looking up many owners' images at once
+func TestGetImagesByOwners(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	withImage := uuid.New()
+	withoutImage := uuid.New()
+
+	uploaded, err := service.UploadImage(ctx, "user", withImage, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	images, err := service.GetImagesByOwners(ctx, "user", []uuid.UUID{withImage, withoutImage, withImage})
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	require.NotNil(t, images[withImage])
+	assert.Equal(t, uploaded.GUID, images[withImage].GUID)
+	assert.Contains(t, images, withoutImage)
+	assert.Nil(t, images[withoutImage])
+
+	// Galleries return their primary image
+	productGUID := uuid.New()
+	first, err := service.UploadImage(ctx, "product", productGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	_, err = service.UploadImage(ctx, "product", productGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	images, err = service.GetImagesByOwners(ctx, "product", []uuid.UUID{productGUID})
+	require.NoError(t, err)
+	assert.Equal(t, first.GUID, images[productGUID].GUID)
+
+	_, err = service.GetImagesByOwners(ctx, "unknown", []uuid.UUID{withImage})
+	assert.True(t, errors.Is(err, ErrUnknownType))
+
+	tooMany := make([]uuid.UUID, MaxBatchOwners+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	_, err = service.GetImagesByOwners(ctx, "user", tooMany)
+	assert.True(t, errors.Is(err, ErrBatchTooLarge))
+}
+
+func TestListImages(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	productGUID := uuid.New()
+
+	var uploaded []uuid.UUID
+	for i := 0; i < 3; i++ {
+		image, err := service.UploadImage(ctx, "product", productGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+		require.NoError(t, err)
+		uploaded = append(uploaded, image.GUID)
+	}
+	_, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	// Page through the products two at a time, newest first
+	filter := repository.ImageFilter{TypeName: "product"}
+	first, err := service.ListImages(ctx, filter, "", 2)
+	require.NoError(t, err)
+	require.Len(t, first.Images, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := service.ListImages(ctx, filter, first.NextCursor, 2)
+	require.NoError(t, err)
+	require.Len(t, second.Images, 1)
+	assert.Empty(t, second.NextCursor)
+
+	var listed []uuid.UUID
+	for _, image := range append(first.Images, second.Images...) {
+		listed = append(listed, image.GUID)
+	}
+	assert.ElementsMatch(t, uploaded, listed)
+	assert.False(t, first.Images[1].UpdatedAt.Before(second.Images[0].UpdatedAt))
+
+	// Filters combine
+	all, err := service.ListImages(ctx, repository.ImageFilter{}, "", 0)
+	require.NoError(t, err)
+	assert.Len(t, all.Images, 4)
+
+	owned, err := service.ListImages(ctx, repository.ImageFilter{OwnerGUID: productGUID, UpdatedTo: time.Now().Add(time.Hour)}, "", 0)
+	require.NoError(t, err)
+	assert.Len(t, owned.Images, 3)
+
+	future, err := service.ListImages(ctx, repository.ImageFilter{CreatedFrom: time.Now().Add(time.Hour)}, "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, future.Images)
+
+	_, err = service.ListImages(ctx, filter, "not-a-cursor", 2)
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+
+	_, err = service.ListImages(ctx, repository.ImageFilter{TypeName: "unknown"}, "", 0)
+	assert.True(t, errors.Is(err, ErrUnknownType))
+}
+
+func TestConditionalWrites(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+	upload := func(precondition domain.Precondition) (*domain.Image, error) {
+		return service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(createTestImageData()),
+			domain.UploadOptions{Precondition: precondition})
+	}
+
+	// If-Match needs a current image; If-None-Match: * needs there to be none
+	_, err := upload(domain.Precondition{IfMatch: []string{"*"}})
+	assert.True(t, errors.Is(err, ErrPreconditionFailed))
+
+	first, err := upload(domain.Precondition{IfNoneMatch: []string{"*"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first.Version)
+
+	_, err = upload(domain.Precondition{IfNoneMatch: []string{"*"}})
+	assert.True(t, errors.Is(err, ErrPreconditionFailed))
+
+	// Replacing requires the current entity tag
+	_, err = upload(domain.Precondition{IfMatch: []string{`"stale"`, "W/" + first.ETag()}})
+	assert.True(t, errors.Is(err, ErrPreconditionFailed))
+
+	second, err := upload(domain.Precondition{IfMatch: []string{`"stale"`, first.ETag()}})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.GUID, second.GUID)
+	assert.NotEqual(t, first.ETag(), second.ETag())
+	assert.Equal(t, int64(2), second.Version)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+
+	// Deleting with a stale tag fails and keeps the image
+	err = service.DeleteImageIf(ctx, "user", ownerGUID, domain.Precondition{IfMatch: []string{first.ETag()}})
+	assert.True(t, errors.Is(err, ErrPreconditionFailed))
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+
+	require.NoError(t, service.DeleteImageIf(ctx, "user", ownerGUID, domain.Precondition{IfMatch: []string{second.ETag()}}))
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+
+	// A write based on a stale read is rejected by the repository
+	third, err := upload(domain.Precondition{})
+	require.NoError(t, err)
+	stale := *third
+	stale.Version--
+	assert.True(t, errors.Is(mockRepo.ReplaceImage(ctx, &stale, domain.NewImage(ownerGUID, "user")), repository.ErrConflict))
+	assert.True(t, errors.Is(mockRepo.ReplaceImage(ctx, nil, domain.NewImage(ownerGUID, "user")), repository.ErrConflict))
+	assert.True(t, errors.Is(mockRepo.DeleteImageVersion(ctx, third.GUID, stale.Version), repository.ErrConflict))
+}
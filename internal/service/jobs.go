@@ -0,0 +1,333 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/jobs"
+	"github.com/antonrybalko/image-service-go/internal/processor"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Background processing settings
+const (
+	// jobKindProcessUpload is the queue job kind that processes a submitted
+	// upload
+	jobKindProcessUpload = "upload.process"
+
+	// jobRetention is how long finished jobs can be looked up
+	jobRetention = 24 * time.Hour
+
+	// jobInternalError is reported for failures that aren't the client's
+	jobInternalError = "internal error"
+)
+
+// processUploadPayload is the queue job payload of jobKindProcessUpload
+type processUploadPayload struct {
+	JobGUID uuid.UUID `json:"jobGuid"`
+}
+
+// jobClientErrors are the failures reported to clients as they are; any
+// other failure is reported as an internal error
+var jobClientErrors = []error{
+	ErrInvalidImage,
+	ErrImageTooLarge,
+	ErrUnsupportedType,
+	ErrProcessingFailed,
+	ErrImageLimit,
+	ErrPreconditionFailed,
+	ErrConcurrentUpdate,
+}
+
+// SetJobStore sets the store tracking background uploads
+func (s *ImageService) SetJobStore(store jobs.Store) {
+	s.jobs = store
+}
+
+// SetQueue sets the queue background work is dispatched through
+func (s *ImageService) SetQueue(jobQueue queue.Queue) {
+	s.jobQueue = jobQueue
+}
+
+// RegisterJobHandlers registers the handlers for the background work the
+// service queues
+func (s *ImageService) RegisterJobHandlers(worker *queue.Worker) {
+	worker.Handle(jobKindProcessUpload, s.handleProcessUpload)
+	worker.Handle(jobKindReprocessImages, s.handleReprocessImages)
+	worker.Handle(jobKindDeleteFiles, s.handleDeleteFiles)
+}
+
+// SubmitUpload checks an upload of the given type and queues it for
+// processing in the background. The original is kept in storage until a
+// queue worker has run it through UploadImage; GetJob reports the outcome.
+func (s *ImageService) SubmitUpload(ctx context.Context, userID, typeName string, ownerGUID uuid.UUID, imageData io.Reader, opts domain.UploadOptions) (*domain.Job, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return nil, err
+	}
+
+	// Reject what can be rejected without decoding the image
+	if utf8.RuneCountInString(opts.AltText) > domain.MaxAltTextLength {
+		return nil, invalidField("altText", "alt text exceeds %d characters", domain.MaxAltTextLength)
+	}
+	current, err := s.currentImage(ctx, typeName, ownerGUID)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Precondition.Allows(current) {
+		return nil, ErrPreconditionFailed
+	}
+
+	data, err := io.ReadAll(newUploadReader(imageData, s.MaxImageSizeFor(typeName)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrInvalidImage
+	}
+	contentType, err := s.processor.DetectImageFormat(data[:min(len(data), processor.HeaderSize)])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	now := time.Now().UTC()
+	job := &domain.Job{
+		GUID:      uuid.New(),
+		UserID:    userID,
+		TypeName:  typeName,
+		OwnerGUID: ownerGUID,
+		Status:    domain.JobPending,
+		Options:   domain.NewJobOptions(opts),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	key := s.storage.GenerateJobKey(job.GUID)
+	if _, err := s.storage.Put(ctx, key, data, contentType); err != nil {
+		s.logger.Errorw("Failed to store job original",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID,
+			"jobGUID", job.GUID)
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	if err := s.jobs.Create(ctx, job); err != nil {
+		s.logger.Errorw("Failed to create job",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID,
+			"jobGUID", job.GUID)
+		s.deleteJobOriginal(ctx, job.GUID)
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	queued, err := queue.NewJob(jobKindProcessUpload, processUploadPayload{JobGUID: job.GUID})
+	if err == nil {
+		// The queue job shares the job's GUID so GetJob can look it up.
+		// Uploads are waited on by clients, so they go ahead of bulk work.
+		queued.ID = job.GUID
+		queued.Priority = queue.PriorityHigh
+		err = s.jobQueue.Enqueue(ctx, queued)
+	}
+	if err != nil {
+		s.logger.Errorw("Failed to queue job",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID,
+			"jobGUID", job.GUID)
+		job.Status = domain.JobFailed
+		job.Error = jobErrorMessage(err)
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.jobs.Finish(ctx, job); err != nil {
+			s.logger.Errorw("Failed to finish job", "error", err, "jobGUID", job.GUID)
+		}
+		s.deleteJobOriginal(ctx, job.GUID)
+		return nil, fmt.Errorf("failed to queue job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetJob returns a job submitted by userID along with, once it is ready, the
+// image it produced; the image is nil if it has been removed since. Jobs of
+// other users are reported as not found. An unfinished job whose queue job
+// has given up is reported, and stored, as failed.
+func (s *ImageService) GetJob(ctx context.Context, userID string, jobGUID uuid.UUID) (*domain.Job, *domain.Image, error) {
+	job, err := s.jobs.Get(ctx, jobGUID)
+	if err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	if job.UserID != userID {
+		return nil, nil, ErrNotFound
+	}
+	if !job.IsFinished() {
+		if job, err = s.reconcileJob(ctx, job); err != nil {
+			return nil, nil, err
+		}
+	}
+	if job.ImageGUID == nil {
+		return job, nil, nil
+	}
+
+	image, err := s.repo.GetImageByID(ctx, *job.ImageGUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return job, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	return job, image, nil
+}
+
+// reconcileJob fails an unfinished job whose queue job is dead, or already
+// purged, as no worker will process it any more. The queue owns attempts and
+// leases; the job store only records what clients are told, and a handler
+// that fails for good without finishing the job leaves it behind. A job
+// finished meanwhile by its handler is read again rather than overwritten.
+func (s *ImageService) reconcileJob(ctx context.Context, job *domain.Job) (*domain.Job, error) {
+	queued, err := s.jobQueue.Get(ctx, job.GUID)
+	if err != nil && !errors.Is(err, queue.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get queued job: %w", err)
+	}
+	if err == nil && queued.Status != queue.StatusDead {
+		return job, nil
+	}
+
+	s.logger.Warnw("Job abandoned by the queue",
+		"typeName", job.TypeName,
+		"ownerGUID", job.OwnerGUID,
+		"jobGUID", job.GUID)
+	job.Status = domain.JobFailed
+	job.Error = jobInternalError
+	job.UpdatedAt = time.Now().UTC()
+	if err := s.jobs.Finish(ctx, job); err != nil {
+		if !errors.Is(err, jobs.ErrFinished) {
+			return nil, fmt.Errorf("failed to finish job: %w", err)
+		}
+		if job, err = s.jobs.Get(ctx, job.GUID); err != nil {
+			return nil, fmt.Errorf("failed to get job: %w", err)
+		}
+		return job, nil
+	}
+	s.deleteJobOriginal(ctx, job.GUID)
+	return job, nil
+}
+
+// CleanupJobs removes finished jobs past retention
+func (s *ImageService) CleanupJobs(ctx context.Context) error {
+	if err := s.jobs.DeleteFinished(ctx, time.Now().UTC().Add(-jobRetention)); err != nil {
+		return fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return nil
+}
+
+// handleProcessUpload processes the upload job named by a queue job
+func (s *ImageService) handleProcessUpload(ctx context.Context, queued *queue.Job) error {
+	var payload processUploadPayload
+	if err := queued.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+	return s.processJob(ctx, payload.JobGUID, queued.Attempts >= queued.MaxAttempts)
+}
+
+// processJob claims a job, runs its original through UploadImage and stores
+// the outcome. Failures that may be transient are returned for the queue to
+// retry, unless this is the last attempt; the job stays processing meanwhile.
+func (s *ImageService) processJob(ctx context.Context, jobGUID uuid.UUID, lastAttempt bool) error {
+	job, err := s.jobs.Claim(ctx, jobGUID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			return queue.Permanent(err)
+		}
+		return fmt.Errorf("failed to claim job: %w", err)
+	}
+	if job == nil {
+		// Already finished, e.g. by an attempt whose settlement was lost
+		return nil
+	}
+
+	var image *domain.Image
+	imageData, err := s.storage.Get(ctx, s.storage.GenerateJobKey(job.GUID))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	} else {
+		image, err = s.UploadImage(ctx, job.TypeName, job.OwnerGUID, bytes.NewReader(imageData), job.Options.UploadOptions())
+	}
+	if err != nil && !isJobClientError(err) && !lastAttempt {
+		s.logger.Warnw("Job attempt failed",
+			"error", err,
+			"typeName", job.TypeName,
+			"ownerGUID", job.OwnerGUID,
+			"jobGUID", job.GUID)
+		return err
+	}
+
+	job.UpdatedAt = time.Now().UTC()
+	if err != nil {
+		s.logger.Warnw("Job failed",
+			"error", err,
+			"typeName", job.TypeName,
+			"ownerGUID", job.OwnerGUID,
+			"jobGUID", job.GUID)
+		job.Status = domain.JobFailed
+		job.Error = jobErrorMessage(err)
+	} else {
+		job.Status = domain.JobReady
+		job.ImageGUID = &image.GUID
+	}
+
+	if err := s.jobs.Finish(ctx, job); err != nil {
+		if errors.Is(err, jobs.ErrFinished) {
+			// Finished meanwhile by another attempt, or failed as abandoned;
+			// either dropped the original
+			return nil
+		}
+		// The original is kept for the retry
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+
+	s.deleteJobOriginal(ctx, job.GUID)
+	return nil
+}
+
+// deleteJobOriginal queues the deletion of the stored original of a job, as
+// the job is already settled
+func (s *ImageService) deleteJobOriginal(ctx context.Context, jobGUID uuid.UUID) {
+	s.queueFileDeletion(ctx, deleteFilesPayload{Keys: []string{s.storage.GenerateJobKey(jobGUID)}})
+}
+
+// isJobClientError reports whether a job failed because of its upload, so
+// retrying won't help
+func isJobClientError(err error) bool {
+	return jobErrorMessage(err) != jobInternalError
+}
+
+// jobErrorMessage describes why a job failed without exposing internals
+func jobErrorMessage(err error) string {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Error()
+	}
+	for _, clientErr := range jobClientErrors {
+		if errors.Is(err, clientErr) {
+			return clientErr.Error()
+		}
+	}
+	return jobInternalError
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// drainJobs processes the jobs queued by the service with a queue worker that
+// retries failed attempts right away, and returns how many attempts were made
+func drainJobs(t *testing.T, service *ImageService) int {
+	config := queue.DefaultConfig()
+	config.BaseBackoff = time.Nanosecond
+	config.MaxBackoff = time.Nanosecond
+	worker := queue.NewWorker(service.jobQueue, config, zap.NewNop().Sugar())
+	service.RegisterJobHandlers(worker)
+
+	processed, err := worker.Drain(context.Background())
+	require.NoError(t, err)
+	return processed
+}
+
+// TestSubmitUpload tests processing an upload in the background
+func TestSubmitUpload(t *testing.T) {
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	job, err := service.SubmitUpload(ctx, userID.String(), "user", userID, bytes.NewReader(createTestImageData()), domain.UploadOptions{AltText: "Later"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobPending, job.Status)
+	assert.True(t, mockStorage.HasObject(mockStorage.GenerateJobKey(job.GUID)))
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+
+	// Only the submitting user can see the job
+	_, _, err = service.GetJob(ctx, uuid.New().String(), job.GUID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// The upload, and then the deletion of its original
+	assert.Equal(t, 2, drainJobs(t, service))
+
+	job, image, err := service.GetJob(ctx, userID.String(), job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobReady, job.Status)
+	require.NotNil(t, image)
+	assert.Equal(t, image.GUID, *job.ImageGUID)
+	assert.Equal(t, "Later", image.AltText)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+
+	// The original is dropped once processed, and the job isn't run again
+	assert.False(t, mockStorage.HasObject(mockStorage.GenerateJobKey(job.GUID)))
+	assert.Equal(t, 0, drainJobs(t, service))
+}
+
+// TestSubmitUpload_Validation tests the checks done before a job is queued
+func TestSubmitUpload_Validation(t *testing.T) {
+	service, _, mockStorage, mockProcessor, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New().String()
+	ownerGUID := uuid.New()
+
+	_, err := service.SubmitUpload(ctx, userID, "unknown", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrUnknownType))
+
+	_, err = service.SubmitUpload(ctx, userID, "user", ownerGUID, bytes.NewReader(nil), domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrInvalidImage))
+
+	_, err = service.SubmitUpload(ctx, userID, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{
+		Precondition: domain.Precondition{IfMatch: []string{"*"}},
+	})
+	assert.True(t, errors.Is(err, ErrPreconditionFailed))
+
+	mockProcessor.SetShouldFailDetection(true)
+	_, err = service.SubmitUpload(ctx, userID, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+
+	assert.Equal(t, 0, mockStorage.GetObjectCount())
+}
+
+// TestSubmitUpload_Failed tests that a failed job reports why without internals
+func TestSubmitUpload_Failed(t *testing.T) {
+	service, mockRepo, mockStorage, mockProcessor, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	job, err := service.SubmitUpload(ctx, userID.String(), "user", userID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	// Failures caused by the upload aren't retried
+	mockProcessor.SetShouldFailProcessing(true)
+	assert.Equal(t, 2, drainJobs(t, service))
+
+	job, image, err := service.GetJob(ctx, userID.String(), job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, job.Status)
+	assert.Equal(t, ErrProcessingFailed.Error(), job.Error)
+	assert.Nil(t, job.ImageGUID)
+	assert.Nil(t, image)
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+	assert.False(t, mockStorage.HasObject(mockStorage.GenerateJobKey(job.GUID)))
+}
+
+// TestSubmitUpload_Retried tests that other failures are retried until the
+// job runs out of attempts
+func TestSubmitUpload_Retried(t *testing.T) {
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	job, err := service.SubmitUpload(ctx, userID.String(), "user", userID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	// Lose the original, so every attempt fails; the last one also queues
+	// the deletion of the original
+	mockStorage.ClearObjects()
+	assert.Equal(t, queue.DefaultMaxAttempts+1, drainJobs(t, service))
+
+	job, _, err = service.GetJob(ctx, userID.String(), job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, job.Status)
+	assert.Equal(t, jobInternalError, job.Error)
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+}
+
+// TestGetJob_Abandoned tests that a job is failed once its queue job is dead
+// without the handler having finished it
+func TestGetJob_Abandoned(t *testing.T) {
+	service, _, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	job, err := service.SubmitUpload(ctx, userID.String(), "user", userID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	// A worker claims the job and gives up on it, e.g. after a timeout
+	_, err = service.jobs.Claim(ctx, job.GUID, time.Now().UTC())
+	require.NoError(t, err)
+	queued, err := service.jobQueue.Claim(ctx, []string{jobKindProcessUpload}, time.Now().UTC(), time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, queued)
+	assert.Equal(t, job.GUID, queued.ID)
+
+	job, _, err = service.GetJob(ctx, userID.String(), job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobProcessing, job.Status)
+
+	require.NoError(t, service.jobQueue.Bury(ctx, queued, time.Now().UTC(), "handler timed out"))
+
+	job, _, err = service.GetJob(ctx, userID.String(), job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, job.Status)
+	assert.Equal(t, jobInternalError, job.Error)
+	assert.Equal(t, 1, drainJobs(t, service))
+	assert.False(t, mockStorage.HasObject(mockStorage.GenerateJobKey(job.GUID)))
+
+	stored, err := service.jobs.Get(ctx, job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, stored.Status)
+}
+
+// TestGetJob_FinishedMeanwhile tests that a job read as unfinished just
+// before its handler finished it is not failed
+func TestGetJob_FinishedMeanwhile(t *testing.T) {
+	service, mockRepo, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	job, err := service.SubmitUpload(ctx, userID.String(), "user", userID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, drainJobs(t, service))
+
+	queued, err := service.jobQueue.Get(ctx, job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusDone, queued.Status)
+
+	// The queue job is done, so the stale copy is left to be read again
+	stale := *job
+	reconciled, err := service.reconcileJob(ctx, &stale)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobPending, reconciled.Status)
+
+	// Once the queue job is purged, the finished job is read again rather
+	// than overwritten
+	service.SetQueue(queue.NewMemoryQueue())
+	reconciled, err = service.reconcileJob(ctx, &stale)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobReady, reconciled.Status)
+	require.NotNil(t, reconciled.ImageGUID)
+
+	job, image, err := service.GetJob(ctx, userID.String(), job.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobReady, job.Status)
+	assert.Empty(t, job.Error)
+	require.NotNil(t, image)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+)
+
+// originalPurgeBatch is how many expired originals are listed at a time
+const originalPurgeBatch = 100
+
+// storeOriginal keeps the uploaded bytes of a new image in the original
+// storage and records them in its metadata, along with the crop applied to
+// them before resizing
+func (s *ImageService) storeOriginal(ctx context.Context, image *domain.Image, data []byte, crop *domain.Crop) error {
+	key := s.originals.GenerateOriginalKey(image.TypeName, image.OwnerGUID, image.GUID)
+	if err := s.originals.PutPrivate(ctx, key, data, image.ContentType); err != nil {
+		s.logger.Errorw("Failed to store image original",
+			"error", err,
+			"typeName", image.TypeName,
+			"ownerGUID", image.OwnerGUID,
+			"imageGUID", image.GUID)
+		return fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	sum := sha256.Sum256(data)
+	image.OriginalKey = key
+	image.OriginalChecksum = hex.EncodeToString(sum[:])
+	image.OriginalSize = int64(len(data))
+	image.OriginalCrop = crop
+	return nil
+}
+
+// getOriginal returns the stored original of an image, or ErrNotFound if
+// none is kept. The checksum is verified, so a damaged original is never
+// used in place of the upload.
+func (s *ImageService) getOriginal(ctx context.Context, image *domain.Image) ([]byte, error) {
+	if image.OriginalKey == "" {
+		return nil, ErrNotFound
+	}
+
+	data, err := s.originals.Get(ctx, image.OriginalKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != image.OriginalChecksum {
+		return nil, fmt.Errorf("%w: original of image %s does not match its checksum", ErrStorageFailed, image.GUID)
+	}
+	return data, nil
+}
+
+// CleanupOriginals deletes the originals kept longer than their type's
+// retention, or kept at all by types that no longer keep them, and returns
+// how many were removed. The images and their variants are kept.
+func (s *ImageService) CleanupOriginals(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	removed := 0
+	for _, imageType := range s.config.Types {
+		keep, period := imageType.OriginalRetentionPeriod()
+		if keep && period == 0 {
+			continue
+		}
+
+		// Types that don't keep originals have a period of 0, dropping them all
+		before := now.Add(-period)
+		for {
+			images, err := s.repo.ListExpiredOriginals(ctx, imageType.Name, before, originalPurgeBatch)
+			if err != nil {
+				return removed, fmt.Errorf("failed to list expired originals: %w", err)
+			}
+
+			for _, image := range images {
+				if err := s.originals.Delete(ctx, image.OriginalKey); err != nil {
+					return removed, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+				}
+				if err := s.repo.ClearOriginal(ctx, image.GUID, image.OriginalKey); err != nil {
+					return removed, fmt.Errorf("failed to clear original: %w", err)
+				}
+				removed++
+			}
+
+			if len(images) < originalPurgeBatch {
+				break
+			}
+		}
+	}
+
+	return removed, nil
+}
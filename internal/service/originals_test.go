@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUploadImage_KeepsOriginal tests that the uploaded bytes are kept and
+// deleted along with the variants
+func TestUploadImage_KeepsOriginal(t *testing.T) {
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+	imageData := createTestImageData()
+	crop := domain.Crop{X: 10, Y: 10, Width: 300, Height: 300}
+	image, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(imageData), domain.UploadOptions{Crop: &crop})
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(imageData)
+	assert.Equal(t, mockStorage.GenerateOriginalKey("user", ownerGUID, image.GUID), image.OriginalKey)
+	assert.Equal(t, "originals/user/"+ownerGUID.String()+"/"+image.GUID.String(), image.OriginalKey)
+	assert.Equal(t, hex.EncodeToString(sum[:]), image.OriginalChecksum)
+	assert.Equal(t, int64(len(imageData)), image.OriginalSize)
+
+	stored, err := mockRepo.GetImageByID(ctx, image.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, image.OriginalKey, stored.OriginalKey)
+	require.NotNil(t, stored.OriginalCrop)
+	assert.Equal(t, crop, *stored.OriginalCrop)
+
+	original, err := mockStorage.Get(ctx, image.OriginalKey)
+	require.NoError(t, err)
+	assert.Equal(t, imageData, original)
+
+	require.NoError(t, service.DeleteImage(ctx, "user", ownerGUID))
+	drainJobs(t, service)
+	assert.False(t, mockStorage.HasObject(image.OriginalKey))
+}
+
+// TestUploadImage_OriginalStorage tests keeping originals apart from the
+// variants, and types that don't keep them
+func TestUploadImage_OriginalStorage(t *testing.T) {
+	service, _, mockStorage, _, imageConfig := setupTestService(t)
+	originals := storage.NewMockS3()
+	service.SetOriginalStorage(originals)
+
+	ctx := context.Background()
+	image, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	assert.True(t, originals.HasObject(image.OriginalKey))
+	assert.False(t, mockStorage.HasObject(image.OriginalKey))
+
+	imageConfig.Types[0].OriginalRetention = domain.RetainOriginalNone
+	image, err = service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, image.OriginalKey)
+	assert.Empty(t, image.OriginalChecksum)
+	assert.Equal(t, 1, originals.GetObjectCount())
+}
+
+// TestCleanupOriginals tests purging originals past their type's retention
+func TestCleanupOriginals(t *testing.T) {
+	service, mockRepo, mockStorage, _, imageConfig := setupTestService(t)
+	imageConfig.Types[0].OriginalRetention = "24h"
+
+	ctx := context.Background()
+	saveWithOriginal := func(typeName string, age time.Duration) *domain.Image {
+		image := &domain.Image{
+			GUID:      uuid.New(),
+			OwnerGUID: uuid.New(),
+			TypeName:  typeName,
+			CreatedAt: time.Now().UTC().Add(-age),
+		}
+		image.OriginalKey = mockStorage.GenerateOriginalKey(typeName, image.OwnerGUID, image.GUID)
+		require.NoError(t, mockStorage.PutPrivate(ctx, image.OriginalKey, createTestImageData(), "image/jpeg"))
+		require.NoError(t, mockRepo.SaveImage(ctx, image))
+		return image
+	}
+	expired := saveWithOriginal("user", 48*time.Hour)
+	recent := saveWithOriginal("user", time.Hour)
+	forever := saveWithOriginal("organization", 48*time.Hour)
+
+	removed, err := service.CleanupOriginals(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	assert.False(t, mockStorage.HasObject(expired.OriginalKey))
+	stored, err := mockRepo.GetImageByID(ctx, expired.GUID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.OriginalKey)
+	assert.Equal(t, expired.Version+1, stored.Version)
+	assert.True(t, mockStorage.HasObject(recent.OriginalKey))
+	assert.True(t, mockStorage.HasObject(forever.OriginalKey))
+
+	// Types that stopped keeping originals drop them all
+	imageConfig.Types[0].OriginalRetention = domain.RetainOriginalNone
+	removed, err = service.CleanupOriginals(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, mockStorage.HasObject(recent.OriginalKey))
+}
+
+// TestReprocessImages_FromOriginal tests that reprocessing starts over from
+// the kept original with the crop it was uploaded with
+func TestReprocessImages_FromOriginal(t *testing.T) {
+	service, mockRepo, mockStorage, mockProcessor, imageConfig := setupTestService(t)
+
+	ctx := context.Background()
+	originalData := append([]byte("original-"), createTestImageData()...)
+	sum := sha256.Sum256(originalData)
+	crop := domain.Crop{X: 0, Y: 0, Width: 200, Height: 200}
+	image := &domain.Image{
+		GUID:             uuid.New(),
+		OwnerGUID:        uuid.New(),
+		TypeName:         "user",
+		OriginalChecksum: hex.EncodeToString(sum[:]),
+		OriginalSize:     int64(len(originalData)),
+		OriginalCrop:     &crop,
+	}
+	image.OriginalKey = mockStorage.GenerateOriginalKey("user", image.OwnerGUID, image.GUID)
+	require.NoError(t, mockStorage.PutPrivate(ctx, image.OriginalKey, originalData, "image/jpeg"))
+	require.NoError(t, mockRepo.SaveImage(ctx, image))
+
+	// No variant is stored, so only the original can be the source
+	report, err := service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Reprocessed)
+	applied, ok := mockProcessor.GetCrop(originalData)
+	require.True(t, ok)
+	assert.Equal(t, crop, applied)
+
+	stored, err := mockRepo.GetImageByID(ctx, image.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, imageConfig.Types[0].SizesHash(), stored.SizesHash)
+	assert.NotEmpty(t, stored.LargeURL)
+
+	// A damaged original is not used
+	require.NoError(t, mockStorage.PutPrivate(ctx, image.OriginalKey, []byte("damaged"), "image/jpeg"))
+	imageConfig.Types[0].Sizes["large"] = domain.Size{Width: 1024, Height: 1024}
+	report, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Failed)
+	assert.ErrorIs(t, report.Images[0].Err, ErrStorageFailed)
+}
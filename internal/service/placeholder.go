@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/placeholder"
+	"github.com/google/uuid"
+)
+
+// GetImageOrPlaceholder retrieves the owner's image like GetImage, falling back
+// to a generated placeholder for types that enable them. The display name, if
+// any, is drawn as initials.
+func (s *ImageService) GetImageOrPlaceholder(ctx context.Context, typeName string, ownerGUID uuid.UUID, displayName string) (*domain.Image, error) {
+	image, err := s.GetImage(ctx, typeName, ownerGUID)
+	if !errors.Is(err, ErrNotFound) {
+		return image, err
+	}
+
+	imageType, typeErr := s.ImageType(typeName)
+	if typeErr != nil || !imageType.Placeholder {
+		return nil, err
+	}
+
+	initials := placeholder.Initials(displayName)
+	return &domain.Image{
+		OwnerGUID:     ownerGUID,
+		TypeName:      typeName,
+		SmallURL:      placeholder.Path(typeName, ownerGUID, "small", initials),
+		MediumURL:     placeholder.Path(typeName, ownerGUID, "medium", initials),
+		LargeURL:      placeholder.Path(typeName, ownerGUID, "large", initials),
+		ContentType:   "image/png",
+		IsPlaceholder: true,
+	}, nil
+}
+
+// RenderPlaceholder draws the owner's placeholder in one of the type's sizes
+// as a PNG. Placeholders are deterministic, so the result can be cached freely.
+func (s *ImageService) RenderPlaceholder(typeName string, ownerGUID uuid.UUID, size, initials string) ([]byte, error) {
+	imageType, err := s.ImageType(typeName)
+	if err != nil {
+		return nil, err
+	}
+	if !imageType.Placeholder {
+		return nil, ErrNotFound
+	}
+	dimensions, exists := imageType.Sizes[size]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s has no size %q", ErrUnknownSize, typeName, size)
+	}
+
+	// Placeholders are square when a size only fixes one dimension
+	width, height := dimensions.Width, dimensions.Height
+	if width <= 0 {
+		width = height
+	}
+	if height <= 0 {
+		height = width
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, placeholder.Generate(ownerGUID, initials, width, height)); err != nil {
+		s.logger.Errorw("Failed to encode placeholder",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
+		return nil, fmt.Errorf("%w: %v", ErrProcessingFailed, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+)
+
+// RenderImage returns a rendition of an image as described by opts, along with
+// its content type. Renditions are cached in storage under a key derived from
+// the canonical options, so each one is only produced once.
+//
+// Renditions are made from the kept original with the crop it was uploaded
+// with, so they are neither enlarged from a smaller variant nor cut to a
+// variant's shape. Images without an original fall back to their largest
+// stored variant.
+func (s *ImageService) RenderImage(ctx context.Context, imageGUID uuid.UUID, opts domain.RenderOptions) ([]byte, string, error) {
+	image, err := s.repo.GetImageByID(ctx, imageGUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get image: %w", err)
+	}
+
+	// Serve a cached rendition if there is one
+	renderKey := s.storage.GenerateRenderKey(image.GUID, renderName(opts, image.OriginalKey != ""))
+	cached, err := s.storage.Get(ctx, renderKey)
+	if err == nil {
+		return cached, opts.ContentType(), nil
+	}
+	if !errors.Is(err, storage.ErrObjectNotFound) {
+		s.logger.Warnw("Failed to read cached rendition",
+			"error", err,
+			"imageGUID", image.GUID,
+			"key", renderKey)
+	}
+
+	source, crop, err := s.renderSource(ctx, image)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rendered, err := s.processor.Render(bytes.NewReader(source), crop, opts)
+	if err != nil {
+		s.logger.Errorw("Failed to render image",
+			"error", err,
+			"imageGUID", image.GUID,
+			"options", opts.String())
+		return nil, "", fmt.Errorf("%w: %v", ErrProcessingFailed, err)
+	}
+
+	if err := s.storage.PutPrivate(ctx, renderKey, rendered, opts.ContentType()); err != nil {
+		// Still serve it; the next request renders again
+		s.logger.Warnw("Failed to cache rendition",
+			"error", err,
+			"imageGUID", image.GUID,
+			"key", renderKey)
+	}
+
+	return rendered, opts.ContentType(), nil
+}
+
+// renderSource returns the data renditions of an image are made from, and
+// the crop to apply to it: the original if one is kept, otherwise the largest
+// variant, which is already cropped
+func (s *ImageService) renderSource(ctx context.Context, image *domain.Image) ([]byte, *domain.Crop, error) {
+	original, err := s.getOriginal(ctx, image)
+	if err == nil {
+		return original, image.OriginalCrop, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		s.logger.Errorw("Failed to get render source original",
+			"error", err,
+			"imageGUID", image.GUID)
+		return nil, nil, err
+	}
+
+	imageType, err := s.ImageType(image.TypeName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source, err := s.storage.Get(ctx, s.imageKey(image.TypeName, image.OwnerGUID, image.GUID, renderSourceSize(imageType)))
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		s.logger.Errorw("Failed to get render source",
+			"error", err,
+			"imageGUID", image.GUID)
+		return nil, nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+	return source, nil, nil
+}
+
+// deleteRenditions removes all cached renditions of an image
+func (s *ImageService) deleteRenditions(ctx context.Context, imageGUID uuid.UUID) error {
+	objects, err := s.storage.List(ctx, s.storage.GenerateRenderKey(imageGUID, ""))
+	if err != nil {
+		return fmt.Errorf("failed to list cached renditions: %w", err)
+	}
+
+	var errs []error
+	for _, object := range objects {
+		if err := s.storage.Delete(ctx, object.Key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			errs = append(errs, fmt.Errorf("failed to delete cached rendition %s: %w", object.Key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// renderName derives the storage name of a rendition from its canonical
+// options and its source, so renditions made from a variant aren't served once
+// an original is available and vice versa
+func renderName(opts domain.RenderOptions, fromOriginal bool) string {
+	source := opts.String()
+	if fromOriginal {
+		source += ",source:original"
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:16]) + "." + opts.Format
+}
+
+// renderSourceSize picks the variant renditions fall back to: the widest
+// configured size, preferring height to break ties
+func renderSourceSize(imageType *domain.ImageType) string {
+	best := ""
+	var bestSize domain.Size
+	for name, size := range imageType.Sizes {
+		if best == "" || size.Width > bestSize.Width ||
+			(size.Width == bestSize.Width && size.Height > bestSize.Height) ||
+			(size.Width == bestSize.Width && size.Height == bestSize.Height && name < best) {
+			best, bestSize = name, size
+		}
+	}
+	return best
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderImage tests rendering, caching and cleanup of renditions
+func TestRenderImage(t *testing.T) {
+	// Set up test service and mocks
+	service, _, mockStorage, mockProcessor, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	image, err := service.UploadImage(ctx, "user", ownerGUID, bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	opts := domain.RenderOptions{Width: 120, Fit: domain.FitContain, Format: domain.FormatPNG, Quality: 85}
+	data, contentType, err := service.RenderImage(ctx, image.GUID, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.NotEmpty(t, data)
+	assert.Equal(t, 1, mockProcessor.GetRenderCount())
+
+	// The second request is served from the cache
+	cached, _, err := service.RenderImage(ctx, image.GUID, opts)
+	require.NoError(t, err)
+	assert.Equal(t, data, cached)
+	assert.Equal(t, 1, mockProcessor.GetRenderCount())
+
+	// Different options render again
+	_, _, err = service.RenderImage(ctx, image.GUID, domain.RenderOptions{Width: 60, Fit: domain.FitContain, Format: domain.FormatJPEG, Quality: 85})
+	require.NoError(t, err)
+	assert.Equal(t, 2, mockProcessor.GetRenderCount())
+
+	// Deleting the image removes its renditions
+	require.NoError(t, service.DeleteImage(ctx, "user", ownerGUID))
+	drainJobs(t, service)
+	renditions, err := mockStorage.List(ctx, mockStorage.GenerateRenderKey(image.GUID, ""))
+	require.NoError(t, err)
+	assert.Empty(t, renditions)
+
+	_, _, err = service.RenderImage(ctx, image.GUID, opts)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestRenderImage_Source tests that renditions are made from the original
+// with its crop, and from the largest variant only when no original is kept
+func TestRenderImage_Source(t *testing.T) {
+	service, mockRepo, mockStorage, mockProcessor, imageConfig := setupTestService(t)
+
+	ctx := context.Background()
+	opts := domain.RenderOptions{Width: 400, Height: 300, Fit: domain.FitContain, Format: domain.FormatJPEG, Quality: 85}
+
+	// No variant is stored, so only the original can be the source
+	originalData := append([]byte("original-"), createTestImageData()...)
+	sum := sha256.Sum256(originalData)
+	crop := domain.Crop{X: 0, Y: 0, Width: 400, Height: 300}
+	image := &domain.Image{
+		GUID:             uuid.New(),
+		OwnerGUID:        uuid.New(),
+		TypeName:         "user",
+		OriginalChecksum: hex.EncodeToString(sum[:]),
+		OriginalSize:     int64(len(originalData)),
+		OriginalCrop:     &crop,
+	}
+	image.OriginalKey = mockStorage.GenerateOriginalKey("user", image.OwnerGUID, image.GUID)
+	require.NoError(t, mockStorage.PutPrivate(ctx, image.OriginalKey, originalData, "image/jpeg"))
+	require.NoError(t, mockRepo.SaveImage(ctx, image))
+
+	_, _, err := service.RenderImage(ctx, image.GUID, opts)
+	require.NoError(t, err)
+	applied, ok := mockProcessor.GetCrop(originalData)
+	require.True(t, ok)
+	assert.Equal(t, crop, applied)
+
+	// A damaged original is not used in its place
+	require.NoError(t, mockStorage.PutPrivate(ctx, image.OriginalKey, []byte("damaged"), "image/jpeg"))
+	_, _, err = service.RenderImage(ctx, image.GUID, domain.RenderOptions{Width: 10, Fit: domain.FitContain, Format: domain.FormatJPEG, Quality: 85})
+	assert.ErrorIs(t, err, ErrStorageFailed)
+
+	// Without an original the largest variant is used
+	imageConfig.Types[0].OriginalRetention = domain.RetainOriginalNone
+	uploaded, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	require.Empty(t, uploaded.OriginalKey)
+	_, _, err = service.RenderImage(ctx, uploaded.GUID, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, mockProcessor.GetRenderCount())
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/antonrybalko/image-service-go/internal/repository"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Reprocessing settings
+const (
+	// jobKindReprocessImages is the queue job kind that reprocesses one batch
+	// of a type's images and queues the next
+	jobKindReprocessImages = "images.reprocess"
+
+	// ReprocessBatchSize is how many images are listed at a time, and examined
+	// by each queued reprocessing job
+	ReprocessBatchSize = 100
+
+	// DefaultReprocessInterval is the default pause after each reprocessed image
+	DefaultReprocessInterval = 100 * time.Millisecond
+
+	// reprocessAttempts bounds how often saving an image's new variants is
+	// retried when the image changes concurrently
+	reprocessAttempts = 3
+)
+
+// ReprocessOptions selects the images ReprocessImages works through
+type ReprocessOptions struct {
+	TypeName string
+	Cursor   string        // NextCursor of an earlier run to resume it; empty to start a new run
+	Limit    int           // Images to examine, 0 means all from Cursor on
+	DryRun   bool          // Only report what would change
+	Interval time.Duration // Pause after each reprocessed image, to bound the load on storage
+}
+
+// ReprocessReport describes a reprocessing run
+type ReprocessReport struct {
+	TypeName    string
+	SizesHash   string // Hash of the type's current sizes
+	DryRun      bool
+	Scanned     int                // Images examined
+	Stale       int                // Images generated with other sizes than the current ones
+	Reprocessed int                // Stale images regenerated; always 0 on a dry run
+	Failed      int                // Stale images that could not be regenerated
+	NextCursor  string             // Cursor to resume from
+	Done        bool               // Whether the last of the type's images was examined
+	Images      []ReprocessedImage // The stale images, in listing order
+}
+
+// ReprocessedImage describes a stale image found by a reprocessing run
+type ReprocessedImage struct {
+	ImageGUID uuid.UUID
+	OwnerGUID uuid.UUID
+	Added     []string // Sizes the type defines that the image has no variant of
+	Removed   []string // Sizes the image has a variant of that the type no longer defines
+	Err       error    // Why regenerating failed; nil if it succeeded and on a dry run
+}
+
+// reprocessPayload is the queue job payload of jobKindReprocessImages
+type reprocessPayload struct {
+	RunID    uuid.UUID     `json:"runId"`
+	TypeName string        `json:"typeName"`
+	Cursor   string        `json:"cursor,omitempty"`
+	Interval time.Duration `json:"interval"`
+}
+
+// SetReprocessInterval sets the pause after each image reprocessed by queued
+// runs
+func (s *ImageService) SetReprocessInterval(interval time.Duration) {
+	s.reprocessInterval = interval
+}
+
+// ReprocessImages regenerates the variants of a type's images that were
+// generated with other size definitions than the type's current ones.
+// Images are examined in ListImages order, most recently updated first, and
+// those already up to date are skipped, so an interrupted run can be resumed
+// from its NextCursor or simply started over. A run only examines the images
+// last updated before it started; the cursor carries that bound, so resuming
+// keeps it. Images uploaded or regenerated since are up to date anyway, and
+// as the listing is keyed on the last image examined, images added or
+// removed meanwhile don't make a resumed run skip any.
+//
+// A failure to regenerate one image is recorded in the report and the run
+// goes on. If listing fails or ctx is done, the report so far is returned
+// along with the error.
+func (s *ImageService) ReprocessImages(ctx context.Context, opts ReprocessOptions) (*ReprocessReport, error) {
+	imageType, err := s.ImageType(opts.TypeName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ImageFilter{
+		TypeName:  opts.TypeName,
+		UpdatedTo: time.Now().UTC(),
+	}
+	if opts.Cursor != "" {
+		filter.UpdatedTo, filter.After, err = decodeReprocessCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &ReprocessReport{
+		TypeName:   opts.TypeName,
+		SizesHash:  imageType.SizesHash(),
+		DryRun:     opts.DryRun,
+		NextCursor: encodeReprocessCursor(filter.UpdatedTo, filter.After),
+	}
+
+	for opts.Limit <= 0 || report.Scanned < opts.Limit {
+		batchSize := ReprocessBatchSize
+		if opts.Limit > 0 {
+			batchSize = min(batchSize, opts.Limit-report.Scanned)
+		}
+
+		images, err := s.repo.ListImages(ctx, filter, batchSize)
+		if err != nil {
+			s.logger.Errorw("Failed to list images for reprocessing",
+				"error", err,
+				"typeName", opts.TypeName,
+				"cursor", report.NextCursor)
+			return report, fmt.Errorf("failed to list images: %w", err)
+		}
+
+		for _, image := range images {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+
+			report.Scanned++
+			filter.After = repository.CursorAfter(image)
+			report.NextCursor = encodeReprocessCursor(filter.UpdatedTo, filter.After)
+			if image.SizesHash == report.SizesHash {
+				continue
+			}
+
+			report.Stale++
+			result := ReprocessedImage{
+				ImageGUID: image.GUID,
+				OwnerGUID: image.OwnerGUID,
+			}
+			result.Added, result.Removed = sizeChanges(image, imageType)
+
+			if !opts.DryRun {
+				result.Err = s.reprocessImage(ctx, image, imageType, result.Removed)
+				if result.Err != nil {
+					s.logger.Warnw("Failed to reprocess image",
+						"error", result.Err,
+						"typeName", image.TypeName,
+						"ownerGUID", image.OwnerGUID,
+						"imageGUID", image.GUID)
+					report.Failed++
+				} else {
+					report.Reprocessed++
+				}
+			}
+			report.Images = append(report.Images, result)
+
+			if !opts.DryRun && opts.Interval > 0 {
+				select {
+				case <-ctx.Done():
+					return report, ctx.Err()
+				case <-time.After(opts.Interval):
+				}
+			}
+		}
+
+		if len(images) < batchSize {
+			report.Done = true
+			break
+		}
+	}
+
+	return report, nil
+}
+
+// StartReprocessing queues a run reprocessing every image of a type and
+// returns its ID, which appears in the workers' logs. Queue workers take the
+// run one batch at a time at low priority, each batch queueing the next, so
+// a run survives restarts and doesn't hold back other work.
+func (s *ImageService) StartReprocessing(ctx context.Context, typeName string) (uuid.UUID, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return uuid.Nil, err
+	}
+
+	payload := reprocessPayload{
+		RunID:    uuid.New(),
+		TypeName: typeName,
+		Interval: s.reprocessInterval,
+	}
+	if err := s.queueReprocessing(ctx, payload); err != nil {
+		s.logger.Errorw("Failed to queue reprocessing",
+			"error", err,
+			"typeName", typeName)
+		return uuid.Nil, err
+	}
+
+	return payload.RunID, nil
+}
+
+// queueReprocessing queues the reprocessing batch described by payload
+func (s *ImageService) queueReprocessing(ctx context.Context, payload reprocessPayload) error {
+	queued, err := queue.NewJob(jobKindReprocessImages, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create reprocessing job: %w", err)
+	}
+	queued.Priority = queue.PriorityLow
+
+	if err := s.jobQueue.Enqueue(ctx, queued); err != nil {
+		return fmt.Errorf("failed to queue reprocessing job: %w", err)
+	}
+	return nil
+}
+
+// handleReprocessImages reprocesses the batch named by a queue job and
+// queues the next one. A failed attempt redoes the whole batch, which only
+// costs a listing for the images already regenerated.
+func (s *ImageService) handleReprocessImages(ctx context.Context, queued *queue.Job) error {
+	var payload reprocessPayload
+	if err := queued.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+
+	report, err := s.ReprocessImages(ctx, ReprocessOptions{
+		TypeName: payload.TypeName,
+		Cursor:   payload.Cursor,
+		Limit:    ReprocessBatchSize,
+		Interval: payload.Interval,
+	})
+	if err != nil {
+		if errors.Is(err, ErrUnknownType) {
+			// The type was removed from the configuration since
+			return queue.Permanent(err)
+		}
+		return err
+	}
+
+	s.logger.Infow("Reprocessed images",
+		"runID", payload.RunID,
+		"typeName", payload.TypeName,
+		"cursor", payload.Cursor,
+		"scanned", report.Scanned,
+		"stale", report.Stale,
+		"reprocessed", report.Reprocessed,
+		"failed", report.Failed)
+
+	if report.Done {
+		s.logger.Infow("Reprocessing finished",
+			"runID", payload.RunID,
+			"typeName", payload.TypeName)
+		return nil
+	}
+
+	payload.Cursor = report.NextCursor
+	return s.queueReprocessing(ctx, payload)
+}
+
+// encodeReprocessCursor serializes the position of a reprocessing run into
+// an opaque URL-safe token: the bound on the images the run examines and the
+// last image examined, nil before the first
+func encodeReprocessCursor(updatedTo time.Time, after *repository.ImageCursor) string {
+	raw := updatedTo.UTC().Format(time.RFC3339Nano)
+	if after != nil {
+		raw += "|" + encodeCursor(after)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeReprocessCursor parses a token produced by encodeReprocessCursor
+func decodeReprocessCursor(token string) (time.Time, *repository.ImageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalidCursor
+	}
+
+	bound, position, found := strings.Cut(string(raw), "|")
+	updatedTo, err := time.Parse(time.RFC3339Nano, bound)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalidCursor
+	}
+	if !found {
+		return updatedTo, nil, nil
+	}
+
+	after, err := decodeCursor(position)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return updatedTo, after, nil
+}
+
+// reprocessImage regenerates an image's variants with the type's current
+// sizes, overwriting the stored ones, and records them in its metadata.
+// They are made from the kept original, cropped as on upload, or from the
+// largest variant when no original is kept. Variants of the removed sizes
+// are deleted once they are no longer referenced.
+func (s *ImageService) reprocessImage(ctx context.Context, image *domain.Image, imageType *domain.ImageType, removed []string) error {
+	var variants map[string][]byte
+	original, err := s.getOriginal(ctx, image)
+	switch {
+	case err == nil && image.OriginalCrop != nil:
+		variants, err = s.processor.ProcessCroppedImage(bytes.NewReader(original), imageType, *image.OriginalCrop)
+	case err == nil:
+		variants, err = s.processor.ProcessImage(bytes.NewReader(original), imageType)
+	case errors.Is(err, ErrNotFound):
+		var source []byte
+		source, err = s.reprocessSource(ctx, image)
+		if err != nil {
+			return err
+		}
+		variants, err = s.processor.ProcessImage(bytes.NewReader(source), imageType)
+	default:
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProcessingFailed, err)
+	}
+
+	urls := make(map[string]string, len(variants))
+	for size, variantData := range variants {
+		url, err := s.storage.Put(ctx, s.imageKey(image.TypeName, image.OwnerGUID, image.GUID, size), variantData, "image/jpeg")
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrStorageFailed, err)
+		}
+		urls[size] = url
+	}
+
+	current := image
+	for attempt := 1; ; attempt++ {
+		updated := *current
+		for _, size := range domain.VariantSizes {
+			setVariantURL(&updated, size, urls[size])
+		}
+		updated.SizesHash = imageType.SizesHash()
+
+		err := s.repo.ReplaceImage(ctx, current, &updated)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrConflict) || attempt == reprocessAttempts {
+			return fmt.Errorf("failed to save image metadata: %w", err)
+		}
+
+		// The image changed meanwhile; record the variants in its latest metadata
+		current, err = s.repo.GetImageByID(ctx, image.GUID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Deleted or replaced meanwhile, so the variants just written are orphaned
+			s.deleteImageFiles(ctx, image)
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get image: %w", err)
+		}
+	}
+
+	// Cached renditions were made from the old variants, so they go along
+	// with the variants of removed sizes
+	payload := deleteFilesPayload{ImageGUID: image.GUID}
+	for _, size := range removed {
+		payload.Keys = append(payload.Keys, s.imageKey(image.TypeName, image.OwnerGUID, image.GUID, size))
+	}
+	s.queueFileDeletion(ctx, payload)
+	return nil
+}
+
+// reprocessSource returns the largest stored variant of an image, the best
+// source there is once its original is gone; sizes that grew past it are
+// scaled up from it.
+func (s *ImageService) reprocessSource(ctx context.Context, image *domain.Image) ([]byte, error) {
+	var source []byte
+	sourceArea := 0
+	for _, size := range domain.VariantSizes {
+		if variantURL(image, size) == "" {
+			continue
+		}
+
+		data, err := s.storage.Get(ctx, s.imageKey(image.TypeName, image.OwnerGUID, image.GUID, size))
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+		}
+
+		width, height, err := s.processor.GetImageDimensions(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		// Ties go to the later, nominally larger size
+		if source == nil || width*height >= sourceArea {
+			source, sourceArea = data, width*height
+		}
+	}
+
+	if source == nil {
+		return nil, fmt.Errorf("%w: no stored variant of image %s to reprocess from", ErrNotFound, image.GUID)
+	}
+	return source, nil
+}
+
+// sizeChanges compares the variants an image has with the sizes its type
+// defines, returning the sizes it lacks and those it has but shouldn't
+func sizeChanges(image *domain.Image, imageType *domain.ImageType) (added, removed []string) {
+	for _, size := range domain.VariantSizes {
+		_, defined := imageType.Sizes[size]
+		stored := variantURL(image, size) != ""
+		switch {
+		case defined && !stored:
+			added = append(added, size)
+		case stored && !defined:
+			removed = append(removed, size)
+		}
+	}
+	return added, removed
+}
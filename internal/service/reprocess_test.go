@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReprocessImages tests regenerating variants after a type's sizes change
+func TestReprocessImages(t *testing.T) {
+	service, mockRepo, mockStorage, _, imageConfig := setupTestService(t)
+	// Without originals, variants are regenerated from the largest one stored
+	imageConfig.Types[0].OriginalRetention = domain.RetainOriginalNone
+
+	ctx := context.Background()
+	var images []*domain.Image
+	for i := 0; i < 3; i++ {
+		image, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+		require.NoError(t, err)
+		images = append(images, image)
+	}
+
+	// Freshly uploaded images are up to date
+	report, err := service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Scanned)
+	assert.Equal(t, 0, report.Stale)
+	assert.True(t, report.Done)
+
+	// Drop the small size and grow the large one
+	imageConfig.Types[0].Sizes = domain.SizeSet{
+		"medium": {Width: 100, Height: 100},
+		"large":  {Width: 1024, Height: 1024},
+	}
+	smallKey := mockStorage.GenerateImageKey("user", images[0].OwnerGUID, images[0].GUID, "small")
+	largeKey := mockStorage.GenerateImageKey("user", images[0].OwnerGUID, images[0].GUID, "large")
+	oldLarge, err := mockStorage.Get(ctx, largeKey)
+	require.NoError(t, err)
+
+	// A dry run reports the stale images a page at a time and changes nothing
+	report, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user", DryRun: true, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Scanned)
+	assert.Equal(t, 2, report.Stale)
+	assert.Equal(t, 0, report.Reprocessed)
+	assert.NotEmpty(t, report.NextCursor)
+	assert.False(t, report.Done)
+	require.Len(t, report.Images, 2)
+	assert.Equal(t, []string{"small"}, report.Images[0].Removed)
+	assert.Empty(t, report.Images[0].Added)
+
+	// Resuming picks up after the last image examined, missing none even
+	// though an image was uploaded meanwhile, which is up to date anyway
+	listed := []uuid.UUID{report.Images[0].ImageGUID, report.Images[1].ImageGUID}
+	_, err = service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	report, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user", DryRun: true, Cursor: report.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Scanned)
+	assert.True(t, report.Done)
+	require.Len(t, report.Images, 1)
+	assert.NotContains(t, listed, report.Images[0].ImageGUID)
+
+	assert.True(t, mockStorage.HasObject(smallKey))
+	stored, err := mockRepo.GetImageByID(ctx, images[0].GUID)
+	require.NoError(t, err)
+	assert.Equal(t, images[0].SizesHash, stored.SizesHash)
+
+	// A real run regenerates the variants in place
+	report, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Stale)
+	assert.Equal(t, 3, report.Reprocessed)
+	assert.Equal(t, 0, report.Failed)
+
+	stored, err = mockRepo.GetImageByID(ctx, images[0].GUID)
+	require.NoError(t, err)
+	assert.Equal(t, imageConfig.Types[0].SizesHash(), stored.SizesHash)
+	assert.Empty(t, stored.SmallURL)
+	assert.Equal(t, images[0].LargeURL, stored.LargeURL)
+	drainJobs(t, service)
+	assert.False(t, mockStorage.HasObject(smallKey))
+	newLarge, err := mockStorage.Get(ctx, largeKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldLarge, newLarge)
+
+	// Running again finds nothing to do
+	report, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Scanned)
+	assert.Equal(t, 0, report.Stale)
+
+	_, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "unknown"})
+	assert.True(t, errors.Is(err, ErrUnknownType))
+}
+
+// TestReprocessImages_Failed tests that a failed image is reported and left
+// stale without stopping the run
+func TestReprocessImages_Failed(t *testing.T) {
+	service, mockRepo, mockStorage, mockProcessor, imageConfig := setupTestService(t)
+	imageConfig.Types[0].OriginalRetention = domain.RetainOriginalNone
+
+	ctx := context.Background()
+	failing, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+	missing, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	imageConfig.Types[0].Sizes["large"] = domain.Size{Width: 1024, Height: 1024}
+
+	// One image has lost its variants, so there is nothing to regenerate it from
+	for _, size := range domain.VariantSizes {
+		require.NoError(t, mockStorage.Delete(ctx, mockStorage.GenerateImageKey("user", missing.OwnerGUID, missing.GUID, size)))
+	}
+	report, err := service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Stale)
+	assert.Equal(t, 1, report.Reprocessed)
+	assert.Equal(t, 1, report.Failed)
+	for _, image := range report.Images {
+		if image.ImageGUID == missing.GUID {
+			assert.True(t, errors.Is(image.Err, ErrNotFound))
+		}
+	}
+
+	imageConfig.Types[0].Sizes["large"] = domain.Size{Width: 900, Height: 900}
+	mockProcessor.SetShouldFailProcessing(true)
+	report, err = service.ReprocessImages(ctx, ReprocessOptions{TypeName: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Failed)
+
+	stored, err := mockRepo.GetImageByID(ctx, failing.GUID)
+	require.NoError(t, err)
+	assert.NotEqual(t, imageConfig.Types[0].SizesHash(), stored.SizesHash)
+}
+
+// TestStartReprocessing tests that a queued run works through all batches
+func TestStartReprocessing(t *testing.T) {
+	service, mockRepo, _, _, imageConfig := setupTestService(t)
+
+	ctx := context.Background()
+	stale, err := service.UploadImage(ctx, "user", uuid.New(), bytes.NewReader(createTestImageData()), domain.UploadOptions{})
+	require.NoError(t, err)
+
+	imageConfig.Types[0].Sizes["large"] = domain.Size{Width: 1024, Height: 1024}
+	sizesHash := imageConfig.Types[0].SizesHash()
+
+	// Fill the first batch with newer images that are up to date, so the
+	// stale image is only reached by the second
+	for i := 0; i < ReprocessBatchSize; i++ {
+		require.NoError(t, mockRepo.SaveImage(ctx, &domain.Image{
+			GUID:      uuid.New(),
+			OwnerGUID: uuid.New(),
+			TypeName:  "user",
+			SizesHash: sizesHash,
+		}))
+	}
+
+	_, err = service.StartReprocessing(ctx, "unknown")
+	assert.True(t, errors.Is(err, ErrUnknownType))
+
+	_, err = service.StartReprocessing(ctx, "user")
+	require.NoError(t, err)
+	// Two batches, and the deletion of the stale image's old renditions
+	assert.Equal(t, 3, drainJobs(t, service))
+
+	stored, err := mockRepo.GetImageByID(ctx, stale.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, sizesHash, stored.SizesHash)
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/resumable"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+)
+
+// resumableUploadTTL is how long a client has to complete a resumable upload
+const resumableUploadTTL = 24 * time.Hour
+
+// SetResumableStore sets the store keeping the state of resumable uploads
+func (s *ImageService) SetResumableStore(store resumable.Store) {
+	s.resumable = store
+}
+
+// CreateResumableUpload starts a chunked upload of length bytes for an image of
+// the given type. The caller is responsible for checking that userID may write
+// images for ownerGUID; only userID can append to or terminate the upload.
+func (s *ImageService) CreateResumableUpload(ctx context.Context, userID, typeName string, ownerGUID uuid.UUID, length int64, altText string) (*domain.ResumableUpload, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return nil, err
+	}
+
+	if length <= 0 {
+		return nil, invalidField("Upload-Length", "upload length must be positive")
+	}
+	if length > s.MaxImageSizeFor(typeName) {
+		return nil, ErrImageTooLarge
+	}
+	if utf8.RuneCountInString(altText) > domain.MaxAltTextLength {
+		return nil, invalidField("altText", "alt text exceeds %d characters", domain.MaxAltTextLength)
+	}
+
+	now := time.Now().UTC()
+	upload := &domain.ResumableUpload{
+		GUID:      uuid.New(),
+		UserID:    userID,
+		TypeName:  typeName,
+		OwnerGUID: ownerGUID,
+		Length:    length,
+		Chunks:    []string{},
+		AltText:   altText,
+		CreatedAt: now,
+		ExpiresAt: now.Add(resumableUploadTTL),
+	}
+
+	if err := s.resumable.Create(ctx, upload); err != nil {
+		s.logger.Errorw("Failed to store resumable upload state",
+			"error", err,
+			"uploadGUID", upload.GUID)
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	return upload, nil
+}
+
+// GetResumableUpload returns the state of a resumable upload owned by userID.
+// Uploads of other users are reported as not found.
+func (s *ImageService) GetResumableUpload(ctx context.Context, userID string, uploadGUID uuid.UUID) (*domain.ResumableUpload, error) {
+	upload, err := s.resumable.Get(ctx, uploadGUID)
+	if err != nil {
+		if errors.Is(err, resumable.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	if upload.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if time.Now().UTC().After(upload.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+
+	return upload, nil
+}
+
+// AppendResumableUpload appends the bytes read from body to the upload, which
+// must currently be at offset. Once the last byte arrives the assembled file is
+// processed through UploadImage and the resulting image is returned alongside
+// the updated upload state; until then the returned image is nil. Of two
+// concurrent appends at the same offset only one is kept; the other fails
+// with ErrOffsetMismatch.
+func (s *ImageService) AppendResumableUpload(ctx context.Context, userID string, uploadGUID uuid.UUID, offset int64, body io.Reader) (*domain.ResumableUpload, *domain.Image, error) {
+	upload, err := s.GetResumableUpload(ctx, userID, uploadGUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if upload.IsComplete() || offset != upload.Offset {
+		return nil, nil, fmt.Errorf("%w: upload is at offset %d", ErrOffsetMismatch, upload.Offset)
+	}
+
+	// Never accept more than the declared length
+	remaining := upload.Length - upload.Offset
+	chunk, err := io.ReadAll(io.LimitReader(body, remaining+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if int64(len(chunk)) > remaining {
+		return nil, nil, fmt.Errorf("%w: chunk exceeds the declared upload length", ErrImageTooLarge)
+	}
+	if len(chunk) == 0 {
+		return upload, nil, nil
+	}
+
+	// Every append stores its chunk under a name of its own, so a concurrent
+	// append at the same offset can't overwrite it
+	chunkName := resumableChunkName(upload.Offset)
+	chunkKey := s.storage.GenerateResumableKey(upload.GUID, chunkName)
+	if err := s.storage.PutPrivate(ctx, chunkKey, chunk, "application/octet-stream"); err != nil {
+		s.logger.Errorw("Failed to store resumable upload chunk",
+			"error", err,
+			"uploadGUID", upload.GUID,
+			"offset", upload.Offset)
+		return nil, nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	upload.Chunks = append(upload.Chunks, chunkName)
+	upload.Offset += int64(len(chunk))
+	if err := s.updateResumableUpload(ctx, upload); err != nil {
+		s.deleteResumableChunk(ctx, upload.GUID, chunkName)
+		return nil, nil, err
+	}
+
+	var image *domain.Image
+	if upload.IsComplete() {
+		image, err = s.completeResumableUpload(ctx, upload)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return upload, image, nil
+}
+
+// TerminateResumableUpload deletes a resumable upload owned by userID and all its data
+func (s *ImageService) TerminateResumableUpload(ctx context.Context, userID string, uploadGUID uuid.UUID) error {
+	if _, err := s.GetResumableUpload(ctx, userID, uploadGUID); err != nil && !errors.Is(err, ErrUploadExpired) {
+		return err
+	}
+
+	return s.deleteResumableUpload(ctx, uploadGUID)
+}
+
+// CleanupResumableUploads deletes resumable uploads past their expiry and
+// returns how many were removed
+func (s *ImageService) CleanupResumableUploads(ctx context.Context) (int, error) {
+	expired, err := s.resumable.ListExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	removed := 0
+	for _, uploadGUID := range expired {
+		if err := s.deleteResumableUpload(ctx, uploadGUID); err != nil {
+			s.logger.Warnw("Failed to delete expired resumable upload",
+				"error", err,
+				"uploadGUID", uploadGUID)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// completeResumableUpload assembles the chunks of a complete upload, processes
+// the image and drops the chunk data. The upload state is kept until it expires
+// so that clients can still query the final offset.
+func (s *ImageService) completeResumableUpload(ctx context.Context, upload *domain.ResumableUpload) (*domain.Image, error) {
+	imageData := make([]byte, 0, upload.Length)
+	for _, chunkName := range upload.Chunks {
+		chunk, err := s.storage.Get(ctx, s.storage.GenerateResumableKey(upload.GUID, chunkName))
+		if err != nil {
+			s.logger.Errorw("Failed to read resumable upload chunk",
+				"error", err,
+				"uploadGUID", upload.GUID,
+				"chunk", chunkName)
+			s.reopenResumableUpload(ctx, upload)
+			return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+		}
+		imageData = append(imageData, chunk...)
+	}
+
+	image, err := s.UploadImage(ctx, upload.TypeName, upload.OwnerGUID, bytes.NewReader(imageData), domain.UploadOptions{AltText: upload.AltText})
+	if err != nil {
+		// A retry of the last chunk can succeed after a transient failure, but
+		// data that is not a valid image never will, so drop the upload entirely
+		if !isRejectedImageError(err) {
+			s.reopenResumableUpload(ctx, upload)
+			return nil, err
+		}
+		if cleanupErr := s.deleteResumableUpload(ctx, upload.GUID); cleanupErr != nil {
+			s.logger.Warnw("Failed to delete rejected resumable upload",
+				"error", cleanupErr,
+				"uploadGUID", upload.GUID)
+		}
+		return nil, err
+	}
+
+	upload.ImageGUID = &image.GUID
+	if err := s.updateResumableUpload(ctx, upload); err != nil {
+		// The image is stored; only the state reported to the client is stale
+		s.logger.Warnw("Failed to record the image of a resumable upload",
+			"error", err,
+			"uploadGUID", upload.GUID,
+			"imageGUID", image.GUID)
+	}
+	if err := s.deleteResumableObjects(ctx, upload.GUID); err != nil {
+		// The cleanup job removes it once the upload expires
+		s.logger.Warnw("Failed to delete resumable upload chunks",
+			"error", err,
+			"uploadGUID", upload.GUID)
+	}
+
+	return image, nil
+}
+
+// reopenResumableUpload takes back the last chunk of a complete upload that
+// failed to process, so the client can retry it
+func (s *ImageService) reopenResumableUpload(ctx context.Context, upload *domain.ResumableUpload) {
+	last := len(upload.Chunks) - 1
+	chunkName := upload.Chunks[last]
+	offset, err := resumableChunkOffset(chunkName)
+	if err == nil {
+		upload.Chunks = upload.Chunks[:last]
+		upload.Offset = offset
+		err = s.updateResumableUpload(ctx, upload)
+	}
+	if err != nil {
+		// Clients can start over once the upload expires
+		s.logger.Warnw("Failed to reopen resumable upload",
+			"error", err,
+			"uploadGUID", upload.GUID)
+		return
+	}
+	s.deleteResumableChunk(ctx, upload.GUID, chunkName)
+}
+
+// updateResumableUpload persists the upload state if no other append changed
+// it since it was read
+func (s *ImageService) updateResumableUpload(ctx context.Context, upload *domain.ResumableUpload) error {
+	if err := s.resumable.Update(ctx, upload); err != nil {
+		switch {
+		case errors.Is(err, resumable.ErrConflict):
+			return fmt.Errorf("%w: upload was appended to concurrently", ErrOffsetMismatch)
+		case errors.Is(err, resumable.ErrNotFound):
+			return ErrNotFound
+		}
+		s.logger.Errorw("Failed to store resumable upload state",
+			"error", err,
+			"uploadGUID", upload.GUID)
+		return fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	return nil
+}
+
+// deleteResumableUpload deletes the stored objects and the state of an upload
+func (s *ImageService) deleteResumableUpload(ctx context.Context, uploadGUID uuid.UUID) error {
+	if err := s.deleteResumableObjects(ctx, uploadGUID); err != nil {
+		return err
+	}
+	if err := s.resumable.Delete(ctx, uploadGUID); err != nil {
+		return fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	return nil
+}
+
+// deleteResumableObjects deletes the stored chunks of an upload
+func (s *ImageService) deleteResumableObjects(ctx context.Context, uploadGUID uuid.UUID) error {
+	objects, err := s.storage.List(ctx, s.storage.GenerateResumableKey(uploadGUID, ""))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	for _, object := range objects {
+		if err := s.storage.Delete(ctx, object.Key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			return fmt.Errorf("%w: %v", ErrStorageFailed, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteResumableChunk deletes a chunk that is not part of the upload state
+func (s *ImageService) deleteResumableChunk(ctx context.Context, uploadGUID uuid.UUID, chunkName string) {
+	if err := s.storage.Delete(ctx, s.storage.GenerateResumableKey(uploadGUID, chunkName)); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		// The chunks of an upload are all deleted along with it
+		s.logger.Warnw("Failed to delete resumable upload chunk",
+			"error", err,
+			"uploadGUID", uploadGUID,
+			"chunk", chunkName)
+	}
+}
+
+// resumableChunkName names a new chunk starting at offset; zero padding keeps
+// listings in order and the random suffix tells concurrent appends apart
+func resumableChunkName(offset int64) string {
+	return fmt.Sprintf("chunk-%020d-%s", offset, uuid.NewString())
+}
+
+// resumableChunkOffset returns the start offset of a chunk named by resumableChunkName
+func resumableChunkOffset(chunkName string) (int64, error) {
+	digits, _, _ := strings.Cut(strings.TrimPrefix(chunkName, "chunk-"), "-")
+	offset, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed chunk name %q: %w", chunkName, err)
+	}
+	return offset, nil
+}
+
+// isRejectedImageError reports whether err means the uploaded data itself was rejected
+func isRejectedImageError(err error) bool {
+	for _, rejected := range []error{ErrInvalidImage, ErrImageTooLarge, ErrUnsupportedType, ErrProcessingFailed, ErrInvalidOptions} {
+		if errors.Is(err, rejected) {
+			return true
+		}
+	}
+	return false
+}
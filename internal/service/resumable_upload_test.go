@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/resumable"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResumableUpload tests creating, appending to and completing a chunked upload
+func TestResumableUpload(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	imageData := createTestImageData()
+
+	upload, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, int64(len(imageData)), "Resumed")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), upload.Offset)
+	assert.True(t, upload.ExpiresAt.After(time.Now()))
+
+	// Only the creating user can see the upload
+	_, err = service.GetResumableUpload(ctx, uuid.New().String(), upload.GUID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// Append the first half
+	half := int64(len(imageData) / 2)
+	upload, image, err := service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader(imageData[:half]))
+	require.NoError(t, err)
+	assert.Nil(t, image)
+	assert.Equal(t, half, upload.Offset)
+
+	// The state is read back from storage, as after a restart
+	upload, err = service.GetResumableUpload(ctx, userID.String(), upload.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, half, upload.Offset)
+
+	// A chunk at the wrong offset is rejected
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader(imageData[:half]))
+	assert.True(t, errors.Is(err, ErrOffsetMismatch))
+
+	// More data than declared is rejected
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, half, bytes.NewReader(append(imageData[half:], 0)))
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+
+	// Append the rest, completing the upload
+	upload, image, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, half, bytes.NewReader(imageData[half:]))
+	require.NoError(t, err)
+	require.NotNil(t, image)
+	assert.True(t, upload.IsComplete())
+	assert.Equal(t, image.GUID, *upload.ImageGUID)
+	assert.Equal(t, "Resumed", image.AltText)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+
+	// Chunks are dropped once processed; only the state remains
+	objects, err := mockStorage.List(ctx, mockStorage.GenerateResumableKey(upload.GUID, ""))
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+	upload, err = service.GetResumableUpload(ctx, userID.String(), upload.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, image.GUID, *upload.ImageGUID)
+
+	// Terminating removes the upload
+	require.NoError(t, service.TerminateResumableUpload(ctx, userID.String(), upload.GUID))
+	_, err = service.GetResumableUpload(ctx, userID.String(), upload.GUID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestCreateResumableUpload_Validation tests the checks done when an upload is created
+func TestCreateResumableUpload_Validation(t *testing.T) {
+	// Set up test service and mocks
+	service, _, _, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, 0, "")
+	assert.True(t, errors.Is(err, ErrInvalidOptions))
+
+	_, err = service.CreateResumableUpload(ctx, userID.String(), "user", userID, service.MaxImageSize()+1, "")
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+
+	_, err = service.CreateResumableUpload(ctx, userID.String(), "banner", userID, 10, "")
+	assert.True(t, errors.Is(err, ErrUnknownType))
+}
+
+// TestResumableUpload_InvalidImage tests that an upload of non-image data is dropped
+func TestResumableUpload_InvalidImage(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, mockStorage, mockProcessor, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	data := []byte("definitely not an image")
+	mockProcessor.SetDetectedFormat(data, "image/gif")
+
+	upload, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, int64(len(data)), "")
+	require.NoError(t, err)
+
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader(data))
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+
+	objects, err := mockStorage.List(ctx, mockStorage.GenerateResumableKey(upload.GUID, ""))
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+// TestCleanupResumableUploads tests removal of expired resumable uploads
+func TestCleanupResumableUploads(t *testing.T) {
+	// Set up test service and mocks
+	service, _, mockStorage, _, _ := setupTestService(t)
+	store := resumable.NewMemoryStore()
+	service.SetResumableStore(store)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	fresh, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, 10, "")
+	require.NoError(t, err)
+
+	expired, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, 10, "")
+	require.NoError(t, err)
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), expired.GUID, 0, bytes.NewReader([]byte("12345")))
+	require.NoError(t, err)
+
+	// Backdate the second upload's expiry
+	expired, err = store.Get(ctx, expired.GUID)
+	require.NoError(t, err)
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+	require.NoError(t, store.Create(ctx, expired))
+
+	_, err = service.GetResumableUpload(ctx, userID.String(), expired.GUID)
+	assert.True(t, errors.Is(err, ErrUploadExpired))
+
+	removed, err := service.CleanupResumableUploads(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	objects, err := mockStorage.List(ctx, mockStorage.GenerateResumableKey(expired.GUID, ""))
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	_, err = store.Get(ctx, expired.GUID)
+	assert.ErrorIs(t, err, resumable.ErrNotFound)
+
+	_, err = service.GetResumableUpload(ctx, userID.String(), fresh.GUID)
+	assert.NoError(t, err)
+}
+
+// staleResumableStore returns the state an upload had when it was frozen, as
+// seen by an append that read it before a concurrent one finished
+type staleResumableStore struct {
+	*resumable.MemoryStore
+	frozen *domain.ResumableUpload
+}
+
+func (s *staleResumableStore) Get(ctx context.Context, uploadGUID uuid.UUID) (*domain.ResumableUpload, error) {
+	if s.frozen != nil {
+		upload := *s.frozen
+		upload.Chunks = append([]string{}, s.frozen.Chunks...)
+		return &upload, nil
+	}
+	return s.MemoryStore.Get(ctx, uploadGUID)
+}
+
+// TestResumableUpload_ConcurrentAppend tests that of two appends at the same
+// offset only the first is kept
+func TestResumableUpload_ConcurrentAppend(t *testing.T) {
+	service, _, mockStorage, _, _ := setupTestService(t)
+	store := &staleResumableStore{MemoryStore: resumable.NewMemoryStore()}
+	service.SetResumableStore(store)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	upload, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, 10, "")
+	require.NoError(t, err)
+	store.frozen, err = store.MemoryStore.Get(ctx, upload.GUID)
+	require.NoError(t, err)
+
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader([]byte("12345")))
+	require.NoError(t, err)
+
+	// The second append read the state from before the first one
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader([]byte("abc")))
+	assert.ErrorIs(t, err, ErrOffsetMismatch)
+
+	store.frozen = nil
+	upload, err = service.GetResumableUpload(ctx, userID.String(), upload.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), upload.Offset)
+	require.Len(t, upload.Chunks, 1)
+
+	// Only the first append's chunk is stored
+	objects, err := mockStorage.List(ctx, mockStorage.GenerateResumableKey(upload.GUID, ""))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, mockStorage.GenerateResumableKey(upload.GUID, upload.Chunks[0]), objects[0].Key)
+}
+
+// flakyStorage fails the next failGets reads and failDeletes deletions
+type flakyStorage struct {
+	*storage.MockS3
+	failGets    int
+	failDeletes int
+}
+
+func (f *flakyStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	if f.failGets > 0 {
+		f.failGets--
+		return nil, errors.New("connection reset")
+	}
+	return f.MockS3.Get(ctx, key)
+}
+
+func (f *flakyStorage) Delete(ctx context.Context, key string) error {
+	if f.failDeletes > 0 {
+		f.failDeletes--
+		return errors.New("connection reset")
+	}
+	return f.MockS3.Delete(ctx, key)
+}
+
+// TestResumableUpload_RetryAfterFailure tests that the last chunk can be sent
+// again when processing the complete upload failed for a transient reason
+func TestResumableUpload_RetryAfterFailure(t *testing.T) {
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	imageData := createTestImageData()
+	upload, err := service.CreateResumableUpload(ctx, userID.String(), "user", userID, int64(len(imageData)), "")
+	require.NoError(t, err)
+
+	// Reading the chunks back fails once
+	service.storage = &flakyStorage{MockS3: mockStorage, failGets: 1}
+	_, _, err = service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader(imageData))
+	assert.ErrorIs(t, err, ErrStorageFailed)
+
+	upload, err = service.GetResumableUpload(ctx, userID.String(), upload.GUID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), upload.Offset)
+	assert.Empty(t, upload.Chunks)
+	assert.Equal(t, 0, mockStorage.GetObjectCount())
+
+	_, image, err := service.AppendResumableUpload(ctx, userID.String(), upload.GUID, 0, bytes.NewReader(imageData))
+	require.NoError(t, err)
+	require.NotNil(t, image)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+}
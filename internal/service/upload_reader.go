@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// uploadReader reads an uploaded image while enforcing the size limit. It
+// remembers why reading stopped, so the cause survives decoders that replace
+// or swallow read errors.
+type uploadReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+// newUploadReader wraps r so that reading more than maxBytes fails with ErrImageTooLarge
+func newUploadReader(r io.Reader, maxBytes int64) *uploadReader {
+	return &uploadReader{r: r, remaining: maxBytes}
+}
+
+// Read implements io.Reader
+func (u *uploadReader) Read(p []byte) (int, error) {
+	if u.err != nil {
+		return 0, u.err
+	}
+	if u.r == nil {
+		return 0, io.EOF
+	}
+
+	// Ask for one byte more than allowed to detect oversized data
+	if int64(len(p)) > u.remaining+1 {
+		p = p[:u.remaining+1]
+	}
+
+	n, err := u.r.Read(p)
+	if int64(n) > u.remaining {
+		u.err = fmt.Errorf("%w: exceeds %d bytes", ErrImageTooLarge, u.remaining)
+		return int(u.remaining), u.err
+	}
+	u.remaining -= int64(n)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		u.err = fmt.Errorf("%w: failed to read image data: %v", ErrInvalidImage, err)
+		return n, u.err
+	}
+	return n, err
+}
+
+// Err returns the size or read error that stopped reading, if any
+func (u *uploadReader) Err() error {
+	return u.err
+}
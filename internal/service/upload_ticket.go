@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Direct upload settings
+const (
+	// uploadTicketTTL is how long a presigned upload URL stays valid
+	uploadTicketTTL = 15 * time.Minute
+
+	// stagingRetention is how long an unfinalized staging object is kept.
+	// It allows a client that uploaded just before the ticket expired to finalize.
+	stagingRetention = 2 * uploadTicketTTL
+
+	// stagingPrefix is the key prefix shared by all staging objects
+	stagingPrefix = "staging/"
+)
+
+// allowedUploadContentTypes are the content types accepted for direct uploads
+var allowedUploadContentTypes = []string{"image/jpeg", "image/png"}
+
+// CreateUploadTicket issues a presigned request that lets a client upload an
+// image of the given type straight to storage. The object lands under a staging
+// key and must be finalized with FinalizeUpload before it becomes an image.
+// PUT tickets are signed for the declared content type and exact size, which
+// must be within the type's limit; POST forms carry the limit in their policy.
+func (s *ImageService) CreateUploadTicket(ctx context.Context, typeName string, ownerGUID uuid.UUID, method, contentType string, size int64) (*domain.UploadTicket, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return nil, err
+	}
+
+	uploadGUID := uuid.New()
+	key := s.storage.GenerateStagingKey(typeName, ownerGUID, uploadGUID)
+
+	var (
+		request *storage.PresignedRequest
+		err     error
+	)
+	switch strings.ToUpper(method) {
+	case "", domain.UploadMethodPut:
+		if !isAllowedUploadContentType(contentType) {
+			return nil, invalidField("contentType", "contentType must be one of %s", strings.Join(allowedUploadContentTypes, ", "))
+		}
+		if size <= 0 || size > s.MaxImageSizeFor(typeName) {
+			return nil, invalidField("size", "size must be between 1 and %d bytes", s.MaxImageSizeFor(typeName))
+		}
+		request, err = s.storage.PresignPut(ctx, key, contentType, size, uploadTicketTTL)
+	case domain.UploadMethodPost:
+		request, err = s.storage.PresignPost(ctx, key, s.MaxImageSizeFor(typeName), uploadTicketTTL)
+	default:
+		return nil, invalidField("method", "method must be PUT or POST")
+	}
+	if err != nil {
+		s.logger.Errorw("Failed to presign upload",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID)
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	return &domain.UploadTicket{
+		UploadGUID:   uploadGUID,
+		TypeName:     typeName,
+		OwnerGUID:    ownerGUID,
+		Key:          key,
+		Method:       request.Method,
+		URL:          request.URL,
+		Headers:      request.Headers,
+		Fields:       request.Fields,
+		MaxBytes:     s.MaxImageSizeFor(typeName),
+		ContentTypes: allowedUploadContentTypes,
+		ExpiresAt:    request.ExpiresAt,
+	}, nil
+}
+
+// FinalizeUpload streams a directly uploaded staging object through the
+// regular upload pipeline, and removes the staging object. An object over the
+// type's size limit is deleted without being read.
+func (s *ImageService) FinalizeUpload(ctx context.Context, typeName string, ownerGUID, uploadGUID uuid.UUID, opts domain.UploadOptions) (*domain.Image, error) {
+	if _, err := s.ImageType(typeName); err != nil {
+		return nil, err
+	}
+
+	key := s.storage.GenerateStagingKey(typeName, ownerGUID, uploadGUID)
+	info, err := s.storage.Stat(ctx, key)
+	if err == nil && info.Size > s.MaxImageSizeFor(typeName) {
+		s.deleteStagingObject(ctx, key)
+		return nil, fmt.Errorf("%w: staging object has %d bytes", ErrImageTooLarge, info.Size)
+	}
+	var imageData io.ReadCloser
+	if err == nil {
+		imageData, err = s.storage.GetStream(ctx, key, 0)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrNotFound
+		}
+		s.logger.Errorw("Failed to get staging object",
+			"error", err,
+			"typeName", typeName,
+			"ownerGUID", ownerGUID,
+			"uploadGUID", uploadGUID)
+		return nil, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	// The size limit still applies while reading, should the object have been
+	// replaced since it was examined
+	image, err := s.UploadImage(ctx, typeName, ownerGUID, imageData, opts)
+	_ = imageData.Close()
+	if err != nil {
+		if errors.Is(err, ErrImageTooLarge) {
+			s.deleteStagingObject(ctx, key)
+		}
+		return nil, err
+	}
+
+	s.deleteStagingObject(ctx, key)
+	return image, nil
+}
+
+// deleteStagingObject deletes a staging object that is done with
+func (s *ImageService) deleteStagingObject(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		// The cleanup job removes it later
+		s.logger.Warnw("Failed to delete staging object",
+			"error", err,
+			"key", key)
+	}
+}
+
+// CleanupStagingUploads deletes staging objects older than the retention period
+// and returns how many were removed
+func (s *ImageService) CleanupStagingUploads(ctx context.Context) (int, error) {
+	objects, err := s.storage.List(ctx, stagingPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+	}
+
+	cutoff := time.Now().UTC().Add(-stagingRetention)
+	removed := 0
+	for _, object := range objects {
+		if object.LastModified.After(cutoff) {
+			continue
+		}
+		if err := s.storage.Delete(ctx, object.Key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			s.logger.Warnw("Failed to delete expired staging object",
+				"error", err,
+				"key", object.Key)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// RunStagingCleanup calls CleanupStagingUploads, CleanupResumableUploads,
+// CleanupJobs and CleanupOriginals every interval until ctx is done. A stage
+// that fails is logged and the next one still runs.
+func (s *ImageService) RunStagingCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.CleanupStagingUploads(ctx)
+			if err != nil {
+				s.logger.Errorw("Failed to clean up staging uploads", "error", err)
+			} else if removed > 0 {
+				s.logger.Infow("Removed expired staging uploads", "count", removed)
+			}
+
+			removed, err = s.CleanupResumableUploads(ctx)
+			if err != nil {
+				s.logger.Errorw("Failed to clean up resumable uploads", "error", err)
+			} else if removed > 0 {
+				s.logger.Infow("Removed expired resumable uploads", "count", removed)
+			}
+
+			if err := s.CleanupJobs(ctx); err != nil {
+				s.logger.Errorw("Failed to clean up jobs", "error", err)
+			}
+
+			removed, err = s.CleanupOriginals(ctx)
+			if err != nil {
+				s.logger.Errorw("Failed to clean up originals", "error", err)
+			} else if removed > 0 {
+				s.logger.Infow("Removed originals past retention", "count", removed)
+			}
+		}
+	}
+}
+
+// isAllowedUploadContentType reports whether contentType may be used for a direct upload
+func isAllowedUploadContentType(contentType string) bool {
+	for _, allowed := range allowedUploadContentTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
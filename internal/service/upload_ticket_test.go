@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateUploadTicket tests issuing presigned PUT and POST tickets
+func TestCreateUploadTicket(t *testing.T) {
+	// Set up test service and mocks
+	service, _, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	ticket, err := service.CreateUploadTicket(ctx, "product", ownerGUID, "", "image/png", 2048)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UploadMethodPut, ticket.Method)
+	assert.Equal(t, mockStorage.GenerateStagingKey("product", ownerGUID, ticket.UploadGUID), ticket.Key)
+	assert.Equal(t, "image/png", ticket.Headers["Content-Type"])
+	assert.Equal(t, "2048", ticket.Headers["Content-Length"])
+	assert.Equal(t, service.MaxImageSize(), ticket.MaxBytes)
+	assert.True(t, ticket.ExpiresAt.After(time.Now()))
+
+	ticket, err = service.CreateUploadTicket(ctx, "product", ownerGUID, "post", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UploadMethodPost, ticket.Method)
+	assert.Equal(t, ticket.Key, ticket.Fields["key"])
+
+	// PUT tickets are signed for a specific allowed content type and size
+	_, err = service.CreateUploadTicket(ctx, "product", ownerGUID, "PUT", "image/gif", 2048)
+	assert.True(t, errors.Is(err, ErrInvalidOptions))
+
+	for _, size := range []int64{0, service.MaxImageSize() + 1} {
+		_, err = service.CreateUploadTicket(ctx, "product", ownerGUID, "PUT", "image/png", size)
+		var fieldErr *FieldError
+		require.True(t, errors.As(err, &fieldErr), size)
+		assert.Equal(t, "size", fieldErr.Field)
+	}
+
+	_, err = service.CreateUploadTicket(ctx, "product", ownerGUID, "PATCH", "image/png", 2048)
+	assert.True(t, errors.Is(err, ErrInvalidOptions))
+
+	_, err = service.CreateUploadTicket(ctx, "banner", ownerGUID, "PUT", "image/png", 2048)
+	assert.True(t, errors.Is(err, ErrUnknownType))
+}
+
+// TestFinalizeUpload tests turning a staging object into an image
+func TestFinalizeUpload(t *testing.T) {
+	// Set up test service and mocks
+	service, mockRepo, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	ticket, err := service.CreateUploadTicket(ctx, "user", ownerGUID, "PUT", "image/jpeg", int64(len(createTestImageData())))
+	require.NoError(t, err)
+
+	// Finalizing before the client uploaded anything is not found
+	_, err = service.FinalizeUpload(ctx, "user", ownerGUID, ticket.UploadGUID, domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// Simulate the client's direct upload
+	_, err = mockStorage.Put(ctx, ticket.Key, createTestImageData(), "image/jpeg")
+	require.NoError(t, err)
+
+	image, err := service.FinalizeUpload(ctx, "user", ownerGUID, ticket.UploadGUID, domain.UploadOptions{AltText: "Direct"})
+	require.NoError(t, err)
+	assert.Equal(t, ownerGUID, image.OwnerGUID)
+	assert.Equal(t, "Direct", image.AltText)
+	assert.Equal(t, 1, mockRepo.GetImageCount())
+	assert.True(t, mockStorage.HasObject(mockStorage.GenerateImageKey("user", ownerGUID, image.GUID, "large")))
+	assert.False(t, mockStorage.HasObject(ticket.Key))
+
+	// A ticket cannot be finalized twice
+	_, err = service.FinalizeUpload(ctx, "user", ownerGUID, ticket.UploadGUID, domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestFinalizeUpload_TooLarge tests that an oversized staging object is
+// rejected and deleted without being read
+func TestFinalizeUpload_TooLarge(t *testing.T) {
+	service, mockRepo, mockStorage, mockProcessor, _ := setupTestService(t)
+	service.SetMaxImageSize(16)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+	uploadGUID := uuid.New()
+	key := mockStorage.GenerateStagingKey("user", ownerGUID, uploadGUID)
+	_, err := mockStorage.Put(ctx, key, createTestImageData(), "image/jpeg")
+	require.NoError(t, err)
+
+	_, err = service.FinalizeUpload(ctx, "user", ownerGUID, uploadGUID, domain.UploadOptions{})
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+	assert.False(t, mockStorage.HasObject(key))
+	assert.Equal(t, 0, mockProcessor.GetProcessedImageCount())
+	assert.Equal(t, 0, mockRepo.GetImageCount())
+}
+
+// TestCleanupStagingUploads tests removal of expired, unfinalized uploads
+func TestCleanupStagingUploads(t *testing.T) {
+	// Set up test service and mocks
+	service, _, mockStorage, _, _ := setupTestService(t)
+
+	ctx := context.Background()
+	ownerGUID := uuid.New()
+
+	expiredKey := mockStorage.GenerateStagingKey("user", ownerGUID, uuid.New())
+	freshKey := mockStorage.GenerateStagingKey("user", ownerGUID, uuid.New())
+	imageKey := mockStorage.GenerateImageKey("user", ownerGUID, uuid.New(), "small")
+	for _, key := range []string{expiredKey, freshKey, imageKey} {
+		_, err := mockStorage.Put(ctx, key, createTestImageData(), "image/jpeg")
+		require.NoError(t, err)
+	}
+	mockStorage.SetLastModified(expiredKey, time.Now().Add(-stagingRetention-time.Minute))
+	mockStorage.SetLastModified(imageKey, time.Now().Add(-24*time.Hour))
+
+	removed, err := service.CleanupStagingUploads(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, mockStorage.HasObject(expiredKey))
+	assert.True(t, mockStorage.HasObject(freshKey))
+	assert.True(t, mockStorage.HasObject(imageKey))
+}
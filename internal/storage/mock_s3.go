@@ -1,9 +1,17 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,6 +21,7 @@ type MockS3 struct {
 	objects     map[string][]byte
 	contentType map[string]string
 	urls        map[string]string
+	modified    map[string]time.Time
 	bucket      string
 	region      string
 	cdnBaseURL  string
@@ -25,6 +34,7 @@ func NewMockS3() *MockS3 {
 		objects:     make(map[string][]byte),
 		contentType: make(map[string]string),
 		urls:        make(map[string]string),
+		modified:    make(map[string]time.Time),
 		bucket:      "test-bucket",
 		region:      "us-east-1",
 		cdnBaseURL:  "https://cdn.example.com",
@@ -35,55 +45,179 @@ func NewMockS3() *MockS3 {
 func (m *MockS3) Put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	// Store the object in memory
 	m.objects[key] = body
 	m.contentType[key] = contentType
-	
+	m.modified[key] = time.Now().UTC()
+
 	// Generate and store URL
 	url := m.GetURL(key)
 	m.urls[key] = url
-	
+
 	return url, nil
 }
 
+// PutPrivate mocks uploading a private object
+func (m *MockS3) PutPrivate(ctx context.Context, key string, body []byte, contentType string) error {
+	_, err := m.Put(ctx, key, body, contentType)
+	return err
+}
+
 // Get mocks retrieving an object from S3
 func (m *MockS3) Get(ctx context.Context, key string) ([]byte, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
+
 	data, exists := m.objects[key]
 	if !exists {
-		return nil, fmt.Errorf("object not found: %s", key)
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
 	}
-	
+
 	return data, nil
 }
 
+// GetStream mocks opening an object for reading from offset
+func (m *MockS3) GetStream(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	data, err := m.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if offset > int64(len(data)) {
+		return nil, fmt.Errorf("offset %d is beyond the end of %s", offset, key)
+	}
+
+	return io.NopCloser(bytes.NewReader(data[offset:])), nil
+}
+
+// Stat mocks reading an object's metadata
+func (m *MockS3) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	data, exists := m.objects[key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+
+	// Like S3, the ETag of a single-part upload is the quoted MD5 of the content
+	sum := md5.Sum(data)
+	return &ObjectInfo{
+		Key:          key,
+		Size:         int64(len(data)),
+		LastModified: m.modified[key],
+		ContentType:  m.contentType[key],
+		ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+	}, nil
+}
+
 // Delete mocks removing an object from S3
 func (m *MockS3) Delete(ctx context.Context, key string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	if _, exists := m.objects[key]; !exists {
-		return fmt.Errorf("object not found: %s", key)
+		return fmt.Errorf("%w: %s", ErrObjectNotFound, key)
 	}
-	
+
 	delete(m.objects, key)
 	delete(m.contentType, key)
 	delete(m.urls, key)
-	
+	delete(m.modified, key)
+
 	return nil
 }
 
+// List mocks listing the objects whose keys start with prefix
+func (m *MockS3) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var objects []ObjectInfo
+	for key, data := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{
+				Key:          key,
+				Size:         int64(len(data)),
+				LastModified: m.modified[key],
+			})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	return objects, nil
+}
+
+// PresignPut mocks presigning a PUT upload
+func (m *MockS3) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error) {
+	return &PresignedRequest{
+		Method: "PUT",
+		URL:    fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s?X-Amz-Signature=mock", m.bucket, m.region, key),
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": strconv.FormatInt(size, 10),
+		},
+		ExpiresAt: time.Now().UTC().Add(expires),
+	}, nil
+}
+
+// PresignPost mocks presigning a POST form upload
+func (m *MockS3) PresignPost(ctx context.Context, key string, maxBytes int64, expires time.Duration) (*PresignedRequest, error) {
+	return &PresignedRequest{
+		Method: "POST",
+		URL:    fmt.Sprintf("https://%s.s3.%s.amazonaws.com", m.bucket, m.region),
+		Fields: map[string]string{
+			"key":             key,
+			"policy":          "mock-policy",
+			"x-amz-signature": "mock",
+		},
+		ExpiresAt: time.Now().UTC().Add(expires),
+	}, nil
+}
+
+// GenerateStagingKey generates the key a client uploads to before an upload is finalized
+func (m *MockS3) GenerateStagingKey(typeName string, ownerGUID uuid.UUID, uploadGUID uuid.UUID) string {
+	return fmt.Sprintf("staging/%s/%s/%s", typeName, ownerGUID.String(), uploadGUID.String())
+}
+
+// GenerateRenderKey generates the key a cached rendition of an image is stored under
+func (m *MockS3) GenerateRenderKey(imageGUID uuid.UUID, name string) string {
+	return fmt.Sprintf("renders/%s/%s", imageGUID.String(), name)
+}
+
+// GenerateJobKey generates the key the original of a background upload is kept under until it is processed
+func (m *MockS3) GenerateJobKey(jobGUID uuid.UUID) string {
+	return fmt.Sprintf("jobs/%s", jobGUID.String())
+}
+
+// GenerateOriginalKey generates the key the uploaded original of an image is kept under
+func (m *MockS3) GenerateOriginalKey(typeName string, ownerGUID uuid.UUID, imageGUID uuid.UUID) string {
+	return fmt.Sprintf("originals/%s/%s/%s", typeName, ownerGUID.String(), imageGUID.String())
+}
+
+// GenerateResumableKey generates the key of one object belonging to a resumable upload
+func (m *MockS3) GenerateResumableKey(uploadGUID uuid.UUID, name string) string {
+	return fmt.Sprintf("resumable/%s/%s", uploadGUID.String(), name)
+}
+
+// GenerateImageKey generates a consistent key for an image variant of any type
+func (m *MockS3) GenerateImageKey(typeName string, ownerGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
+	return fmt.Sprintf("images/%s/%s/%s/%s.jpg", typeName, ownerGUID.String(), imageGUID.String(), size)
+}
+
 // GenerateUserImageKey generates a consistent key for user images
 func (m *MockS3) GenerateUserImageKey(userGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
-	return fmt.Sprintf("images/user/%s/%s/%s.jpg", userGUID.String(), imageGUID.String(), size)
+	return m.GenerateImageKey("user", userGUID, imageGUID, size)
 }
 
 // GenerateOrganizationImageKey generates a consistent key for organization images
 func (m *MockS3) GenerateOrganizationImageKey(orgGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
-	return fmt.Sprintf("images/organization/%s/%s/%s.jpg", orgGUID.String(), imageGUID.String(), size)
+	return m.GenerateImageKey("organization", orgGUID, imageGUID, size)
+}
+
+// GenerateProductImageKey generates a consistent key for product images
+func (m *MockS3) GenerateProductImageKey(productGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
+	return m.GenerateImageKey("product", productGUID, imageGUID, size)
 }
 
 // GetURL returns the URL for an object
@@ -100,7 +234,7 @@ func (m *MockS3) GetURL(key string) string {
 func (m *MockS3) HasObject(key string) bool {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
+
 	_, exists := m.objects[key]
 	return exists
 }
@@ -109,7 +243,7 @@ func (m *MockS3) HasObject(key string) bool {
 func (m *MockS3) GetObjectCount() int {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
+
 	return len(m.objects)
 }
 
@@ -117,17 +251,25 @@ func (m *MockS3) GetObjectCount() int {
 func (m *MockS3) ClearObjects() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	m.objects = make(map[string][]byte)
 	m.contentType = make(map[string]string)
 	m.urls = make(map[string]string)
+	m.modified = make(map[string]time.Time)
+}
+
+// SetLastModified overrides the modification time of an object
+func (m *MockS3) SetLastModified(key string, modified time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.modified[key] = modified
 }
 
 // GetContentType returns the content type for a key
 func (m *MockS3) GetContentType(key string) (string, bool) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
+
 	ct, exists := m.contentType[key]
 	return ct, exists
 }
@@ -136,7 +278,7 @@ func (m *MockS3) GetContentType(key string) (string, bool) {
 func (m *MockS3) GetStoredURL(key string) (string, bool) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
+
 	url, exists := m.urls[key]
 	return url, exists
 }
@@ -145,7 +287,7 @@ func (m *MockS3) GetStoredURL(key string) (string, bool) {
 func (m *MockS3) SetBucket(bucket string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	m.bucket = bucket
 }
 
@@ -153,7 +295,7 @@ func (m *MockS3) SetBucket(bucket string) {
 func (m *MockS3) SetRegion(region string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	m.region = region
 }
 
@@ -161,7 +303,7 @@ func (m *MockS3) SetRegion(region string) {
 func (m *MockS3) SetCDNBaseURL(url string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	m.cdnBaseURL = url
 }
 
@@ -169,11 +311,11 @@ func (m *MockS3) SetCDNBaseURL(url string) {
 func (m *MockS3) GetAllKeys() []string {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
+
 	keys := make([]string, 0, len(m.objects))
 	for k := range m.objects {
 		keys = append(keys, k)
 	}
-	
+
 	return keys
 }
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ObjectReader reads a stored object as an io.ReadSeeker without buffering
+// it. The object is only opened on the first Read after a Seek, from the new
+// position to the end of the object, as a reader isn't told where a range
+// ends. Serving a byte range therefore skips the bytes before it, and closing
+// the reader once the range is copied stops the transfer of the rest.
+type ObjectReader struct {
+	ctx     context.Context
+	storage S3Interface
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+// NewObjectReader returns a reader over the object described by info
+func NewObjectReader(ctx context.Context, storage S3Interface, info *ObjectInfo) *ObjectReader {
+	return &ObjectReader{
+		ctx:     ctx,
+		storage: storage,
+		key:     info.Key,
+		size:    info.Size,
+	}
+}
+
+// Read reads from the current position, opening the object if needed
+func (o *ObjectReader) Read(p []byte) (int, error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+
+	if o.body == nil {
+		body, err := o.storage.GetStream(o.ctx, o.key, o.offset)
+		if err != nil {
+			return 0, err
+		}
+		o.body = body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+// Seek moves the position for the next Read
+func (o *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = o.offset + offset
+	case io.SeekEnd:
+		position = o.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if position < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	// Reopen on the next Read unless the position is unchanged
+	if position != o.offset {
+		if err := o.Close(); err != nil {
+			return 0, err
+		}
+		o.offset = position
+	}
+
+	return position, nil
+}
+
+// Close releases the open object, if any
+func (o *ObjectReader) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
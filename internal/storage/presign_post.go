@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signature V4 constants for browser-based POST uploads
+const (
+	postAlgorithm  = "AWS4-HMAC-SHA256"
+	postDateFormat = "20060102T150405Z"
+	postDayFormat  = "20060102"
+)
+
+// PresignPost returns a presigned POST form for uploading one image object of at most maxBytes.
+// The policy pins the key, limits the body size and requires an image/* Content-Type field.
+// The AWS SDK version in use has no POST presigner, so the policy is signed here.
+func (s *S3Client) PresignPost(ctx context.Context, key string, maxBytes int64, expires time.Duration) (*PresignedRequest, error) {
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve S3 credentials: %w", err)
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(expires)
+	credential := fmt.Sprintf("%s/%s/%s/s3/aws4_request", creds.AccessKeyID, now.Format(postDayFormat), s.region)
+
+	fields := map[string]string{
+		"key":              key,
+		"x-amz-algorithm":  postAlgorithm,
+		"x-amz-credential": credential,
+		"x-amz-date":       now.Format(postDateFormat),
+	}
+	if creds.SessionToken != "" {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+
+	conditions := []interface{}{
+		map[string]string{"bucket": s.bucket},
+		[]interface{}{"content-length-range", 1, maxBytes},
+		[]string{"starts-with", "$Content-Type", "image/"},
+	}
+	for name, value := range fields {
+		conditions = append(conditions, map[string]string{name: value})
+	}
+
+	policy, err := json.Marshal(map[string]interface{}{
+		"expiration": expiresAt.Format(time.RFC3339),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode upload policy: %w", err)
+	}
+
+	encodedPolicy := base64.StdEncoding.EncodeToString(policy)
+	fields["policy"] = encodedPolicy
+	fields["x-amz-signature"] = hex.EncodeToString(
+		hmacSHA256(postSigningKey(creds.SecretAccessKey, now, s.region), encodedPolicy))
+
+	return &PresignedRequest{
+		Method:    "POST",
+		URL:       s.bucketURL(),
+		Fields:    fields,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// bucketURL returns the URL that browser-based POST uploads are sent to
+func (s *S3Client) bucketURL() string {
+	if s.endpoint != "" {
+		return fmt.Sprintf("%s/%s", strings.TrimRight(s.endpoint, "/"), s.bucket)
+	}
+	if s.usePathStyle {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s", s.region, s.bucket)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", s.bucket, s.region)
+}
+
+// postSigningKey derives the Signature V4 signing key for S3 on the given day
+func postSigningKey(secret string, date time.Time, region string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date.Format(postDayFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	return hmacSHA256(key, "aws4_request")
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -3,10 +3,12 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -27,33 +29,98 @@ type S3Config struct {
 	UsePathStyle    bool   // Use path-style addressing (for MinIO)
 }
 
+// ErrObjectNotFound is returned when a requested object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// PresignedRequest describes a request a client can send directly to storage
+type PresignedRequest struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"` // Headers the client must send with a PUT
+	Fields    map[string]string `json:"fields,omitempty"`  // Form fields to send before the file in a POST
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ContentType  string // Only set by Stat
+	ETag         string // Quoted strong entity tag
+}
+
 // S3Interface defines the operations for S3 storage
 type S3Interface interface {
 	// Put uploads an object to S3 and returns the public URL
 	Put(ctx context.Context, key string, body []byte, contentType string) (string, error)
 
+	// PutPrivate uploads an object that is not publicly readable, e.g. partial upload state
+	PutPrivate(ctx context.Context, key string, body []byte, contentType string) error
+
 	// Get retrieves an object from S3
 	Get(ctx context.Context, key string) ([]byte, error)
 
+	// GetStream opens an object for reading from offset to its end; the caller closes it
+	GetStream(ctx context.Context, key string, offset int64) (io.ReadCloser, error)
+
+	// Stat returns an object's metadata without reading it
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
 	// Delete removes an object from S3
 	Delete(ctx context.Context, key string) error
 
+	// List returns the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
+	// PresignPut returns a presigned PUT request for uploading one object with the
+	// given content type and exactly size bytes
+	PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error)
+
+	// PresignPost returns a presigned POST form for uploading one image object of at most maxBytes
+	PresignPost(ctx context.Context, key string, maxBytes int64, expires time.Duration) (*PresignedRequest, error)
+
+	// GenerateStagingKey generates the key a client uploads to before an upload is finalized
+	GenerateStagingKey(typeName string, ownerGUID uuid.UUID, uploadGUID uuid.UUID) string
+
+	// GenerateResumableKey generates the key of one object belonging to a resumable upload
+	GenerateResumableKey(uploadGUID uuid.UUID, name string) string
+
+	// GenerateJobKey generates the key the original of a background upload is kept under until it is processed
+	GenerateJobKey(jobGUID uuid.UUID) string
+
+	// GenerateOriginalKey generates the key the uploaded original of an image is kept under
+	GenerateOriginalKey(typeName string, ownerGUID uuid.UUID, imageGUID uuid.UUID) string
+
+	// GenerateRenderKey generates the key a cached rendition of an image is stored under
+	GenerateRenderKey(imageGUID uuid.UUID, name string) string
+
+	// GenerateImageKey generates a consistent key for an image variant of any type
+	GenerateImageKey(typeName string, ownerGUID uuid.UUID, imageGUID uuid.UUID, size string) string
+
 	// GenerateUserImageKey generates a consistent key for user images
 	GenerateUserImageKey(userGUID uuid.UUID, imageGUID uuid.UUID, size string) string
 
 	// GenerateOrganizationImageKey generates a consistent key for organization images
 	GenerateOrganizationImageKey(orgGUID uuid.UUID, imageGUID uuid.UUID, size string) string
 
+	// GenerateProductImageKey generates a consistent key for product images
+	GenerateProductImageKey(productGUID uuid.UUID, imageGUID uuid.UUID, size string) string
+
 	// GetURL returns the URL for an object
 	GetURL(key string) string
 }
 
 // S3Client implements S3Interface using AWS SDK
 type S3Client struct {
-	client     *s3.Client
-	bucket     string
-	region     string
-	cdnBaseURL string
+	client       *s3.Client
+	presigner    *s3.PresignClient
+	credentials  aws.CredentialsProvider
+	bucket       string
+	region       string
+	endpoint     string
+	usePathStyle bool
+	cdnBaseURL   string
 }
 
 // NewS3Client creates a new S3 client
@@ -99,10 +166,14 @@ func NewS3Client(cfg S3Config) (S3Interface, error) {
 	})
 
 	return &S3Client{
-		client:     s3Client,
-		bucket:     cfg.Bucket,
-		region:     cfg.Region,
-		cdnBaseURL: cfg.CDNBaseURL,
+		client:       s3Client,
+		presigner:    s3.NewPresignClient(s3Client),
+		credentials:  awsCfg.Credentials,
+		bucket:       cfg.Bucket,
+		region:       cfg.Region,
+		endpoint:     cfg.Endpoint,
+		usePathStyle: cfg.UsePathStyle,
+		cdnBaseURL:   cfg.CDNBaseURL,
 	}, nil
 }
 
@@ -122,6 +193,22 @@ func (s *S3Client) Put(ctx context.Context, key string, body []byte, contentType
 	return s.GetURL(key), nil
 }
 
+// PutPrivate uploads an object that is not publicly readable
+func (s *S3Client) PutPrivate(ctx context.Context, key string, body []byte, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+		ACL:         types.ObjectCannedACLPrivate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload object to S3: %w", err)
+	}
+
+	return nil
+}
+
 // Get retrieves an object from S3
 func (s *S3Client) Get(ctx context.Context, key string) ([]byte, error) {
 	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
@@ -129,6 +216,10 @@ func (s *S3Client) Get(ctx context.Context, key string) ([]byte, error) {
 		Key:    aws.String(key),
 	})
 	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
 		return nil, fmt.Errorf("failed to get object from S3: %w", err)
 	}
 	defer func() {
@@ -141,6 +232,53 @@ func (s *S3Client) Get(ctx context.Context, key string) ([]byte, error) {
 	return io.ReadAll(result.Body)
 }
 
+// GetStream opens an object for reading from offset to its end. The range is
+// open-ended; callers that need less close the body early.
+func (s *S3Client) GetStream(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to get object from S3: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+// Stat returns an object's metadata without reading it
+func (s *S3Client) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		// HEAD responses have no body, so a missing key surfaces as NotFound
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to stat object in S3: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(result.ContentLength),
+		LastModified: aws.ToTime(result.LastModified),
+		ContentType:  aws.ToString(result.ContentType),
+		ETag:         aws.ToString(result.ETag),
+	}, nil
+}
+
 // Delete removes an object from S3
 func (s *S3Client) Delete(ctx context.Context, key string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -154,14 +292,105 @@ func (s *S3Client) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// List returns the objects whose keys start with prefix
+func (s *S3Client) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in S3: %w", err)
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+				ETag:         aws.ToString(object.ETag),
+			})
+		}
+	}
+
+	return objects, nil
+}
+
+// PresignPut returns a presigned PUT request for uploading one object with the
+// given content type and exactly size bytes. Content-Length is a signed header,
+// so S3 refuses a body of any other length.
+func (s *S3Client) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error) {
+	request, err := s.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign S3 upload: %w", err)
+	}
+
+	headers := make(map[string]string)
+	for name := range request.SignedHeader {
+		// Host is set by the client's HTTP stack
+		if !strings.EqualFold(name, "Host") {
+			headers[name] = request.SignedHeader.Get(name)
+		}
+	}
+
+	return &PresignedRequest{
+		Method:    request.Method,
+		URL:       request.URL,
+		Headers:   headers,
+		ExpiresAt: time.Now().UTC().Add(expires),
+	}, nil
+}
+
+// GenerateStagingKey generates the key a client uploads to before an upload is finalized
+func (s *S3Client) GenerateStagingKey(typeName string, ownerGUID uuid.UUID, uploadGUID uuid.UUID) string {
+	return fmt.Sprintf("staging/%s/%s/%s", typeName, ownerGUID.String(), uploadGUID.String())
+}
+
+// GenerateRenderKey generates the key a cached rendition of an image is stored under
+func (s *S3Client) GenerateRenderKey(imageGUID uuid.UUID, name string) string {
+	return fmt.Sprintf("renders/%s/%s", imageGUID.String(), name)
+}
+
+// GenerateJobKey generates the key the original of a background upload is kept under until it is processed
+func (s *S3Client) GenerateJobKey(jobGUID uuid.UUID) string {
+	return fmt.Sprintf("jobs/%s", jobGUID.String())
+}
+
+// GenerateOriginalKey generates the key the uploaded original of an image is kept under
+func (s *S3Client) GenerateOriginalKey(typeName string, ownerGUID uuid.UUID, imageGUID uuid.UUID) string {
+	return fmt.Sprintf("originals/%s/%s/%s", typeName, ownerGUID.String(), imageGUID.String())
+}
+
+// GenerateResumableKey generates the key of one object belonging to a resumable upload
+func (s *S3Client) GenerateResumableKey(uploadGUID uuid.UUID, name string) string {
+	return fmt.Sprintf("resumable/%s/%s", uploadGUID.String(), name)
+}
+
+// GenerateImageKey generates a consistent key for an image variant of any type
+func (s *S3Client) GenerateImageKey(typeName string, ownerGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
+	return fmt.Sprintf("images/%s/%s/%s/%s.jpg", typeName, ownerGUID.String(), imageGUID.String(), size)
+}
+
 // GenerateUserImageKey generates a consistent key for user images
 func (s *S3Client) GenerateUserImageKey(userGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
-	return fmt.Sprintf("images/user/%s/%s/%s.jpg", userGUID.String(), imageGUID.String(), size)
+	return s.GenerateImageKey("user", userGUID, imageGUID, size)
 }
 
 // GenerateOrganizationImageKey generates a consistent key for organization images
 func (s *S3Client) GenerateOrganizationImageKey(orgGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
-	return fmt.Sprintf("images/organization/%s/%s/%s.jpg", orgGUID.String(), imageGUID.String(), size)
+	return s.GenerateImageKey("organization", orgGUID, imageGUID, size)
+}
+
+// GenerateProductImageKey generates a consistent key for product images
+func (s *S3Client) GenerateProductImageKey(productGUID uuid.UUID, imageGUID uuid.UUID, size string) string {
+	return s.GenerateImageKey("product", productGUID, imageGUID, size)
 }
 
 // GetURL returns the URL for an object
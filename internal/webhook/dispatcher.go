@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// jobKindDeliver is the queue job kind that attempts a delivery
+const jobKindDeliver = "webhook.deliver"
+
+// deliverPayload is the queue job payload of jobKindDeliver
+type deliverPayload struct {
+	DeliveryID uuid.UUID `json:"deliveryId"`
+}
+
+// Config holds the delivery and retry limits of a Dispatcher
+type Config struct {
+	Timeout     time.Duration // Time limit for a single delivery attempt
+	MaxAttempts int           // Attempts before a delivery is dead-lettered
+	BaseBackoff time.Duration // Delay after the first failed attempt, doubled after each further one
+	MaxBackoff  time.Duration // Upper bound for the delay between attempts
+}
+
+// DefaultConfig returns the default delivery and retry limits. With ten
+// attempts a failing delivery is retried for about an hour and a half.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:     10 * time.Second,
+		MaxAttempts: 10,
+		BaseBackoff: 10 * time.Second,
+		MaxBackoff:  time.Hour,
+	}
+}
+
+// maxErrorBody bounds how much of a failed response is kept as the delivery's last error
+const maxErrorBody = 512
+
+// Dispatcher queues deliveries on the job queue and sends them to their
+// webhooks when their jobs run
+type Dispatcher struct {
+	store       Store
+	queue       queue.Queue
+	imageConfig *domain.ImageConfig
+	config      Config
+	client      *http.Client
+	logger      *zap.SugaredLogger
+	now         func() time.Time
+}
+
+// NewDispatcher creates a dispatcher for the webhooks in the image configuration
+func NewDispatcher(store Store, jobQueue queue.Queue, imageConfig *domain.ImageConfig, config Config, logger *zap.SugaredLogger) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		queue:       jobQueue,
+		imageConfig: imageConfig,
+		config:      config,
+		client:      &http.Client{Timeout: config.Timeout},
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetHTTPClient sets the client deliveries are sent with
+func (d *Dispatcher) SetHTTPClient(client *http.Client) {
+	d.client = client
+}
+
+// RegisterJobHandlers registers the handler that attempts queued deliveries
+func (d *Dispatcher) RegisterJobHandlers(worker *queue.Worker) {
+	worker.Handle(jobKindDeliver, d.handleDelivery)
+}
+
+// Get returns a delivery by ID
+func (d *Dispatcher) Get(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	return d.store.Get(ctx, id)
+}
+
+// List returns up to limit deliveries, newest first, optionally only those
+// with the given status
+func (d *Dispatcher) List(ctx context.Context, status string, limit int) ([]*Delivery, error) {
+	return d.store.List(ctx, status, limit)
+}
+
+// Redeliver makes a delivery pending again with a fresh attempt budget and
+// queues a job to attempt it right away
+func (d *Dispatcher) Redeliver(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	delivery, err := d.store.Redeliver(ctx, id, d.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	// The job of the earlier attempts may not be purged yet, so this one
+	// needs an ID of its own
+	if err := d.schedule(ctx, delivery, uuid.New()); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// schedule queues the job that attempts a pending delivery when it is due
+func (d *Dispatcher) schedule(ctx context.Context, delivery *Delivery, jobID uuid.UUID) error {
+	job, err := queue.NewJob(jobKindDeliver, deliverPayload{DeliveryID: delivery.ID})
+	if err != nil {
+		return err
+	}
+	job.ID = jobID
+	job.RunAt = delivery.NextAttemptAt
+	job.MaxAttempts = d.config.MaxAttempts
+
+	if err := d.queue.Enqueue(ctx, job); err != nil {
+		return fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// handleDelivery attempts the delivery named by a queue job and records the
+// outcome. A failed attempt is retried at the delivery's own backoff; a
+// dead-lettered delivery buries its job.
+func (d *Dispatcher) handleDelivery(ctx context.Context, job *queue.Job) error {
+	var payload deliverPayload
+	if err := job.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+
+	delivery, err := d.store.Claim(ctx, payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return queue.Permanent(err)
+		}
+		return fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		// Already delivered or dead-lettered, e.g. by the job of a redelivery
+		return nil
+	}
+
+	sendErr := d.attempt(ctx, delivery, job.Attempts >= job.MaxAttempts)
+	if err := d.store.Update(ctx, delivery); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			d.logger.Warnw("Webhook delivery claimed again before its attempt was recorded",
+				"deliveryID", delivery.ID)
+			return nil
+		}
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+
+	switch delivery.Status {
+	case StatusDelivered:
+		return nil
+	case StatusDead:
+		return queue.Permanent(sendErr)
+	}
+	return queue.RetryAt(sendErr, delivery.NextAttemptAt)
+}
+
+// attempt sends a delivery once, records the outcome on it and returns why
+// it failed. The delivery is dead-lettered once it has used up its attempts,
+// or on the job's last attempt, as no further one will be made.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery, lastAttempt bool) error {
+	now := d.now().UTC()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+
+	webhook := d.subscription(delivery)
+	if webhook == nil {
+		// Retrying won't help until the subscription is configured again
+		delivery.Status = StatusDead
+		delivery.LastStatusCode = 0
+		delivery.LastError = "webhook is no longer configured"
+		return errors.New(delivery.LastError)
+	}
+
+	statusCode, err := d.send(ctx, delivery, webhook.Secret, now)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.Status = StatusDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return nil
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.config.MaxAttempts || lastAttempt {
+		delivery.Status = StatusDead
+		d.logger.Warnw("Webhook delivery dead-lettered",
+			"error", err,
+			"deliveryID", delivery.ID,
+			"webhook", delivery.Webhook,
+			"typeName", delivery.TypeName,
+			"attempts", delivery.Attempts)
+		return err
+	}
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	return err
+}
+
+// subscription returns the configured webhook a delivery is for, or nil if
+// it was removed from the configuration since the delivery was queued
+func (d *Dispatcher) subscription(delivery *Delivery) *domain.Webhook {
+	imageType, found := domain.GetImageTypeByName(d.imageConfig, delivery.TypeName)
+	if !found {
+		return nil
+	}
+	webhook, found := imageType.Webhook(delivery.Webhook)
+	if !found {
+		return nil
+	}
+	return webhook
+}
+
+// send posts a signed delivery and returns the response status. Any status
+// other than 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery, secret string, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "image-service-webhooks")
+	req.Header.Set(HeaderID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.config.MaxBackoff)
+}
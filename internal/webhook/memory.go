@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore implements Store in memory, for development and tests
+type MemoryStore struct {
+	mutex      sync.Mutex
+	deliveries map[uuid.UUID]*Delivery
+}
+
+// NewMemoryStore creates a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		deliveries: make(map[uuid.UUID]*Delivery),
+	}
+}
+
+// Enqueue stores new pending deliveries, dropping repeated events
+func (m *MemoryStore) Enqueue(ctx context.Context, deliveries []*Delivery) ([]*Delivery, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored := make([]*Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		existing := m.queued(delivery)
+		if existing == nil {
+			existing = copyDelivery(delivery)
+			m.deliveries[delivery.ID] = existing
+		}
+		stored = append(stored, copyDelivery(existing))
+	}
+	return stored, nil
+}
+
+// queued returns the delivery of the same event already stored for the
+// delivery's webhook, or nil if there is none
+func (m *MemoryStore) queued(delivery *Delivery) *Delivery {
+	for _, existing := range m.deliveries {
+		if existing.EventID == delivery.EventID && existing.Webhook == delivery.Webhook {
+			return existing
+		}
+	}
+	return nil
+}
+
+// Claim gives a pending delivery a new lease
+func (m *MemoryStore) Claim(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delivery, exists := m.deliveries[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	if delivery.Status != StatusPending {
+		return nil, nil
+	}
+	delivery.Lease = uuid.New()
+	return copyDelivery(delivery), nil
+}
+
+// Update stores the outcome of a claimed delivery's attempt
+func (m *MemoryStore) Update(ctx context.Context, delivery *Delivery) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.deliveries[delivery.ID]
+	if !exists {
+		return ErrNotFound
+	}
+	if stored.Status != StatusPending || stored.Lease != delivery.Lease {
+		return ErrLeaseLost
+	}
+	m.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+// Get returns a delivery by ID
+func (m *MemoryStore) Get(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delivery, exists := m.deliveries[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyDelivery(delivery), nil
+}
+
+// List returns deliveries newest first, optionally filtered by status
+func (m *MemoryStore) List(ctx context.Context, status string, limit int) ([]*Delivery, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var deliveries []*Delivery
+	for _, delivery := range m.deliveries {
+		if status == "" || delivery.Status == status {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// Redeliver makes a delivery pending again, due at now
+func (m *MemoryStore) Redeliver(ctx context.Context, id uuid.UUID, now time.Time) (*Delivery, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delivery, exists := m.deliveries[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.Lease = uuid.Nil
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	return copyDelivery(delivery), nil
+}
+
+// copyDelivery returns a deep copy so callers can't modify stored deliveries
+func copyDelivery(delivery *Delivery) *Delivery {
+	deliveryCopy := *delivery
+	deliveryCopy.Payload = append([]byte(nil), delivery.Payload...)
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		deliveryCopy.DeliveredAt = &deliveredAt
+	}
+	return &deliveryCopy
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// deliveryColumns lists the webhook_deliveries columns in scanDelivery order
+const deliveryColumns = `id, event_id, event_type, type_name, webhook, url, payload, status, attempts,
+	next_attempt_at, last_error, last_status_code, created_at, updated_at, delivered_at, lease`
+
+// PostgresStore implements Store using the webhook_deliveries table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new PostgresStore
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// Enqueue stores new pending deliveries in a single transaction, dropping
+// repeated events in favour of the stored ones
+func (p *PostgresStore) Enqueue(ctx context.Context, deliveries []*Delivery) ([]*Delivery, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stored := make([]*Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (`+deliveryColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			ON CONFLICT (event_id, webhook) DO NOTHING`,
+			d.ID, d.EventID, d.EventType, d.TypeName, d.Webhook, d.URL, d.Payload, d.Status, d.Attempts,
+			d.NextAttemptAt, d.LastError, d.LastStatusCode, d.CreatedAt, d.UpdatedAt, d.DeliveredAt, d.Lease)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+
+		row := tx.QueryRowContext(ctx, `
+			SELECT `+deliveryColumns+`
+			FROM webhook_deliveries
+			WHERE event_id = $1 AND webhook = $2`,
+			d.EventID, d.Webhook)
+		delivery, err := scanDelivery(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+		}
+		stored = append(stored, delivery)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook deliveries: %w", err)
+	}
+	return stored, nil
+}
+
+// Claim gives a pending delivery a new lease with a conditional update, so
+// finished deliveries are never claimed again
+func (p *PostgresStore) Claim(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	row := p.db.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries
+		SET lease = $2
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+deliveryColumns,
+		id, uuid.New())
+	delivery, err := scanDelivery(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Tell a finished delivery from a missing one
+		if _, err := p.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// Update stores the outcome of a claimed delivery's attempt with a
+// conditional update on its lease, so an attempt whose claim expired can't
+// overwrite the outcome of a later one
+func (p *PostgresStore) Update(ctx context.Context, d *Delivery) error {
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5,
+			last_status_code = $6, updated_at = $7, delivered_at = $8
+		WHERE id = $1 AND lease = $9 AND status = 'pending'`,
+		d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastError, d.LastStatusCode, d.UpdatedAt, d.DeliveredAt, d.Lease)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if rowsAffected == 0 {
+		if _, err := p.Get(ctx, d.ID); err != nil {
+			return err
+		}
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Get returns a delivery by ID
+func (p *PostgresStore) Get(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	row := p.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+	delivery, err := scanDelivery(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// List returns deliveries newest first, optionally filtered by status
+func (p *PostgresStore) List(ctx context.Context, status string, limit int) ([]*Delivery, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return scanDeliveries(rows)
+}
+
+// Redeliver makes a delivery pending again, due at now
+func (p *PostgresStore) Redeliver(ctx context.Context, id uuid.UUID, now time.Time) (*Delivery, error) {
+	row := p.db.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $2, updated_at = $2,
+			lease = '00000000-0000-0000-0000-000000000000'
+		WHERE id = $1
+		RETURNING `+deliveryColumns,
+		id, now)
+	delivery, err := scanDelivery(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanDelivery reads a row selected with deliveryColumns
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	var d Delivery
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.EventID, &d.EventType, &d.TypeName, &d.Webhook, &d.URL, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.LastStatusCode, &d.CreatedAt, &d.UpdatedAt, &deliveredAt, &d.Lease)
+	if err != nil {
+		return nil, err
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
+
+// scanDeliveries reads and closes rows selected with deliveryColumns
+func scanDeliveries(rows *sql.Rows) ([]*Delivery, error) {
+	defer func() { _ = rows.Close() }()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Publisher queues image events for the webhooks subscribed to them. It
+// implements outbox.Publisher.
+type Publisher struct {
+	dispatcher *Dispatcher
+}
+
+// NewPublisher creates a publisher for the webhooks the dispatcher sends to
+func NewPublisher(dispatcher *Dispatcher) *Publisher {
+	return &Publisher{
+		dispatcher: dispatcher,
+	}
+}
+
+// Publish stores one delivery of the event per subscribed webhook of the
+// image's type and queues the jobs that attempt them. Publishing an event
+// again queues the jobs of its pending deliveries that are missing, as the
+// job of a delivery shares its ID.
+func (p *Publisher) Publish(ctx context.Context, event domain.ImageEvent) error {
+	imageType, found := domain.GetImageTypeByName(p.dispatcher.imageConfig, event.Image.TypeName)
+	if !found {
+		return nil
+	}
+
+	var deliveries []*Delivery
+	var payload []byte
+	for _, webhook := range imageType.Webhooks {
+		if !webhook.Wants(event.Type) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+		}
+
+		now := time.Now().UTC()
+		deliveries = append(deliveries, &Delivery{
+			ID:            uuid.New(),
+			EventID:       event.ID,
+			EventType:     event.Type,
+			TypeName:      imageType.Name,
+			Webhook:       webhook.Name,
+			URL:           webhook.URL,
+			Payload:       payload,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	stored, err := p.dispatcher.store.Enqueue(ctx, deliveries)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range stored {
+		if delivery.Status != StatusPending {
+			continue
+		}
+		if err := p.dispatcher.schedule(ctx, delivery, delivery.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package webhook delivers image lifecycle events to the HTTP endpoints
+// subscribed to them in the image configuration. Events are stored as one
+// delivery per subscription, so admins can inspect and redeliver them, and
+// each delivery is attempted by a job on the job queue. The Dispatcher
+// handling those jobs signs each payload with the subscription's secret and
+// retries failed deliveries with exponential backoff until they are
+// delivered or dead-lettered.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderID        = "X-Webhook-Id"        // Delivery ID, stable across retries
+	HeaderEvent     = "X-Webhook-Event"     // Event type, e.g. image.uploaded
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix time of the attempt, covered by the signature
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"   // Waiting for its next attempt
+	StatusDelivered = "delivered" // Acknowledged with a 2xx response
+	StatusDead      = "dead"      // Gave up; can be redelivered manually
+)
+
+var (
+	// ErrNotFound is returned for unknown delivery IDs
+	ErrNotFound = errors.New("webhook delivery not found")
+
+	// ErrLeaseLost is returned when recording an attempt whose claim has
+	// been superseded, as the delivery may have been claimed again by another
+	// job
+	ErrLeaseLost = errors.New("webhook delivery lease lost")
+)
+
+// Delivery is an event queued for one webhook subscription
+type Delivery struct {
+	ID             uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	TypeName       string // Image type whose subscription receives the event
+	Webhook        string // Subscription name within the image type
+	URL            string
+	Payload        []byte // JSON-encoded domain.ImageEvent, sent and signed as is
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	LastStatusCode int // Zero if the last attempt got no response
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeliveredAt    *time.Time
+	Lease          uuid.UUID // Identifies the current claim; zero if never claimed
+}
+
+// Store persists deliveries
+type Store interface {
+	// Enqueue stores new pending deliveries and returns the stored delivery
+	// for each. A delivery of an event already stored for the same webhook is
+	// dropped in favour of the stored one, as events may be published more
+	// than once.
+	Enqueue(ctx context.Context, deliveries []*Delivery) ([]*Delivery, error)
+
+	// Claim gives a pending delivery a new lease and returns it, so that an
+	// attempt made under an earlier claim can no longer be recorded. It
+	// returns nil if the delivery is no longer pending.
+	Claim(ctx context.Context, id uuid.UUID) (*Delivery, error)
+
+	// Update stores the outcome of a claimed delivery's attempt, provided the
+	// claim is still the current one; otherwise ErrLeaseLost is returned and
+	// nothing is written
+	Update(ctx context.Context, delivery *Delivery) error
+
+	// Get returns a delivery by ID
+	Get(ctx context.Context, id uuid.UUID) (*Delivery, error)
+
+	// List returns up to limit deliveries, newest first, optionally only
+	// those with the given status
+	List(ctx context.Context, status string, limit int) ([]*Delivery, error)
+
+	// Redeliver makes a delivery pending again with a fresh attempt budget,
+	// due at now
+	Redeliver(ctx context.Context, id uuid.UUID, now time.Time) (*Delivery, error)
+}
+
+// Sign returns the signature header value for a payload sent at timestamp.
+// Receivers recompute it with their copy of the secret and compare it in
+// constant time, rejecting stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,356 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/antonrybalko/image-service-go/internal/domain"
+	"github.com/antonrybalko/image-service-go/internal/queue"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSecret = "webhook-secret"
+
+// receiver is an httptest endpoint that verifies signatures and records the
+// events it accepts
+type receiver struct {
+	server *httptest.Server
+	status atomic.Int32 // Status answered to valid requests
+
+	mutex  sync.Mutex
+	events []domain.ImageEvent
+}
+
+func newReceiver(t *testing.T) *receiver {
+	rcv := &receiver{}
+	rcv.status.Store(http.StatusNoContent)
+	rcv.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil || !hmac.Equal([]byte(r.Header.Get(HeaderSignature)), []byte(Sign(testSecret, timestamp, body))) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+
+		status := int(rcv.status.Load())
+		if status < 300 {
+			var event domain.ImageEvent
+			require.NoError(t, json.Unmarshal(body, &event))
+			assert.Equal(t, event.Type, r.Header.Get(HeaderEvent))
+			rcv.mutex.Lock()
+			rcv.events = append(rcv.events, event)
+			rcv.mutex.Unlock()
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rcv.server.Close)
+	return rcv
+}
+
+// received returns the events accepted so far
+func (r *receiver) received() []domain.ImageEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]domain.ImageEvent(nil), r.events...)
+}
+
+// newTestImageConfig subscribes webhooks to the user image type
+func newTestImageConfig(webhooks ...domain.Webhook) *domain.ImageConfig {
+	return &domain.ImageConfig{
+		Types: []domain.ImageType{
+			{
+				Name: "user",
+				Sizes: domain.SizeSet{
+					"small":  {Width: 50, Height: 50},
+					"medium": {Width: 100, Height: 100},
+					"large":  {Width: 800, Height: 800},
+				},
+				Webhooks: webhooks,
+			},
+		},
+	}
+}
+
+// testClock is a settable clock for the dispatcher
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// newTestDispatcher creates a dispatcher on a test clock set to the current
+// time, and a queue worker that runs its delivery jobs
+func newTestDispatcher(store Store, imageConfig *domain.ImageConfig, config Config) (*Dispatcher, *testClock, *queue.Worker) {
+	clock := &testClock{now: time.Now().UTC()}
+	jobQueue := queue.NewMemoryQueue()
+	dispatcher := NewDispatcher(store, jobQueue, imageConfig, config, zap.NewNop().Sugar())
+	dispatcher.now = clock.Now
+	worker := queue.NewWorker(jobQueue, queue.DefaultConfig(), zap.NewNop().Sugar())
+	dispatcher.RegisterJobHandlers(worker)
+	return dispatcher, clock, worker
+}
+
+// drain runs the due delivery jobs and returns how many attempts were made
+func drain(t *testing.T, worker *queue.Worker) int {
+	processed, err := worker.Drain(context.Background())
+	require.NoError(t, err)
+	return processed
+}
+
+// publishUpload publishes an upload event for a new user image
+func publishUpload(t *testing.T, publisher *Publisher, eventType string) domain.ImageEvent {
+	event := domain.NewImageEvent(eventType, domain.NewImage(uuid.New(), "user"))
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	return event
+}
+
+func TestPublishAndDeliver(t *testing.T) {
+	everything := newReceiver(t)
+	deletions := newReceiver(t)
+	imageConfig := newTestImageConfig(
+		domain.Webhook{Name: "search", URL: everything.server.URL, Secret: testSecret},
+		domain.Webhook{Name: "profiles", URL: deletions.server.URL, Secret: testSecret, Events: []string{domain.EventImageDeleted}},
+	)
+
+	store := NewMemoryStore()
+	dispatcher, _, worker := newTestDispatcher(store, imageConfig, DefaultConfig())
+	publisher := NewPublisher(dispatcher)
+	ctx := context.Background()
+
+	uploaded := publishUpload(t, publisher, domain.EventImageUploaded)
+	deleted := publishUpload(t, publisher, domain.EventImageDeleted)
+
+	// Types without subscriptions queue nothing
+	require.NoError(t, publisher.Publish(ctx, domain.NewImageEvent(domain.EventImageUploaded, domain.NewImage(uuid.New(), "product"))))
+
+	// Republished events are only queued once per webhook
+	require.NoError(t, publisher.Publish(ctx, uploaded))
+
+	assert.Equal(t, 3, drain(t, worker))
+
+	received := everything.received()
+	require.Len(t, received, 2)
+	assert.ElementsMatch(t, []uuid.UUID{uploaded.ID, deleted.ID}, []uuid.UUID{received[0].ID, received[1].ID})
+	assert.Equal(t, "user", received[0].Image.TypeName)
+
+	received = deletions.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, deleted.ID, received[0].ID)
+	assert.Equal(t, deleted.Image.GUID, received[0].Image.GUID)
+
+	delivered, err := store.List(ctx, StatusDelivered, 10)
+	require.NoError(t, err)
+	require.Len(t, delivered, 3)
+	for _, delivery := range delivered {
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+		assert.NotNil(t, delivery.DeliveredAt)
+	}
+
+	// Delivered events are not sent again, even when republished
+	require.NoError(t, publisher.Publish(ctx, uploaded))
+	assert.Zero(t, drain(t, worker))
+	assert.Len(t, everything.received(), 2)
+}
+
+// TestPublishQueuesMissingJobs tests that republishing an event queues the
+// jobs of deliveries stored by a publish that failed before queueing them
+func TestPublishQueuesMissingJobs(t *testing.T) {
+	rcv := newReceiver(t)
+	imageConfig := newTestImageConfig(domain.Webhook{Name: "search", URL: rcv.server.URL, Secret: testSecret})
+
+	store := NewMemoryStore()
+	dispatcher, clock, worker := newTestDispatcher(store, imageConfig, DefaultConfig())
+	ctx := context.Background()
+
+	event := domain.NewImageEvent(domain.EventImageUploaded, domain.NewImage(uuid.New(), "user"))
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	_, err = store.Enqueue(ctx, []*Delivery{{
+		ID:            uuid.New(),
+		EventID:       event.ID,
+		EventType:     event.Type,
+		TypeName:      "user",
+		Webhook:       "search",
+		URL:           rcv.server.URL,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: clock.now,
+		CreatedAt:     clock.now,
+	}})
+	require.NoError(t, err)
+	assert.Zero(t, drain(t, worker))
+
+	require.NoError(t, NewPublisher(dispatcher).Publish(ctx, event))
+	assert.Equal(t, 1, drain(t, worker))
+	assert.Len(t, rcv.received(), 1)
+}
+
+func TestDeliverRetriesAndDeadLetters(t *testing.T) {
+	rcv := newReceiver(t)
+	rcv.status.Store(http.StatusServiceUnavailable)
+	imageConfig := newTestImageConfig(domain.Webhook{Name: "search", URL: rcv.server.URL, Secret: testSecret})
+
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	// A failed attempt is retried when the delivery's backoff says
+	dispatcher, clock, worker := newTestDispatcher(store, imageConfig, DefaultConfig())
+	event := publishUpload(t, NewPublisher(dispatcher), domain.EventImageUploaded)
+	assert.Equal(t, 1, drain(t, worker))
+
+	pending, err := store.List(ctx, StatusPending, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	delivery := pending[0]
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+	assert.Contains(t, delivery.LastError, "unexpected status 503")
+	assert.Equal(t, clock.now.Add(10*time.Second), delivery.NextAttemptAt)
+
+	job, err := dispatcher.queue.Get(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusPending, job.Status)
+	assert.Equal(t, delivery.NextAttemptAt, job.RunAt)
+
+	// Nothing is due until the delay has passed
+	assert.Zero(t, drain(t, worker))
+
+	// Without a delay between attempts, the last one dead-letters the
+	// delivery and buries its job
+	config := DefaultConfig()
+	config.MaxAttempts = 3
+	config.BaseBackoff = time.Nanosecond
+	config.MaxBackoff = time.Nanosecond
+	dispatcher, clock, worker = newTestDispatcher(store, imageConfig, config)
+	event = publishUpload(t, NewPublisher(dispatcher), domain.EventImageUploaded)
+	assert.Equal(t, 3, drain(t, worker))
+
+	dead, err := store.List(ctx, StatusDead, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	delivery = dead[0]
+	assert.Equal(t, event.ID, delivery.EventID)
+	assert.Equal(t, 3, delivery.Attempts)
+	job, err = dispatcher.queue.Get(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusDead, job.Status)
+	assert.Empty(t, rcv.received())
+
+	// Redelivery starts over once the receiver has recovered
+	rcv.status.Store(http.StatusOK)
+	delivery, err = dispatcher.Redeliver(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.Equal(t, clock.now, delivery.NextAttemptAt)
+
+	assert.Equal(t, 1, drain(t, worker))
+	delivery, err = store.Get(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, delivery.Status)
+	assert.Empty(t, delivery.LastError)
+	assert.Len(t, rcv.received(), 1)
+
+	_, err = dispatcher.Redeliver(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeliverRemovedWebhook(t *testing.T) {
+	rcv := newReceiver(t)
+	imageConfig := newTestImageConfig(domain.Webhook{Name: "search", URL: rcv.server.URL, Secret: testSecret})
+
+	store := NewMemoryStore()
+	dispatcher, _, worker := newTestDispatcher(store, imageConfig, DefaultConfig())
+	publishUpload(t, NewPublisher(dispatcher), domain.EventImageUploaded)
+
+	// The subscription is gone by the time the delivery is attempted
+	imageConfig.Types[0].Webhooks = nil
+	assert.Equal(t, 1, drain(t, worker))
+
+	dead, err := store.List(context.Background(), StatusDead, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Contains(t, dead[0].LastError, "no longer configured")
+	assert.Empty(t, rcv.received())
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher, _, _ := newTestDispatcher(NewMemoryStore(), newTestImageConfig(), DefaultConfig())
+
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 20*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 40*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, time.Hour, dispatcher.backoff(10))
+	assert.Equal(t, time.Hour, dispatcher.backoff(100))
+}
+
+func TestMemoryStore_Claim(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	delivery := &Delivery{
+		ID:            uuid.New(),
+		EventID:       uuid.New(),
+		Webhook:       "search",
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	stored, err := store.Enqueue(ctx, []*Delivery{delivery})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, delivery.ID, stored[0].ID)
+
+	// A repeated event gets the delivery stored first
+	repeated := *delivery
+	repeated.ID = uuid.New()
+	stored, err = store.Enqueue(ctx, []*Delivery{&repeated})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, delivery.ID, stored[0].ID)
+
+	_, err = store.Claim(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// A later claim supersedes an earlier one, and only the current claim
+	// can record the attempt
+	expired, err := store.Claim(ctx, delivery.ID)
+	require.NoError(t, err)
+	require.NotNil(t, expired)
+	current, err := store.Claim(ctx, delivery.ID)
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.NotEqual(t, expired.Lease, current.Lease)
+
+	expired.Status = StatusDead
+	assert.ErrorIs(t, store.Update(ctx, expired), ErrLeaseLost)
+	current.Status = StatusDelivered
+	require.NoError(t, store.Update(ctx, current))
+	got, err := store.Get(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, got.Status)
+
+	// Finished deliveries are neither claimed nor updated by a late attempt
+	claimed, err := store.Claim(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+	assert.ErrorIs(t, store.Update(ctx, current), ErrLeaseLost)
+}
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Ordering and primary flag for collection image types (e.g. product galleries)
+ALTER TABLE images ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- At most one primary image per owner and type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_images_owner_type_primary
+    ON images (owner_guid, type_name) WHERE is_primary;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS idx_images_owner_type_primary;
+
+ALTER TABLE images DROP COLUMN IF EXISTS is_primary;
+ALTER TABLE images DROP COLUMN IF EXISTS position;
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Optional alternative text supplied with multipart uploads
+ALTER TABLE images ADD COLUMN IF NOT EXISTS alt_text TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE images DROP COLUMN IF EXISTS alt_text;
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Keyset pagination for the admin listing: ORDER BY updated_at DESC, guid DESC
+CREATE INDEX IF NOT EXISTS idx_images_updated ON images (updated_at DESC, guid DESC);
+CREATE INDEX IF NOT EXISTS idx_images_type_updated ON images (type_name, updated_at DESC, guid DESC);
+
+-- Superseded by idx_images_updated
+DROP INDEX IF EXISTS idx_images_updated_at;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+CREATE INDEX IF NOT EXISTS idx_images_updated_at ON images (updated_at DESC);
+
+DROP INDEX IF EXISTS idx_images_type_updated;
+DROP INDEX IF EXISTS idx_images_updated;
@@ -0,0 +1,35 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Image lifecycle events, written in the same transaction as the image change
+-- and published by the outbox relay
+CREATE TABLE IF NOT EXISTS outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    image_guid UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMPTZ
+);
+
+-- The relay polls for unpublished events in the order they were recorded
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox (seq) WHERE published_at IS NULL;
+
+-- Published events are purged once they are past retention
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at ON outbox (published_at) WHERE published_at IS NOT NULL;
+
+-- The relay publishes at least once, so an event may be queued for a webhook
+-- again; the repeat is dropped
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event_webhook ON webhook_deliveries (event_id, webhook);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_event_webhook;
+DROP INDEX IF EXISTS idx_outbox_published_at;
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,30 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Uploads processed in the background, reported by GET /v1/jobs/{jobGuid}
+CREATE TABLE IF NOT EXISTS image_jobs (
+    guid UUID PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    type_name TEXT NOT NULL,
+    owner_guid UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    options JSONB NOT NULL DEFAULT '{}',
+    image_guid UUID,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Workers poll for pending jobs, oldest first
+CREATE INDEX IF NOT EXISTS idx_image_jobs_pending ON image_jobs (created_at) WHERE status = 'pending';
+
+-- Stale and finished jobs are found by status and age
+CREATE INDEX IF NOT EXISTS idx_image_jobs_status_updated_at ON image_jobs (status, updated_at);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS idx_image_jobs_status_updated_at;
+DROP INDEX IF EXISTS idx_image_jobs_pending;
+
+DROP TABLE IF EXISTS image_jobs;
@@ -0,0 +1,39 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Durable queue for background work, claimed by the workers of every replica
+-- with SELECT ... FOR UPDATE SKIP LOCKED
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMPTZ NOT NULL,
+    lease UUID NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Workers claim due jobs by priority, then in the order they became due
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (priority DESC, run_at) WHERE status = 'pending';
+
+-- Done and dead jobs are purged once they are past retention
+CREATE INDEX IF NOT EXISTS idx_jobs_finished ON jobs (updated_at) WHERE status <> 'pending';
+
+-- Upload jobs are dispatched through the queue, so pending ones are no longer
+-- looked up in image_jobs
+DROP INDEX IF EXISTS idx_image_jobs_pending;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+CREATE INDEX IF NOT EXISTS idx_image_jobs_pending ON image_jobs (created_at) WHERE status = 'pending';
+
+DROP INDEX IF EXISTS idx_jobs_finished;
+DROP INDEX IF EXISTS idx_jobs_due;
+
+DROP TABLE IF EXISTS jobs;
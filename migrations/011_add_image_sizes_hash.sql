@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Fingerprint of the size definitions an image's variants were generated with;
+-- images whose hash differs from their type's current one are reprocessed.
+-- Existing images have none, so the first reprocessing run regenerates them all.
+ALTER TABLE images ADD COLUMN IF NOT EXISTS sizes_hash TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE images DROP COLUMN IF EXISTS sizes_hash;
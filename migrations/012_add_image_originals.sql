@@ -0,0 +1,23 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- The uploaded original, kept so variants can be regenerated from it; an empty
+-- key means none is kept, as for images uploaded before originals were stored
+ALTER TABLE images ADD COLUMN IF NOT EXISTS original_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN IF NOT EXISTS original_checksum TEXT NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN IF NOT EXISTS original_size BIGINT NOT NULL DEFAULT 0;
+
+-- The crop applied to the original before resizing, NULL for none
+ALTER TABLE images ADD COLUMN IF NOT EXISTS original_crop JSONB;
+
+-- Finds the originals past their type's retention
+CREATE INDEX IF NOT EXISTS idx_images_type_originals ON images (type_name, created_at) WHERE original_key <> '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS idx_images_type_originals;
+ALTER TABLE images DROP COLUMN IF EXISTS original_crop;
+ALTER TABLE images DROP COLUMN IF EXISTS original_size;
+ALTER TABLE images DROP COLUMN IF EXISTS original_checksum;
+ALTER TABLE images DROP COLUMN IF EXISTS original_key;
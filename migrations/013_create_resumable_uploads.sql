@@ -0,0 +1,30 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- State of tus uploads; the chunks are kept in S3 under resumable/. Appends
+-- update a row only at the version they read, so concurrent PATCH requests
+-- can't both advance the offset.
+CREATE TABLE IF NOT EXISTS resumable_uploads (
+    guid UUID PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    type_name TEXT NOT NULL,
+    owner_guid UUID NOT NULL,
+    length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    chunks JSONB NOT NULL DEFAULT '[]',
+    alt_text TEXT NOT NULL DEFAULT '',
+    image_guid UUID,
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- The cleanup job finds expired uploads
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_expires_at ON resumable_uploads (expires_at);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS idx_resumable_uploads_expires_at;
+
+DROP TABLE IF EXISTS resumable_uploads;
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Flags the images of single-image types, as inserted by ReplaceImage. The
+-- unique index lets only one of two concurrent first uploads for an owner
+-- commit. Existing rows stay unflagged, which only matters once an owner has
+-- an image, when replacements are guarded by the version column instead.
+ALTER TABLE images ADD COLUMN IF NOT EXISTS single BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_images_owner_type_single
+    ON images (owner_guid, type_name) WHERE single;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS idx_images_owner_type_single;
+ALTER TABLE images DROP COLUMN IF EXISTS single;
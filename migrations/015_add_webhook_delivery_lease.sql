@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Identifies the current claim of a delivery. A dispatcher records an attempt
+-- only while its claim is current, so one whose claim expired mid-batch can't
+-- overwrite the outcome of the dispatcher that claimed the delivery again.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS lease UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS lease;
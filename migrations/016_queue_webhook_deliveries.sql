@@ -0,0 +1,20 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Deliveries are attempted by jobs on the job queue rather than polled for,
+-- so pending ones get the job that attempts them when they are due
+INSERT INTO jobs (id, kind, payload, priority, status, attempts, max_attempts, run_at, lease, last_error, created_at, updated_at)
+SELECT id, 'webhook.deliver', jsonb_build_object('deliveryId', id), 0, 'pending', 0, 10, next_attempt_at,
+    '00000000-0000-0000-0000-000000000000', '', NOW(), NOW()
+FROM webhook_deliveries
+WHERE status = 'pending'
+ON CONFLICT (id) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+DELETE FROM jobs WHERE kind = 'webhook.deliver';
@@ -0,0 +1,11 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Identifies the reservation of a key, so a request whose reservation lapsed
+-- can't extend, complete or release a key another request reserved since
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS token TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS token;